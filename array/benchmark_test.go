@@ -2,6 +2,7 @@ package array
 
 import (
 	"fmt"
+	"slices"
 	"testing"
 )
 
@@ -190,6 +191,32 @@ func BenchmarkIterate(b *testing.B) {
 			}
 		})
 
+		b.Run(fmt.Sprintf("VectorGet/size_%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < vector.Len(); j++ {
+					_, _ = vector.Get(j)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("VectorBackward/size_%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, val := range vector.Backward() {
+					_ = val
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("VectorChunks/size_%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for chunk := range vector.Chunks() {
+					for _, val := range chunk {
+						_ = val
+					}
+				}
+			}
+		})
+
 		b.Run(fmt.Sprintf("NaiveArray/size_%d", size), func(b *testing.B) {
 			for i := 0; i < b.N; i++ {
 				for _, val := range naive.All() {
@@ -314,3 +341,168 @@ func BenchmarkMemoryAllocation(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkFromSlice(b *testing.B) {
+	sizes := []int{1000, 10000, 100000}
+
+	for _, size := range sizes {
+		values := make([]int, size)
+		for i := range values {
+			values[i] = i
+		}
+
+		b.Run(fmt.Sprintf("AppendLoop/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				v := NewVector[int]()
+				for _, value := range values {
+					v = v.Append(value)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("FromSlice/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				FromSlice(values)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Collect/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Collect(slices.Values(values))
+			}
+		})
+	}
+}
+
+func BenchmarkToSlice(b *testing.B) {
+	sizes := []int{1000, 10000, 100000}
+
+	for _, size := range sizes {
+		v := NewVector[int]()
+		for i := 0; i < size; i++ {
+			v = v.Append(i)
+		}
+
+		b.Run(fmt.Sprintf("ValuesLoop/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				out := make([]int, 0, size)
+				for value := range v.Values() {
+					out = append(out, value)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("ToSlice/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				v.ToSlice()
+			}
+		})
+	}
+}
+
+// mix - функция с ненулевой стоимостью на элемент, чтобы замер не сводился к обходу дерева
+func mix(x int) int {
+	h := uint64(x)
+	for i := 0; i < 16; i++ {
+		h ^= h >> 33
+		h *= 0xff51afd7ed558ccd
+	}
+	return int(h)
+}
+
+func BenchmarkParallel(b *testing.B) {
+	const size = 1_000_000
+	values := make([]int, size)
+	for i := range values {
+		values[i] = i
+	}
+	v := FromSlice(values)
+	keep := func(x int) bool { return mix(x)%2 == 0 }
+	sum := func(acc, x int) int { return acc + mix(x) }
+	add := func(a, b int) int { return a + b }
+
+	b.Run("Map/Sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Map(v, mix)
+		}
+	})
+	b.Run("Filter/Sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Filter(v, keep)
+		}
+	})
+	b.Run("Reduce/Sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Reduce(v, 0, sum)
+		}
+	})
+
+	for _, workers := range []int{1, -1} {
+		name := "workers_1"
+		if workers < 0 {
+			name = "workers_GOMAXPROCS"
+		}
+		b.Run("Map/Parallel/"+name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ParallelMap(v, workers, mix)
+			}
+		})
+		b.Run("Filter/Parallel/"+name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ParallelFilter(v, workers, keep)
+			}
+		})
+		b.Run("Reduce/Parallel/"+name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ParallelReduce(v, workers, 0, sum, add)
+			}
+		})
+	}
+}
+
+func BenchmarkCursor(b *testing.B) {
+	const size = 100_000
+	v, _ := rangeVector(size)
+
+	for _, edits := range []int{32, 1000, 10000} {
+		b.Run(fmt.Sprintf("SetLoop/edits_%d", edits), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				w := v
+				for j := 0; j < edits; j++ {
+					w = w.Set(size/2+j, -j)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Cursor/edits_%d", edits), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c := v.Cursor()
+				c.Move(size / 2)
+				for j := 0; j < edits; j++ {
+					c.Set(-j)
+					c.Move(c.Index() + 1)
+				}
+				c.Commit()
+			}
+		})
+	}
+
+	b.Run("InsertLoop/Cursor", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			c := v.Cursor()
+			c.Move(size / 2)
+			for j := 0; j < 1000; j++ {
+				c.Insert(j)
+			}
+			c.Commit()
+		}
+	})
+}
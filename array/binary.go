@@ -0,0 +1,238 @@
+package array
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrCorruptStream = errors.New("array: corrupt binary stream")
+
+const (
+	recordInternal uint8 = iota + 1
+	recordLeaf
+	recordVersion
+)
+
+// vectorRecord - единица бинарного потока. Узлы пишутся один раз и получают ID,
+// версии ссылаются на корень по ID. Поля экспортированы для encoding/gob.
+type vectorRecord[T any] struct {
+	Kind     uint8
+	ID       uint64
+	Children []uint64 // ID детей внутреннего узла, 0 - пустой слот
+	Values   []T      // значения листа или tail версии
+	Root     uint64
+	Len      int
+	Shift    uint
+}
+
+// VectorEncoder пишет несколько версий вектора в один поток.
+// Узлы, общие для нескольких версий, записываются только один раз.
+type VectorEncoder[T any] struct {
+	enc    *gob.Encoder
+	ids    map[*vectorNode[T]]uint64
+	nextID uint64
+}
+
+func NewVectorEncoder[T any](w io.Writer) *VectorEncoder[T] {
+	return &VectorEncoder[T]{
+		enc:    gob.NewEncoder(w),
+		ids:    make(map[*vectorNode[T]]uint64),
+		nextID: 1,
+	}
+}
+
+func (e *VectorEncoder[T]) Encode(v *Vector[T]) error {
+	var root uint64
+	if v.root != nil {
+		var err error
+		if root, err = e.encodeNode(v.root, v.shift); err != nil {
+			return err
+		}
+	}
+
+	return e.enc.Encode(vectorRecord[T]{
+		Kind:   recordVersion,
+		Root:   root,
+		Values: v.tail,
+		Len:    v.len,
+		Shift:  v.shift,
+	})
+}
+
+// encodeNode пишет поддерево в post-order, чтобы дети всегда шли раньше родителя
+func (e *VectorEncoder[T]) encodeNode(node *vectorNode[T], level uint) (uint64, error) {
+	if id, ok := e.ids[node]; ok {
+		return id, nil
+	}
+
+	record := vectorRecord[T]{Kind: recordLeaf}
+	if level > 0 {
+		record.Kind = recordInternal
+		last := -1
+		var children [nodeWidth]uint64
+		for i, child := range node.resolve().children {
+			if child == nil {
+				continue
+			}
+			id, err := e.encodeNode(child, level-shiftStep)
+			if err != nil {
+				return 0, err
+			}
+			children[i] = id
+			last = i
+		}
+		record.Children = children[:last+1]
+	} else {
+		record.Values = node.resolve().values[:]
+	}
+
+	record.ID = e.nextID
+	if err := e.enc.Encode(record); err != nil {
+		return 0, err
+	}
+	e.ids[node] = e.nextID
+	e.nextID++
+	return record.ID, nil
+}
+
+// VectorDecoder читает версии, записанные VectorEncoder, восстанавливая общие узлы
+type VectorDecoder[T any] struct {
+	dec   *gob.Decoder
+	nodes map[uint64]decodedNode[T]
+}
+
+// decodedNode - прочитанный узел с формой его поддерева: level - уровень узла (0 у листа),
+// size - число элементов в поддереве. Форма проверяется при чтении, чтобы версия из потока
+// не могла сослаться на поддерево, по которому Get ушёл бы в пустой слот.
+type decodedNode[T any] struct {
+	node  *vectorNode[T]
+	level uint
+	size  int
+}
+
+func NewVectorDecoder[T any](r io.Reader) *VectorDecoder[T] {
+	return &VectorDecoder[T]{
+		dec:   gob.NewDecoder(r),
+		nodes: make(map[uint64]decodedNode[T]),
+	}
+}
+
+// Decode возвращает следующую версию из потока или io.EOF, если версий больше нет
+func (d *VectorDecoder[T]) Decode() (*Vector[T], error) {
+	for {
+		var record vectorRecord[T]
+		if err := d.dec.Decode(&record); err != nil {
+			return nil, err
+		}
+
+		switch record.Kind {
+		case recordLeaf:
+			if len(record.Values) != nodeWidth {
+				return nil, fmt.Errorf("%w: leaf %d has %d values", ErrCorruptStream, record.ID, len(record.Values))
+			}
+			node := &vectorNode[T]{}
+			copy(node.values[:], record.Values)
+			d.nodes[record.ID] = decodedNode[T]{node: node, size: nodeWidth}
+
+		case recordInternal:
+			decoded, err := d.internal(record)
+			if err != nil {
+				return nil, err
+			}
+			d.nodes[record.ID] = decoded
+
+		case recordVersion:
+			return d.version(record)
+
+		default:
+			return nil, fmt.Errorf("%w: unknown record kind %d", ErrCorruptStream, record.Kind)
+		}
+	}
+}
+
+// internal собирает внутренний узел. Дети должны занимать слоты подряд с первого, лежать
+// на одном уровне и быть заполнены целиком, кроме последнего, - как в дереве, построенном Append.
+func (d *VectorDecoder[T]) internal(record vectorRecord[T]) (decodedNode[T], error) {
+	if len(record.Children) == 0 || len(record.Children) > nodeWidth {
+		return decodedNode[T]{}, fmt.Errorf("%w: node %d has %d children", ErrCorruptStream, record.ID, len(record.Children))
+	}
+
+	node := &vectorNode[T]{}
+	var level uint
+	size := 0
+	for i, id := range record.Children {
+		child, ok := d.nodes[id]
+		if !ok {
+			return decodedNode[T]{}, fmt.Errorf("%w: unknown node %d", ErrCorruptStream, id)
+		}
+		if i == 0 {
+			level = child.level
+		}
+		if child.level != level || (i > 0 && size != i*capacity(level)) {
+			return decodedNode[T]{}, fmt.Errorf("%w: node %d has misplaced child %d", ErrCorruptStream, record.ID, id)
+		}
+		node.children[i] = child.node
+		size += child.size
+	}
+	return decodedNode[T]{node: node, level: level + shiftStep, size: size}, nil
+}
+
+// capacity - число элементов в полном поддереве уровня level
+func capacity(level uint) int {
+	if level+shiftStep >= 63 {
+		return 0
+	}
+	return 1 << (level + shiftStep)
+}
+
+func (d *VectorDecoder[T]) version(record vectorRecord[T]) (*Vector[T], error) {
+	v := NewVector[T]()
+	if record.Len == 0 {
+		return v, nil
+	}
+
+	if record.Shift < shiftStep || record.Shift%shiftStep != 0 || len(record.Values) > nodeWidth {
+		return nil, fmt.Errorf("%w: invalid version header", ErrCorruptStream)
+	}
+
+	v.len = record.Len
+	v.shift = record.Shift
+	v.tail = make([]T, len(record.Values), nodeWidth)
+	copy(v.tail, record.Values)
+
+	if record.Root != 0 {
+		root, ok := d.nodes[record.Root]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown root %d", ErrCorruptStream, record.Root)
+		}
+		if root.level != v.shift || root.size != v.tailOffset() {
+			return nil, fmt.Errorf("%w: root %d does not match shift %d and length %d", ErrCorruptStream, record.Root, v.shift, v.len)
+		}
+		v.root = root.node
+	}
+
+	if v.len-v.tailOffset() != len(v.tail) || (v.root == nil) != (v.tailOffset() == 0) {
+		return nil, fmt.Errorf("%w: length %d does not match tree", ErrCorruptStream, v.len)
+	}
+	return v, nil
+}
+
+func (v *Vector[T]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewVectorEncoder[T](&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (v *Vector[T]) UnmarshalBinary(data []byte) error {
+	decoded, err := NewVectorDecoder[T](bytes.NewReader(data)).Decode()
+	if err != nil {
+		return err
+	}
+	*v = *decoded
+	return nil
+}
@@ -0,0 +1,183 @@
+package array
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildVector(size int) *Vector[int] {
+	v := NewVector[int]()
+	for i := 0; i < size; i++ {
+		v = v.Append(i)
+	}
+	return v
+}
+
+func requireVectorEqual[T any](t *testing.T, expected, actual *Vector[T]) {
+	t.Helper()
+	require.Equal(t, expected.Len(), actual.Len())
+	for i := 0; i < expected.Len(); i++ {
+		e, _ := expected.Get(i)
+		a, _ := actual.Get(i)
+		require.Equal(t, e, a, "элемент %d", i)
+	}
+}
+
+func TestVector_Binary(t *testing.T) {
+	t.Run("одна версия через BinaryMarshaler", func(t *testing.T) {
+		for _, size := range []int{0, 1, 32, 33, 1057, 5000} {
+			v := buildVector(size)
+
+			data, err := v.MarshalBinary()
+			require.NoError(t, err)
+
+			var decoded Vector[int]
+			require.NoError(t, decoded.UnmarshalBinary(data))
+			requireVectorEqual(t, v, &decoded)
+		}
+	})
+
+	t.Run("несколько версий с общими узлами", func(t *testing.T) {
+		base := buildVector(5000)
+		versions := []*Vector[int]{base, base.Set(10, -1), base.Set(4000, -2), base.Append(5000)}
+
+		var buf bytes.Buffer
+		enc := NewVectorEncoder[int](&buf)
+		for _, v := range versions {
+			require.NoError(t, enc.Encode(v))
+		}
+
+		dec := NewVectorDecoder[int](&buf)
+		var decoded []*Vector[int]
+		for {
+			v, err := dec.Decode()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			decoded = append(decoded, v)
+		}
+
+		require.Len(t, decoded, len(versions))
+		for i := range versions {
+			requireVectorEqual(t, versions[i], decoded[i])
+		}
+
+		// лист с индексом 2000 не менялся ни в одной версии и должен быть общим
+		assert.Same(t, decoded[0].getLeaf(2000), decoded[1].getLeaf(2000))
+		assert.Same(t, decoded[0].getLeaf(2000), decoded[2].getLeaf(2000))
+		assert.Same(t, decoded[0].getLeaf(2000), decoded[3].getLeaf(2000))
+		assert.NotSame(t, decoded[0].getLeaf(10), decoded[1].getLeaf(10))
+	})
+
+	t.Run("общие узлы не дублируются в потоке", func(t *testing.T) {
+		base := buildVector(10000)
+
+		var shared bytes.Buffer
+		enc := NewVectorEncoder[int](&shared)
+		var independent int
+		for i := 0; i < 10; i++ {
+			v := base.Set(i*1000, -i)
+			require.NoError(t, enc.Encode(v))
+
+			data, err := v.MarshalBinary()
+			require.NoError(t, err)
+			independent += len(data)
+		}
+
+		assert.Less(t, shared.Len()*3, independent, "поток с шарингом должен быть в разы меньше")
+	})
+
+	t.Run("декодированный вектор поддерживает изменения", func(t *testing.T) {
+		data, err := buildVector(2000).MarshalBinary()
+		require.NoError(t, err)
+
+		var decoded Vector[int]
+		require.NoError(t, decoded.UnmarshalBinary(data))
+
+		v := &decoded
+		v = v.Append(2000).Set(5, -5)
+		v, last, _ := v.Pop()
+		assert.Equal(t, 2000, last)
+		val, _ := v.Get(5)
+		assert.Equal(t, -5, val)
+	})
+
+	t.Run("ссылка на неизвестный узел", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(vectorRecord[int]{
+			Kind:     recordInternal,
+			ID:       1,
+			Children: []uint64{42},
+		}))
+
+		_, err := NewVectorDecoder[int](&buf).Decode()
+		assert.ErrorIs(t, err, ErrCorruptStream)
+	})
+
+	t.Run("форма дерева не соответствует версии", func(t *testing.T) {
+		leaf := make([]int, nodeWidth)
+		cases := map[string][]vectorRecord[int]{
+			"лист вместо корня": {
+				{Kind: recordLeaf, ID: 1, Values: leaf},
+				{Kind: recordVersion, Root: 1, Values: []int{1}, Len: nodeWidth + 1, Shift: 2 * shiftStep},
+			},
+			"корень ниже shift": {
+				{Kind: recordLeaf, ID: 1, Values: leaf},
+				{Kind: recordInternal, ID: 2, Children: []uint64{1}},
+				{Kind: recordVersion, Root: 2, Values: []int{1}, Len: nodeWidth + 1, Shift: 2 * shiftStep},
+			},
+			"пропуск среди детей": {
+				{Kind: recordLeaf, ID: 1, Values: leaf},
+				{Kind: recordInternal, ID: 2, Children: []uint64{0, 1}},
+			},
+			"дети разных уровней": {
+				{Kind: recordLeaf, ID: 1, Values: leaf},
+				{Kind: recordInternal, ID: 2, Children: []uint64{1}},
+				{Kind: recordInternal, ID: 3, Children: []uint64{2, 1}},
+			},
+			"неполный ребёнок перед последним": {
+				{Kind: recordLeaf, ID: 1, Values: leaf},
+				{Kind: recordInternal, ID: 2, Children: []uint64{1}},
+				{Kind: recordInternal, ID: 3, Children: []uint64{2, 2}},
+			},
+			"дерево короче длины": {
+				{Kind: recordLeaf, ID: 1, Values: leaf},
+				{Kind: recordInternal, ID: 2, Children: []uint64{1}},
+				{Kind: recordVersion, Root: 2, Values: []int{1}, Len: 2*nodeWidth + 1, Shift: shiftStep},
+			},
+		}
+
+		for name, records := range cases {
+			t.Run(name, func(t *testing.T) {
+				var buf bytes.Buffer
+				enc := gob.NewEncoder(&buf)
+				for _, record := range records {
+					require.NoError(t, enc.Encode(record))
+				}
+
+				_, err := NewVectorDecoder[int](&buf).Decode()
+				assert.ErrorIs(t, err, ErrCorruptStream)
+			})
+		}
+	})
+
+	t.Run("длина не соответствует дереву", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(vectorRecord[int]{
+			Kind:   recordVersion,
+			Values: []int{1, 2},
+			Len:    100,
+			Shift:  shiftStep,
+		}))
+
+		_, err := NewVectorDecoder[int](&buf).Decode()
+		assert.ErrorIs(t, err, ErrCorruptStream)
+	})
+}
@@ -0,0 +1,143 @@
+package array
+
+import "iter"
+
+// FromSlice строит вектор из копии values снизу вверх: листья заполняются напрямую из среза,
+// затем группируются по 32 в родительские узлы. Path copying не выполняется.
+func FromSlice[T any](values []T) *Vector[T] {
+	n := len(values)
+	if n == 0 {
+		return NewVector[T]()
+	}
+
+	tailOffset := ((n - 1) >> shiftStep) << shiftStep
+	leaves := make([]*vectorNode[T], 0, tailOffset/nodeWidth)
+	for i := 0; i < tailOffset; i += nodeWidth {
+		leaf := &vectorNode[T]{}
+		copy(leaf.values[:], values[i:i+nodeWidth])
+		leaves = append(leaves, leaf)
+	}
+
+	tail := make([]T, n-tailOffset, nodeWidth)
+	copy(tail, values[tailOffset:])
+	return fromLeaves(leaves, tail)
+}
+
+// Collect строит вектор из последовательности, заполняя листья по мере чтения,
+// без промежуточного среза всех элементов
+func Collect[T any](seq iter.Seq[T]) *Vector[T] {
+	var b builder[T]
+	for value := range seq {
+		b.add(value)
+	}
+	return b.build()
+}
+
+// builder накапливает элементы сразу в листьях и собирает из них дерево снизу вверх.
+// Полный лист другого вектора можно добавить без копирования, если builder стоит на границе листа.
+type builder[T any] struct {
+	leaves []*vectorNode[T]
+	leaf   *vectorNode[T] // заполняемый лист, nil на границе листа
+	filled int
+}
+
+func (b *builder[T]) add(value T) {
+	if b.leaf == nil {
+		b.leaf = &vectorNode[T]{}
+	}
+	b.leaf.values[b.filled] = value
+	b.filled++
+	if b.filled == nodeWidth {
+		b.leaves = append(b.leaves, b.leaf)
+		b.leaf, b.filled = nil, 0
+	}
+}
+
+// addChunk добавляет элементы листа. Полный лист на границе переиспользуется целиком,
+// поэтому узлы не должны изменяться после добавления.
+func (b *builder[T]) addChunk(values []T, leaf *vectorNode[T]) {
+	if leaf != nil && b.leaf == nil && len(values) == nodeWidth {
+		b.leaves = append(b.leaves, leaf)
+		return
+	}
+	for _, value := range values {
+		b.add(value)
+	}
+}
+
+// addRange добавляет элементы v с индексами из [from, to). Полные листья v переиспользуются,
+// если builder стоит на границе листа там же, где они.
+func (b *builder[T]) addRange(v *Vector[T], from, to int) {
+	if from >= to {
+		return
+	}
+	start := from &^ indexMask
+	for values, leaf := range v.leaves(from) {
+		if start >= to {
+			return
+		}
+		lo, hi := max(from-start, 0), min(to-start, len(values))
+		if lo < hi {
+			if lo > 0 || hi < len(values) {
+				leaf = nil
+			}
+			b.addChunk(values[lo:hi], leaf)
+		}
+		start += len(values)
+	}
+}
+
+func (b *builder[T]) len() int {
+	return len(b.leaves)*nodeWidth + b.filled
+}
+
+func (b *builder[T]) build() *Vector[T] {
+	var tail []T
+	switch {
+	case b.leaf != nil:
+		tail = make([]T, b.filled, nodeWidth)
+		copy(tail, b.leaf.values[:b.filled])
+	case len(b.leaves) > 0:
+		// последний полный лист становится хвостом
+		last := b.leaves[len(b.leaves)-1]
+		b.leaves = b.leaves[:len(b.leaves)-1]
+		tail = make([]T, nodeWidth)
+		copy(tail, last.values[:])
+	default:
+		return NewVector[T]()
+	}
+	return fromLeaves(b.leaves, tail)
+}
+
+// fromLeaves собирает дерево из полных листьев, группируя каждый уровень по 32 узла
+func fromLeaves[T any](leaves []*vectorNode[T], tail []T) *Vector[T] {
+	v := &Vector[T]{tail: tail, len: len(leaves)*nodeWidth + len(tail), shift: shiftStep}
+	if len(leaves) == 0 {
+		return v
+	}
+
+	level := leaves
+	for {
+		parents := make([]*vectorNode[T], 0, (len(level)+nodeWidth-1)/nodeWidth)
+		for i := 0; i < len(level); i += nodeWidth {
+			parent := &vectorNode[T]{}
+			copy(parent.children[:], level[i:min(i+nodeWidth, len(level))])
+			parents = append(parents, parent)
+		}
+		if len(parents) == 1 {
+			v.root = parents[0]
+			return v
+		}
+		level = parents
+		v.shift += shiftStep
+	}
+}
+
+// ToSlice копирует элементы в новый срез, читая каждый лист целиком
+func (v *Vector[T]) ToSlice() []T {
+	result := make([]T, 0, v.len)
+	for values := range v.leaves(0) {
+		result = append(result, values...)
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package array
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVector_FromSlice(t *testing.T) {
+	// размеры на границах хвоста, листа и уровней дерева
+	for _, size := range []int{0, 1, 31, 32, 33, 64, 65, 1024, 1056, 1057, 33000} {
+		t.Run(fmt.Sprintf("размер %d", size), func(t *testing.T) {
+			values := make([]int, size)
+			for i := range values {
+				values[i] = i
+			}
+
+			appended := NewVector[int]()
+			for _, v := range values {
+				appended = appended.Append(v)
+			}
+
+			for name, v := range map[string]*Vector[int]{
+				"FromSlice": FromSlice(values),
+				"Collect":   Collect(slices.Values(values)),
+			} {
+				require.Equal(t, size, v.Len(), name)
+				assert.Equal(t, appended.shift, v.shift, "%s: глубина дерева как после Append", name)
+				assert.Equal(t, values, v.ToSlice(), name)
+
+				// построенный вектор продолжает работать как обычный
+				grown := v.Append(-1)
+				last, _ := grown.Get(size)
+				assert.Equal(t, -1, last, name)
+				if size > 0 {
+					popped, value, ok := v.Pop()
+					require.True(t, ok)
+					assert.Equal(t, size-1, value, name)
+					assert.Equal(t, values[:size-1], popped.ToSlice(), name)
+				}
+			}
+		})
+	}
+
+	t.Run("срез копируется", func(t *testing.T) {
+		values := []int{1, 2, 3}
+		v := FromSlice(values)
+		values[0] = 100
+		first, _ := v.Get(0)
+		assert.Equal(t, 1, first)
+
+		out := v.ToSlice()
+		out[1] = 200
+		second, _ := v.Get(1)
+		assert.Equal(t, 2, second)
+	})
+
+	t.Run("Collect с ранней остановкой источника", func(t *testing.T) {
+		v := Collect(func(yield func(string) bool) {
+			for i := 0; i < 40; i++ {
+				if !yield(fmt.Sprint(i)) {
+					return
+				}
+			}
+		})
+		require.Equal(t, 40, v.Len())
+		value, _ := v.Get(39)
+		assert.Equal(t, "39", value)
+	})
+}
+
+func TestVector_ToSliceDiskBacked(t *testing.T) {
+	diskBacked = true
+	defer func() { diskBacked = false }()
+
+	v := newTestVector[int](t)
+	expected := make([]int, 0, 2000)
+	for i := 0; i < 2000; i++ {
+		v = v.Append(i)
+		expected = append(expected, i)
+	}
+	assert.Equal(t, expected, v.ToSlice())
+}
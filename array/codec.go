@@ -0,0 +1,136 @@
+package array
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+var errBadDelta = errors.New("array: bad vector delta")
+
+// vectorDelta - изменения между двумя версиями: новая длина и значения по изменённым индексам
+// (по возрастанию). Индексы за пределами старой длины добавляются через Append.
+type vectorDelta[T any] struct {
+	Len     int   `json:"n"`
+	Indexes []int `json:"i,omitempty"`
+	Values  []T   `json:"v,omitempty"`
+}
+
+// VectorCodec кодирует версии вектора для history.DurableHistory: версию целиком - как JSON-массив,
+// а следующую версию - как разницу с предыдущей. Разница ищется параллельным обходом деревьев,
+// совпадающие по указателю поддеревья пропускаются, поэтому её размер пропорционален изменённым листьям.
+type VectorCodec[T any] struct{}
+
+func NewVectorCodec[T any]() *VectorCodec[T] {
+	return &VectorCodec[T]{}
+}
+
+func (c *VectorCodec[T]) Marshal(v *Vector[T]) ([]byte, error) {
+	return v.MarshalJSON()
+}
+
+func (c *VectorCodec[T]) Unmarshal(data []byte) (*Vector[T], error) {
+	v := NewVector[T]()
+	if err := v.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (c *VectorCodec[T]) Diff(prev, next *Vector[T]) ([]byte, error) {
+	var indexes []int
+	emit := func(index int) {
+		indexes = append(indexes, index)
+	}
+
+	if next.root != nil {
+		// выравниваем высоту: при росте дерева старый корень становится самым левым потомком нового
+		a, aLevel := prev.root, prev.shift
+		b, bLevel := next.root, next.shift
+		for aLevel > bLevel {
+			if a == nil {
+				aLevel = bLevel
+				break
+			}
+			a, aLevel = a.resolve().children[0], aLevel-shiftStep
+		}
+		for bLevel > aLevel {
+			diffVectorNodes(nil, b, bLevel, 0, next.tailOffset(), emit, true)
+			b, bLevel = b.resolve().children[0], bLevel-shiftStep
+		}
+		diffVectorNodes(a, b, bLevel, 0, next.tailOffset(), emit, false)
+	}
+	for i := next.tailOffset(); i < next.len; i++ {
+		emit(i)
+	}
+
+	slices.Sort(indexes)
+	delta := vectorDelta[T]{Len: next.len, Indexes: indexes, Values: make([]T, len(indexes))}
+	for i, index := range indexes {
+		delta.Values[i], _ = next.Get(index)
+	}
+	return json.Marshal(delta)
+}
+
+// diffVectorNodes вызывает emit для индексов листьев b, которые отличаются от a.
+// Узел уровня level покрывает индексы [offset, offset + 32<<level); skipFirst пропускает
+// самого левого потомка, который уже сравнён после выравнивания высоты.
+func diffVectorNodes[T any](a, b *vectorNode[T], level uint, offset, limit int, emit func(int), skipFirst bool) {
+	if a == b || offset >= limit {
+		return
+	}
+	b = b.resolve()
+
+	if level == 0 {
+		for i := 0; i < nodeWidth && offset+i < limit; i++ {
+			emit(offset + i)
+		}
+		return
+	}
+
+	if a != nil {
+		a = a.resolve()
+	}
+	for i, child := range b.children {
+		if child == nil {
+			break
+		}
+		if i == 0 && skipFirst {
+			continue
+		}
+		var old *vectorNode[T]
+		if a != nil {
+			old = a.children[i]
+		}
+		diffVectorNodes(old, child, level-shiftStep, offset+i<<level, limit, emit, false)
+	}
+}
+
+func (c *VectorCodec[T]) Apply(prev *Vector[T], data []byte) (*Vector[T], error) {
+	var delta vectorDelta[T]
+	if err := json.Unmarshal(data, &delta); err != nil {
+		return nil, err
+	}
+	if len(delta.Indexes) != len(delta.Values) || delta.Len < 0 {
+		return nil, errBadDelta
+	}
+
+	v := prev
+	for v.len > delta.Len {
+		v, _, _ = v.Pop()
+	}
+	for i, index := range delta.Indexes {
+		switch {
+		case index >= 0 && index < v.len:
+			v = v.Set(index, delta.Values[i])
+		case index == v.len:
+			v = v.Append(delta.Values[i])
+		default:
+			return nil, errBadDelta
+		}
+	}
+	if v.len != delta.Len {
+		return nil, errBadDelta
+	}
+	return v, nil
+}
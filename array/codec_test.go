@@ -0,0 +1,69 @@
+package array
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectorCodec_DiffApply(t *testing.T) {
+	codec := NewVectorCodec[int]()
+	base := buildVector(1000)
+
+	cases := map[string]func(*Vector[int]) *Vector[int]{
+		"без изменений": func(v *Vector[int]) *Vector[int] { return v },
+		"Set в дереве и в tail": func(v *Vector[int]) *Vector[int] {
+			return v.Set(5, -5).Set(500, -500).Set(v.Len()-1, -1)
+		},
+		"рост дерева на уровень": func(v *Vector[int]) *Vector[int] {
+			for i := 0; i < 2000; i++ {
+				v = v.Append(i)
+			}
+			return v
+		},
+		"уменьшение до tail": func(v *Vector[int]) *Vector[int] {
+			for v.Len() > 20 {
+				v, _, _ = v.Pop()
+			}
+			return v
+		},
+		"уменьшение с изменением": func(v *Vector[int]) *Vector[int] {
+			for v.Len() > 300 {
+				v, _, _ = v.Pop()
+			}
+			return v.Set(0, 42)
+		},
+	}
+
+	for name, change := range cases {
+		t.Run(name, func(t *testing.T) {
+			next := change(base)
+
+			delta, err := codec.Diff(base, next)
+			require.NoError(t, err)
+			applied, err := codec.Apply(base, delta)
+			require.NoError(t, err)
+
+			requireVectorEqual(t, next, applied)
+		})
+	}
+
+	t.Run("разница пропорциональна изменениям", func(t *testing.T) {
+		big := buildVector(100_000)
+		full, err := codec.Marshal(big)
+		require.NoError(t, err)
+		delta, err := codec.Diff(big, big.Set(50_000, -1))
+		require.NoError(t, err)
+
+		assert.Less(t, len(delta)*100, len(full))
+	})
+
+	t.Run("с пустым вектором", func(t *testing.T) {
+		delta, err := codec.Diff(NewVector[int](), base)
+		require.NoError(t, err)
+		applied, err := codec.Apply(NewVector[int](), delta)
+		require.NoError(t, err)
+		requireVectorEqual(t, base, applied)
+	})
+}
@@ -0,0 +1,189 @@
+package array
+
+import (
+	"iter"
+
+	"github.com/ykhdr/persistent-data-structures/hashmap"
+)
+
+// Комбинаторы обходят вектор по листьям и собирают результат через builder снизу вверх,
+// без path copying на каждый элемент. Листья, которые переходят в результат без изменений,
+// разделяются с исходным вектором, если в результате они попадают на границу листа.
+// Построенный результат хранится в памяти, даже если исходный вектор хранится в файле. Filter, Partition,
+// Take, Drop и TakeWhile, которым нечего удалять, возвращают сам v, и он остаётся там, где был.
+
+// Pair - пара элементов, которую возвращает Zip
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+func Map[T, U any](v *Vector[T], fn func(T) U) *Vector[U] {
+	var b builder[U]
+	for values := range v.leaves(0) {
+		for _, value := range values {
+			b.add(fn(value))
+		}
+	}
+	return b.build()
+}
+
+// Filter оставляет элементы, для которых pred вернул true. Если подходят все элементы,
+// возвращается сам v.
+func Filter[T any](v *Vector[T], pred func(T) bool) *Vector[T] {
+	kept, _ := partition(v, pred, true)
+	return kept
+}
+
+// Partition делит вектор на элементы, для которых pred вернул true, и остальные, сохраняя порядок
+func Partition[T any](v *Vector[T], pred func(T) bool) (matched, rest *Vector[T]) {
+	return partition(v, pred, false)
+}
+
+// partition раскладывает элементы по двум builder. Лист, все элементы которого попали в одну часть,
+// добавляется туда целиком. Если onlyMatched, вторая часть не строится.
+func partition[T any](v *Vector[T], pred func(T) bool, onlyMatched bool) (*Vector[T], *Vector[T]) {
+	var yes, no builder[T]
+	var flags [nodeWidth]bool
+	for values, leaf := range v.leaves(0) {
+		count := 0
+		for i, value := range values {
+			flags[i] = pred(value)
+			if flags[i] {
+				count++
+			}
+		}
+
+		switch count {
+		case len(values):
+			yes.addChunk(values, leaf)
+		case 0:
+			if !onlyMatched {
+				no.addChunk(values, leaf)
+			}
+		default:
+			for i, value := range values {
+				if flags[i] {
+					yes.add(value)
+				} else if !onlyMatched {
+					no.add(value)
+				}
+			}
+		}
+	}
+
+	if yes.len() == v.len {
+		return v, NewVector[T]()
+	}
+	if onlyMatched {
+		return yes.build(), nil
+	}
+	if no.len() == v.len {
+		return NewVector[T](), v
+	}
+	return yes.build(), no.build()
+}
+
+// GroupBy раскладывает элементы по ключу в HashMap векторов, сохраняя порядок элементов внутри группы.
+// Каждая группа строится снизу вверх через FromSlice.
+func GroupBy[T any, K comparable](v *Vector[T], key func(T) K) *hashmap.HashMap[K, *Vector[T]] {
+	groups := make(map[K][]T)
+	for values := range v.leaves(0) {
+		for _, value := range values {
+			k := key(value)
+			groups[k] = append(groups[k], value)
+		}
+	}
+
+	result := make(map[K]*Vector[T], len(groups))
+	for k, values := range groups {
+		result[k] = FromSlice(values)
+	}
+	return hashmap.FromMap(result)
+}
+
+// Reduce сворачивает элементы слева направо, начиная с init
+func Reduce[T, A any](v *Vector[T], init A, fn func(A, T) A) A {
+	acc := init
+	for values := range v.leaves(0) {
+		for _, value := range values {
+			acc = fn(acc, value)
+		}
+	}
+	return acc
+}
+
+// FlatMap заменяет каждый элемент последовательностью, которую вернул fn, и склеивает их по порядку
+func FlatMap[T, U any](v *Vector[T], fn func(T) iter.Seq[U]) *Vector[U] {
+	var b builder[U]
+	for values := range v.leaves(0) {
+		for _, value := range values {
+			for mapped := range fn(value) {
+				b.add(mapped)
+			}
+		}
+	}
+	return b.build()
+}
+
+// Zip составляет пары из элементов a и b с одинаковыми индексами. Длина результата - меньшая из длин.
+func Zip[T, U any](a *Vector[T], b *Vector[U]) *Vector[Pair[T, U]] {
+	var result builder[Pair[T, U]]
+	next, stop := iter.Pull2(b.leaves(0))
+	defer stop()
+
+	var other []U
+	for values := range a.leaves(0) {
+		for _, value := range values {
+			if len(other) == 0 {
+				chunk, _, ok := next()
+				if !ok {
+					return result.build()
+				}
+				other = chunk
+			}
+			result.add(Pair[T, U]{First: value, Second: other[0]})
+			other = other[1:]
+		}
+	}
+	return result.build()
+}
+
+// Take возвращает первые n элементов. Отрицательное n считается нулём, n больше длины - длиной.
+func Take[T any](v *Vector[T], n int) *Vector[T] {
+	return v.slice(0, max(0, min(n, v.len)))
+}
+
+// Drop возвращает вектор без первых n элементов
+func Drop[T any](v *Vector[T], n int) *Vector[T] {
+	return v.slice(max(0, min(n, v.len)), v.len)
+}
+
+// TakeWhile возвращает самый длинный префикс, все элементы которого удовлетворяют pred
+func TakeWhile[T any](v *Vector[T], pred func(T) bool) *Vector[T] {
+	n := 0
+	for values := range v.leaves(0) {
+		for _, value := range values {
+			if !pred(value) {
+				return v.slice(0, n)
+			}
+			n++
+		}
+	}
+	return v
+}
+
+// slice возвращает элементы [from, to). Полные листья внутри диапазона переиспользуются,
+// если from кратен ширине листа: тогда они и в результате лежат на границе листа.
+func (v *Vector[T]) slice(from, to int) *Vector[T] {
+	if from == 0 && to == v.len {
+		return v
+	}
+	if from >= to {
+		return NewVector[T]()
+	}
+
+	var b builder[T]
+	b.addRange(v, from, to)
+	return b.build()
+}
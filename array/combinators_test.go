@@ -0,0 +1,156 @@
+package array
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rangeVector(n int) (*Vector[int], []int) {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = i
+	}
+	return FromSlice(values), values
+}
+
+func TestVector_Combinators(t *testing.T) {
+	even := func(x int) bool { return x%2 == 0 }
+
+	for _, size := range []int{0, 1, 31, 32, 33, 100, 1057, 5000} {
+		t.Run(fmt.Sprintf("размер %d", size), func(t *testing.T) {
+			v, values := rangeVector(size)
+
+			evens, odds := []int{}, []int{}
+			for _, x := range values {
+				if even(x) {
+					evens = append(evens, x)
+				} else {
+					odds = append(odds, x)
+				}
+			}
+
+			squares := Map(v, func(x int) string { return strconv.Itoa(x * x) })
+			require.Equal(t, size, squares.Len())
+			for i, s := range squares.All() {
+				require.Equal(t, strconv.Itoa(i*i), s)
+			}
+
+			assert.Equal(t, evens, Filter(v, even).ToSlice())
+			matched, rest := Partition(v, even)
+			assert.Equal(t, evens, matched.ToSlice())
+			assert.Equal(t, odds, rest.ToSlice())
+
+			assert.Equal(t, size*(size-1)/2, Reduce(v, 0, func(acc, x int) int { return acc + x }))
+
+			doubled := FlatMap(v, func(x int) iter.Seq[int] {
+				return slices.Values([]int{x, x})
+			})
+			require.Equal(t, 2*size, doubled.Len())
+			for i, x := range doubled.All() {
+				require.Equal(t, i/2, x)
+			}
+
+			for _, n := range []int{-1, 0, 1, 31, 32, 33, 64, size / 2, size, size + 10} {
+				k := max(0, min(n, size))
+				assert.Equal(t, values[:k], Take(v, n).ToSlice(), "Take(%d)", n)
+				assert.Equal(t, values[k:], Drop(v, n).ToSlice(), "Drop(%d)", n)
+				assert.Equal(t, values[:k], TakeWhile(v, func(x int) bool { return x < n }).ToSlice(), "TakeWhile(< %d)", n)
+			}
+		})
+	}
+
+	t.Run("Zip", func(t *testing.T) {
+		a, _ := rangeVector(100)
+		b := Map(Take(a, 70), func(x int) string { return strconv.Itoa(x) })
+
+		zipped := Zip(a, b)
+		require.Equal(t, 70, zipped.Len())
+		for i, p := range zipped.All() {
+			assert.Equal(t, Pair[int, string]{First: i, Second: strconv.Itoa(i)}, p)
+		}
+		assert.Equal(t, 70, Zip(b, a).Len())
+		assert.Equal(t, 0, Zip(a, NewVector[string]()).Len())
+	})
+}
+
+func TestVector_CombinatorsSharing(t *testing.T) {
+	v, values := rangeVector(2000)
+
+	t.Run("Filter без удалений возвращает тот же вектор", func(t *testing.T) {
+		assert.Same(t, v, Filter(v, func(int) bool { return true }))
+		matched, rest := Partition(v, func(int) bool { return false })
+		assert.Same(t, v, rest)
+		assert.Equal(t, 0, matched.Len())
+		assert.Same(t, v, Take(v, 5000))
+		assert.Same(t, v, Drop(v, 0))
+		assert.Same(t, v, TakeWhile(v, func(int) bool { return true }))
+	})
+
+	t.Run("Take и Drop на границе листа разделяют листья", func(t *testing.T) {
+		taken := Take(v, 1000)
+		for i := 0; i < taken.tailOffset(); i += nodeWidth {
+			assert.Same(t, v.getLeaf(i), taken.getLeaf(i), "лист %d", i/nodeWidth)
+		}
+
+		dropped := Drop(v, 64)
+		for i := 0; i < dropped.tailOffset(); i += nodeWidth {
+			assert.Same(t, v.getLeaf(i+64), dropped.getLeaf(i), "лист %d", i/nodeWidth)
+		}
+
+		// изменение результата не затрагивает исходный вектор
+		changed := dropped.Set(0, -1)
+		value, _ := v.Get(64)
+		assert.Equal(t, 64, value)
+		value, _ = changed.Get(0)
+		assert.Equal(t, -1, value)
+	})
+
+	t.Run("Filter переиспользует нетронутые листья", func(t *testing.T) {
+		// удаляется только первый лист целиком, остальные листья сдвигаются на его место
+		filtered := Filter(v, func(x int) bool { return x >= 32 })
+		assert.Equal(t, values[32:], filtered.ToSlice())
+		assert.Same(t, v.getLeaf(32), filtered.getLeaf(0))
+	})
+
+	t.Run("вектор в файле", func(t *testing.T) {
+		diskBacked = true
+		defer func() { diskBacked = false }()
+
+		stored := newTestVector[int](t)
+		for _, x := range values {
+			stored = stored.Append(x)
+		}
+		assert.Equal(t, values[100:1500], Take(Drop(stored, 100), 1400).ToSlice())
+		assert.Equal(t, Reduce(v, 0, func(a, x int) int { return a + x }), Reduce(stored, 0, func(a, x int) int { return a + x }))
+
+		evens := Filter(stored, func(x int) bool { return x%2 == 0 })
+		assert.Nil(t, evens.loader, "результат хранится в памяти")
+		assert.Equal(t, 1000, evens.Len())
+
+		all := Filter(stored, func(int) bool { return true })
+		assert.Same(t, stored, all, "без удалений возвращается сам вектор")
+		assert.NotNil(t, all.loader, "и он остаётся в файле")
+	})
+}
+
+func TestGroupBy(t *testing.T) {
+	v, _ := rangeVector(1000)
+
+	groups := GroupBy(v, func(x int) int { return x % 3 })
+	require.Equal(t, 3, groups.Len())
+	for k, group := range groups.All() {
+		expected := []int{}
+		for x := k; x < 1000; x += 3 {
+			expected = append(expected, x)
+		}
+		assert.Equal(t, expected, group.ToSlice(), "группа %d", k)
+	}
+
+	assert.Equal(t, 0, GroupBy(NewVector[int](), func(x int) int { return x }).Len())
+}
@@ -0,0 +1,23 @@
+package array
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ykhdr/persistent-data-structures/pdstest"
+)
+
+func TestIndexed_Contract(t *testing.T) {
+	id := func(i int) int { return i }
+
+	t.Run("Vector", func(t *testing.T) { pdstest.Indexed(t, NewVector[int], id) })
+	t.Run("NaiveArray", func(t *testing.T) { pdstest.Indexed(t, NewNaiveArray[int], id) })
+	t.Run("VectorFile", func(t *testing.T) {
+		f, err := CreateVectorFile[int](filepath.Join(t.TempDir(), "vector.pds"), 4)
+		require.NoError(t, err)
+		t.Cleanup(func() { f.Close() })
+		pdstest.Indexed(t, f.Empty, id)
+	})
+}
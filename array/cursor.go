@@ -0,0 +1,213 @@
+package array
+
+import "slices"
+
+// Cursor - курсор (zipper) для последовательной правки вектора. Он делит вектор на две части:
+// элементы до фокуса уже собраны в листья нового вектора, а элементы начиная с фокуса лежат
+// кусками листьев исходного вектора и ещё не прочитанным остатком исходного вектора.
+// Get, Set, Insert и Delete в фокусе и сдвиг фокуса на соседние позиции стоят O(1) амортизированно,
+// а не O(log n) с копированием пути, как Set вектора. Commit собирает новый вектор снизу вверх,
+// переиспользуя листья, которые не изменились и не сдвинулись относительно границы листа.
+//
+// В отличие от вектора курсор изменяемый и не безопасен для одновременного использования.
+// Исходный вектор и векторы, полученные из Commit, курсор не меняет.
+type Cursor[T any] struct {
+	left    builder[T]   // элементы до фокуса
+	right   []segment[T] // элементы начиная с фокуса, ближайший кусок - последний
+	src     *Vector[T]
+	next    int // элементы src с индекса next следуют за right
+	len     int
+	changed bool
+}
+
+// segment - кусок листа после фокуса
+type segment[T any] struct {
+	values []T
+	leaf   *vectorNode[T] // не nil, если values - целый лист leaf, который можно переиспользовать
+	owned  bool           // values принадлежат курсору и могут изменяться на месте
+}
+
+// Cursor возвращает курсор с фокусом на первом элементе
+func (v *Vector[T]) Cursor() *Cursor[T] {
+	return &Cursor[T]{src: v, len: v.len}
+}
+
+func (c *Cursor[T]) Len() int {
+	return c.len
+}
+
+// Index возвращает позицию фокуса. Позиция Len - за последним элементом.
+func (c *Cursor[T]) Index() int {
+	return c.left.len()
+}
+
+// Move переносит фокус на позицию index из [0, Len]. Для index вне диапазона возвращает false,
+// и фокус не меняется. Стоимость пропорциональна расстоянию, а целые нетронутые листья
+// пропускаются за O(1).
+func (c *Cursor[T]) Move(index int) bool {
+	if index < 0 || index > c.len {
+		return false
+	}
+	for d := index - c.Index(); d > 0; {
+		top := c.top()
+		if c.left.leaf == nil && top.leaf != nil && d >= nodeWidth {
+			c.left.addChunk(top.values, top.leaf)
+			c.right = c.right[:len(c.right)-1]
+			d -= nodeWidth
+			continue
+		}
+		k := min(d, len(top.values))
+		for _, value := range top.values[:k] {
+			c.left.add(value)
+		}
+		c.dropFront(k)
+		d -= k
+	}
+	for d := c.Index() - index; d > 0; {
+		d -= c.unwind(d)
+	}
+	return true
+}
+
+// unwind возвращает из left в right до d последних элементов, не больше одного листа, и сообщает их число
+func (c *Cursor[T]) unwind(d int) int {
+	b := &c.left
+	if b.leaf != nil {
+		// недостроенный лист принадлежит builder: целиком он передаётся курсору, а его часть копируется
+		k := min(d, b.filled)
+		if k == b.filled {
+			c.right = append(c.right, segment[T]{values: b.leaf.values[:k], owned: true})
+			b.leaf, b.filled = nil, 0
+			return k
+		}
+		b.filled -= k
+		values := slices.Clone(b.leaf.values[b.filled : b.filled+k])
+		clear(b.leaf.values[b.filled : b.filled+k])
+		c.right = append(c.right, segment[T]{values: values, owned: true})
+		return k
+	}
+
+	last := b.leaves[len(b.leaves)-1]
+	b.leaves = b.leaves[:len(b.leaves)-1]
+	if d >= nodeWidth {
+		c.right = append(c.right, segment[T]{values: last.values[:], leaf: last})
+		return nodeWidth
+	}
+	keep := nodeWidth - d
+	for _, value := range last.values[:keep] {
+		b.add(value)
+	}
+	c.right = append(c.right, segment[T]{values: last.values[keep:]})
+	return d
+}
+
+// top возвращает кусок с фокусом, при необходимости читая следующий лист src, или nil за последним элементом
+func (c *Cursor[T]) top() *segment[T] {
+	if len(c.right) == 0 {
+		if c.next >= c.src.len {
+			return nil
+		}
+		var seg segment[T]
+		if tailOffset := c.src.tailOffset(); c.next >= tailOffset {
+			seg.values = c.src.tail[c.next-tailOffset:]
+		} else {
+			leaf := c.src.getLeaf(c.next)
+			seg.values = leaf.values[c.next&indexMask:]
+			if c.next&indexMask == 0 {
+				seg.leaf = leaf
+			}
+		}
+		c.right = append(c.right, seg)
+		c.next += len(seg.values)
+	}
+	return &c.right[len(c.right)-1]
+}
+
+// dropFront убирает k первых элементов куска с фокусом
+func (c *Cursor[T]) dropFront(k int) {
+	top := &c.right[len(c.right)-1]
+	top.values, top.leaf = top.values[k:], nil
+	if len(top.values) == 0 {
+		c.right = c.right[:len(c.right)-1]
+	}
+}
+
+// Get возвращает элемент в фокусе. За последним элементом возвращает false.
+func (c *Cursor[T]) Get() (T, bool) {
+	top := c.top()
+	if top == nil {
+		var zero T
+		return zero, false
+	}
+	return top.values[0], true
+}
+
+// Set заменяет элемент в фокусе. Кусок листа копируется один раз, последующие Set
+// в том же куске меняют копию на месте. За последним элементом возвращает false.
+func (c *Cursor[T]) Set(value T) bool {
+	top := c.top()
+	if top == nil {
+		return false
+	}
+	if !top.owned {
+		top.values, top.leaf, top.owned = slices.Clone(top.values), nil, true
+	}
+	top.values[0] = value
+	c.changed = true
+	return true
+}
+
+// Insert вставляет values перед фокусом. Фокус остаётся на том же элементе, и его индекс
+// увеличивается на len(values).
+func (c *Cursor[T]) Insert(values ...T) {
+	for _, value := range values {
+		c.left.add(value)
+	}
+	c.len += len(values)
+	c.changed = c.changed || len(values) > 0
+}
+
+// Delete удаляет count элементов начиная с фокуса, и фокус переходит на следующий за ними.
+// Если после фокуса меньше count элементов или count < 0, ничего не удаляется и возвращается false.
+// Непрочитанные элементы исходного вектора пропускаются без чтения листов.
+func (c *Cursor[T]) Delete(count int) bool {
+	if count < 0 || count > c.len-c.Index() {
+		return false
+	}
+	for remaining := count; remaining > 0; {
+		if len(c.right) == 0 {
+			skip := min(remaining, c.src.len-c.next)
+			c.next += skip
+			remaining -= skip
+			continue
+		}
+		k := min(remaining, len(c.right[len(c.right)-1].values))
+		c.dropFront(k)
+		remaining -= k
+	}
+	c.len -= count
+	c.changed = c.changed || count > 0
+	return true
+}
+
+// Commit возвращает вектор с текущим содержимым курсора; курсор можно использовать и дальше.
+// Если правок не было, возвращается исходный вектор. Commit стоит O(n/32): листья до фокуса
+// и нетронутые листья после него переиспользуются, если лежат на границе листа.
+// Результат хранится в памяти, даже если исходный вектор хранится в файле.
+func (c *Cursor[T]) Commit() *Vector[T] {
+	if !c.changed {
+		return c.src
+	}
+
+	// копия left: недостроенный лист и срез листьев курсора не должны меняться при сборке
+	b := builder[T]{leaves: slices.Clip(c.left.leaves), filled: c.left.filled}
+	if c.left.leaf != nil {
+		b.leaf = &vectorNode[T]{values: c.left.leaf.values}
+	}
+	for i := len(c.right) - 1; i >= 0; i-- {
+		seg := c.right[i]
+		b.addChunk(seg.values, seg.leaf)
+	}
+	b.addRange(c.src, c.next, c.src.len)
+	return b.build()
+}
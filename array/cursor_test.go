@@ -0,0 +1,199 @@
+package array
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	v, values := rangeVector(100)
+	c := v.Cursor()
+
+	require.True(t, c.Move(40))
+	value, ok := c.Get()
+	require.True(t, ok)
+	assert.Equal(t, 40, value)
+
+	require.True(t, c.Set(-40))
+	c.Insert(1000, 1001)
+	assert.Equal(t, 42, c.Index(), "фокус остаётся на том же элементе")
+	value, _ = c.Get()
+	assert.Equal(t, -40, value)
+
+	require.True(t, c.Delete(3))
+	value, _ = c.Get()
+	assert.Equal(t, 43, value)
+	assert.Equal(t, 99, c.Len())
+
+	expected := slices.Concat(values[:40], []int{1000, 1001}, values[43:])
+	assert.Equal(t, expected, c.Commit().ToSlice())
+	assert.Equal(t, values, v.ToSlice(), "исходный вектор не меняется")
+
+	t.Run("границы", func(t *testing.T) {
+		c := v.Cursor()
+		assert.False(t, c.Move(-1))
+		assert.False(t, c.Move(101))
+		assert.Equal(t, 0, c.Index())
+
+		require.True(t, c.Move(100))
+		_, ok := c.Get()
+		assert.False(t, ok, "за последним элементом")
+		assert.False(t, c.Set(1))
+		assert.False(t, c.Delete(1))
+		assert.False(t, c.Delete(-1))
+		c.Insert(100, 101)
+		assert.Equal(t, append(slices.Clone(values), 100, 101), c.Commit().ToSlice())
+	})
+
+	t.Run("пустой вектор", func(t *testing.T) {
+		c := NewVector[int]().Cursor()
+		_, ok := c.Get()
+		assert.False(t, ok)
+		c.Insert(1, 2, 3)
+		require.True(t, c.Move(1))
+		require.True(t, c.Delete(1))
+		assert.Equal(t, []int{1, 3}, c.Commit().ToSlice())
+	})
+}
+
+func TestCursor_Random(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			r := rand.New(rand.NewSource(seed))
+			v, expected := rangeVector(r.Intn(3000))
+			c := v.Cursor()
+			index := 0
+
+			type commit struct {
+				v      *Vector[int]
+				values []int
+			}
+			var commits []commit
+
+			for step := 0; step < 2000; step++ {
+				switch op := r.Intn(10); {
+				case op < 3:
+					// в основном короткие сдвиги, иногда - далёкие
+					to := index + r.Intn(70) - 35
+					if r.Intn(10) == 0 {
+						to = r.Intn(len(expected) + 1)
+					}
+					if to < 0 || to > len(expected) {
+						require.False(t, c.Move(to))
+						continue
+					}
+					require.True(t, c.Move(to))
+					index = to
+				case op < 5:
+					ok := c.Set(step)
+					require.Equal(t, index < len(expected), ok)
+					if ok {
+						expected[index] = step
+					}
+				case op < 7:
+					values := make([]int, r.Intn(40))
+					for i := range values {
+						values[i] = -step*100 - i
+					}
+					c.Insert(values...)
+					expected = slices.Insert(expected, index, values...)
+					index += len(values)
+				case op < 9:
+					count := r.Intn(40)
+					ok := c.Delete(count)
+					require.Equal(t, index+count <= len(expected), ok)
+					if ok {
+						expected = slices.Delete(expected, index, index+count)
+					}
+				default:
+					committed := c.Commit()
+					require.Equal(t, len(expected), committed.Len())
+					commits = append(commits, commit{committed, slices.Clone(expected)})
+				}
+
+				require.Equal(t, index, c.Index(), "шаг %d", step)
+				require.Equal(t, len(expected), c.Len(), "шаг %d", step)
+				value, ok := c.Get()
+				if index < len(expected) {
+					require.True(t, ok)
+					require.Equal(t, expected[index], value, "шаг %d", step)
+				} else {
+					require.False(t, ok)
+				}
+			}
+
+			final := c.Commit()
+			require.Equal(t, expected, final.ToSlice())
+			// вектор из Commit - обычный вектор, дальнейшие операции над ним корректны
+			require.Equal(t, append(slices.Clone(expected), 1), final.Append(1).ToSlice())
+			for i, commit := range commits {
+				require.Equal(t, commit.values, commit.v.ToSlice(), "Commit %d изменился после последующих правок", i)
+			}
+		})
+	}
+}
+
+func TestCursor_Sharing(t *testing.T) {
+	v, _ := rangeVector(5000)
+
+	c := v.Cursor()
+	require.True(t, c.Move(2500))
+	assert.Same(t, v, c.Commit(), "без правок возвращается исходный вектор")
+
+	for i := 0; i < 10; i++ {
+		require.True(t, c.Set(-i))
+		require.True(t, c.Move(c.Index()+1))
+	}
+	edited := c.Commit()
+
+	// Set не сдвигает элементы, поэтому все листья, кроме изменённого, переиспользуются
+	changed := 2500 &^ indexMask
+	for i := 0; i < edited.tailOffset(); i += nodeWidth {
+		if i == changed {
+			assert.NotSame(t, v.getLeaf(i), edited.getLeaf(i))
+			continue
+		}
+		require.Same(t, v.getLeaf(i), edited.getLeaf(i), "лист %d", i)
+	}
+
+	// после вставки 32 элементов листья за ней снова лежат на границе
+	c = v.Cursor()
+	require.True(t, c.Move(64))
+	c.Insert(make([]int, nodeWidth)...)
+	shifted := c.Commit()
+	assert.Same(t, v.getLeaf(1024), shifted.getLeaf(1024+nodeWidth))
+}
+
+func TestCursor_DiskBacked(t *testing.T) {
+	f, err := CreateVectorFile[int](filepath.Join(t.TempDir(), "vector.pds"), 4)
+	require.NoError(t, err)
+	defer f.Close()
+
+	v := f.Empty()
+	expected := make([]int, 3000)
+	for i := range expected {
+		v = v.Append(i)
+		expected[i] = i
+	}
+	require.NoError(t, f.Checkpoint(v))
+	v, err = f.Load()
+	require.NoError(t, err)
+
+	c := v.Cursor()
+	require.True(t, c.Move(1000))
+	for i := 0; i < 50; i++ {
+		require.True(t, c.Set(-i))
+		require.True(t, c.Move(c.Index()+1))
+		expected[1000+i] = -i
+	}
+	require.True(t, c.Delete(500))
+	expected = slices.Delete(expected, 1050, 1550)
+
+	assert.Equal(t, expected, c.Commit().ToSlice())
+}
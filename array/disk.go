@@ -0,0 +1,214 @@
+package array
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ykhdr/persistent-data-structures/store"
+)
+
+// VectorFile хранит узлы векторов в файле store.PageFile и подгружает их по требованию,
+// поэтому вектор может быть больше оперативной памяти. В памяти держатся только заглушки
+// на пути от корня и не больше cacheSize загруженных узлов.
+//
+// Векторы, полученные из Empty или Load, записывают новые узлы каждой версии в конец файла:
+// старые версии остаются валидными, пока файл открыт.
+//
+// У операций вектора (Get, Set, Append, обход и другие) нет возвращаемой ошибки, поэтому ошибка
+// ввода-вывода или повреждённый узел при чтении и записи приводят к панике со значением
+// *store.NodeError. Её можно перевести в ошибку через defer store.Recover(&err).
+// Load и Checkpoint не паникуют и возвращают такие ошибки сами.
+type VectorFile[T any] struct {
+	pages *store.PageFile
+	cache *store.Cache[*vectorNode[T]]
+}
+
+func CreateVectorFile[T any](path string, cacheSize int) (*VectorFile[T], error) {
+	pages, err := store.CreatePageFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &VectorFile[T]{pages: pages, cache: store.NewCache[*vectorNode[T]](cacheSize)}, nil
+}
+
+func OpenVectorFile[T any](path string, cacheSize int) (*VectorFile[T], error) {
+	pages, err := store.OpenPageFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &VectorFile[T]{pages: pages, cache: store.NewCache[*vectorNode[T]](cacheSize)}, nil
+}
+
+// Empty возвращает пустой вектор, узлы которого будут храниться в файле
+func (f *VectorFile[T]) Empty() *Vector[T] {
+	v := NewVector[T]()
+	v.loader = f
+	return v
+}
+
+// Checkpoint записывает версию v и делает её той, которую вернёт Load после повторного открытия.
+// Вектор в памяти при этом целиком копируется в файл.
+func (f *VectorFile[T]) Checkpoint(v *Vector[T]) (err error) {
+	defer store.Recover(&err)
+
+	tail, err := store.EncodeValues(v.tail)
+	if err != nil {
+		return err
+	}
+
+	var root uint64
+	if v.root != nil {
+		root = f.spillNode(v.root, v.shift).lazy.ref
+	}
+
+	data := []byte{objectVersion}
+	data = binary.AppendUvarint(data, uint64(v.len))
+	data = binary.AppendUvarint(data, uint64(v.shift))
+	data = binary.AppendUvarint(data, root)
+	data = append(data, tail...)
+
+	offset, err := f.pages.Append(data)
+	if err != nil {
+		return err
+	}
+	return f.pages.SetCheckpoint(offset)
+}
+
+// Load возвращает версию, сохранённую последним Checkpoint. Читается только корень дерева:
+// повреждённый checkpoint обнаруживается здесь, а не при первом обращении к вектору.
+func (f *VectorFile[T]) Load() (_ *Vector[T], err error) {
+	defer store.Recover(&err)
+
+	offset, err := f.pages.Checkpoint()
+	if err != nil {
+		return nil, err
+	}
+	data, err := f.pages.Read(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || data[0] != objectVersion {
+		return nil, fmt.Errorf("%w: record at %d is not a vector version", store.ErrCorrupt, offset)
+	}
+
+	data = data[1:]
+	var fields [3]uint64
+	for i := range fields {
+		value, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("%w: record at %d truncated", store.ErrCorrupt, offset)
+		}
+		fields[i] = value
+		data = data[n:]
+	}
+	length, shift, root := fields[0], fields[1], fields[2]
+	if shift < shiftStep || shift%shiftStep != 0 {
+		return nil, fmt.Errorf("%w: record at %d bad shift", store.ErrCorrupt, offset)
+	}
+
+	tail, err := store.DecodeValues[T](data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: record at %d: %v", store.ErrCorrupt, offset, err)
+	}
+
+	v := &Vector[T]{
+		tail:   make([]T, len(tail), nodeWidth),
+		len:    int(length),
+		shift:  uint(shift),
+		loader: f,
+	}
+	copy(v.tail, tail)
+	if root != 0 {
+		v.root = f.stub(root)
+	}
+
+	if v.len-v.tailOffset() != len(v.tail) || (v.root == nil) != (v.tailOffset() == 0) {
+		return nil, fmt.Errorf("%w: record at %d length does not match tree", store.ErrCorrupt, offset)
+	}
+	if v.root != nil {
+		v.root.resolve()
+	}
+	return v, nil
+}
+
+func (f *VectorFile[T]) Close() error {
+	return f.pages.Close()
+}
+
+func (f *VectorFile[T]) stub(ref uint64) *vectorNode[T] {
+	return &vectorNode[T]{lazy: &lazyNode[T]{loader: f, ref: ref}}
+}
+
+func (f *VectorFile[T]) loadNode(ref uint64) *vectorNode[T] {
+	if node, ok := f.cache.Get(ref); ok {
+		return node
+	}
+
+	data, err := f.pages.Read(ref)
+	if err != nil {
+		panic(&store.NodeError{Err: fmt.Errorf("array: load node at %d: %w", ref, err)})
+	}
+
+	node := &vectorNode[T]{}
+	switch {
+	case len(data) > 0 && data[0] == objectLeaf:
+		values, err := store.DecodeValues[T](data[1:])
+		if err != nil || len(values) != nodeWidth {
+			panic(&store.NodeError{Err: fmt.Errorf("array: load node at %d: %w: bad leaf", ref, store.ErrCorrupt)})
+		}
+		copy(node.values[:], values)
+
+	case len(data) > 0 && data[0] == objectInternal:
+		data = data[1:]
+		for i := 0; len(data) > 0; i++ {
+			child, n := binary.Uvarint(data)
+			if n <= 0 || i >= nodeWidth {
+				panic(&store.NodeError{Err: fmt.Errorf("array: load node at %d: %w: bad children", ref, store.ErrCorrupt)})
+			}
+			node.children[i] = f.stub(child)
+			data = data[n:]
+		}
+
+	default:
+		panic(&store.NodeError{Err: fmt.Errorf("array: load node at %d: %w: unexpected record", ref, store.ErrCorrupt)})
+	}
+
+	f.cache.Put(ref, node)
+	return node
+}
+
+// spillNode записывает узел и всех его потомков, которых ещё нет в файле.
+// Узлы не изменяются: записывается копия, в которой дети заменены заглушками.
+func (f *VectorFile[T]) spillNode(node *vectorNode[T], level uint) *vectorNode[T] {
+	if node.lazy != nil && node.lazy.loader == f {
+		return node
+	}
+	node = node.resolve()
+
+	var data []byte
+	written := &vectorNode[T]{}
+	if level == 0 {
+		values, err := store.EncodeValues(node.values[:])
+		if err != nil {
+			panic(&store.NodeError{Err: fmt.Errorf("array: spill leaf: %w", err)})
+		}
+		data = append([]byte{objectLeaf}, values...)
+		written.values = node.values
+	} else {
+		data = []byte{objectInternal}
+		for i, child := range node.children {
+			if child == nil {
+				break
+			}
+			written.children[i] = f.spillNode(child, level-shiftStep)
+			data = binary.AppendUvarint(data, written.children[i].lazy.ref)
+		}
+	}
+
+	ref, err := f.pages.Append(data)
+	if err != nil {
+		panic(&store.NodeError{Err: fmt.Errorf("array: spill node: %w", err)})
+	}
+	f.cache.Put(ref, written)
+	return f.stub(ref)
+}
@@ -0,0 +1,207 @@
+package array
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ykhdr/persistent-data-structures/store"
+)
+
+// diskBacked переключает newTestVector на векторы, хранящиеся в VectorFile
+var diskBacked = false
+
+// newTestVector создаёт пустой вектор для тестов из vector_test.go.
+// В дисковом режиме кэш узлов крошечный, чтобы узлы постоянно перечитывались из файла.
+func newTestVector[T any](t *testing.T) *Vector[T] {
+	if !diskBacked {
+		return NewVector[T]()
+	}
+
+	f, err := CreateVectorFile[T](filepath.Join(t.TempDir(), "vector.pds"), 4)
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+	return f.Empty()
+}
+
+func TestVector_DiskBacked(t *testing.T) {
+	diskBacked = true
+	defer func() { diskBacked = false }()
+
+	for name, test := range map[string]func(*testing.T){
+		"Append":           TestVector_Append,
+		"Get":              TestVector_Get,
+		"Set":              TestVector_Set,
+		"Pop":              TestVector_Pop,
+		"Persistence":      TestVector_Persistence,
+		"LargeDataset":     TestVector_LargeDataset,
+		"Iterator":         TestVector_Iterator,
+		"Iterators":        TestVector_Iterators,
+		"NestedStructures": TestVector_NestedStructures,
+		"EdgeCases":        TestVector_EdgeCases,
+	} {
+		t.Run(name, test)
+	}
+}
+
+func TestVectorFile_Checkpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vector.pds")
+
+	f, err := CreateVectorFile[int](path, 8)
+	require.NoError(t, err)
+
+	v := f.Empty()
+	for i := 0; i < 5000; i++ {
+		v = v.Append(i)
+	}
+	v = v.Set(100, -100)
+	require.NoError(t, f.Checkpoint(v))
+	require.NoError(t, f.Close())
+
+	f, err = OpenVectorFile[int](path, 8)
+	require.NoError(t, err)
+	defer f.Close()
+
+	loaded, err := f.Load()
+	require.NoError(t, err)
+	require.Equal(t, 5000, loaded.Len())
+	for i := 0; i < 5000; i++ {
+		expected := i
+		if i == 100 {
+			expected = -100
+		}
+		val, ok := loaded.Get(i)
+		require.True(t, ok)
+		require.Equal(t, expected, val, "элемент %d", i)
+	}
+	assert.LessOrEqual(t, f.cache.Len(), 8, "в памяти не должно быть больше cacheSize узлов")
+
+	// после повторного открытия версия продолжает дописываться в тот же файл
+	next, _, _ := loaded.Append(5000).Pop()
+	assert.Equal(t, 5000, next.Len())
+}
+
+func TestVectorFile_OldVersions(t *testing.T) {
+	f, err := CreateVectorFile[int](filepath.Join(t.TempDir(), "vector.pds"), 2)
+	require.NoError(t, err)
+	defer f.Close()
+
+	v1 := f.Empty()
+	for i := 0; i < 2000; i++ {
+		v1 = v1.Append(i)
+	}
+	v2 := v1
+	for i := 0; i < 2000; i += 7 {
+		v2 = v2.Set(i, -i)
+	}
+	v3, _, _ := v2.Pop()
+
+	for i := 0; i < 2000; i++ {
+		val, _ := v1.Get(i)
+		require.Equal(t, i, val, "старая версия не должна измениться")
+	}
+	val, _ := v2.Get(700)
+	assert.Equal(t, -700, val)
+	assert.Equal(t, 1999, v3.Len())
+}
+
+func TestVectorFile_CheckpointInMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vector.pds")
+	f, err := CreateVectorFile[string](path, 16)
+	require.NoError(t, err)
+
+	v := NewVector[string]()
+	for i := 0; i < 1500; i++ {
+		v = v.Append(string(rune('a' + i%26)))
+	}
+	require.NoError(t, f.Checkpoint(v))
+	require.NoError(t, f.Close())
+
+	f, err = OpenVectorFile[string](path, 16)
+	require.NoError(t, err)
+	defer f.Close()
+
+	loaded, err := f.Load()
+	require.NoError(t, err)
+	requireVectorEqual(t, v, loaded)
+}
+
+func TestVectorFile_Errors(t *testing.T) {
+	t.Run("файл без checkpoint", func(t *testing.T) {
+		f, err := CreateVectorFile[int](filepath.Join(t.TempDir(), "vector.pds"), 4)
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = f.Load()
+		assert.ErrorIs(t, err, store.ErrNoCheckpoint)
+	})
+
+	t.Run("повреждённый заголовок", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "vector.pds")
+		require.NoError(t, os.WriteFile(path, []byte("garbage garbage garbage"), 0o644))
+
+		_, err := OpenVectorFile[int](path, 4)
+		assert.ErrorIs(t, err, store.ErrCorrupt)
+	})
+
+	t.Run("повреждённый узел", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "vector.pds")
+		f, err := CreateVectorFile[int](path, 4)
+		require.NoError(t, err)
+		require.NoError(t, f.Checkpoint(buildVector(3000)))
+		loaded, err := f.Load()
+		require.NoError(t, err)
+		root := loaded.root.lazy.ref
+		require.NoError(t, f.Close())
+
+		// запись в середине файла - один из листьев
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		flipByte(t, path, info.Size()/2)
+
+		f, err = OpenVectorFile[int](path, 4)
+		require.NoError(t, err)
+		loaded, err = f.Load()
+		require.NoError(t, err)
+
+		readAll := func() (err error) {
+			defer store.Recover(&err)
+			for range loaded.All() {
+			}
+			return nil
+		}
+		err = readAll()
+		var nodeErr *store.NodeError
+		assert.ErrorAs(t, err, &nodeErr, "обычные операции сообщают о повреждении паникой NodeError")
+		assert.ErrorIs(t, err, store.ErrCorrupt)
+
+		// повреждённый корень обнаруживает уже Load
+		require.NoError(t, f.Close())
+		flipByte(t, path, int64(root)+4)
+		f, err = OpenVectorFile[int](path, 4)
+		require.NoError(t, err)
+		defer f.Close()
+		_, err = f.Load()
+		assert.ErrorIs(t, err, store.ErrCorrupt)
+	})
+
+	t.Run("Checkpoint после закрытия файла", func(t *testing.T) {
+		f, err := CreateVectorFile[int](filepath.Join(t.TempDir(), "vector.pds"), 4)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		assert.Error(t, f.Checkpoint(buildVector(100)), "ошибка записи узла возвращается, а не паникует")
+	})
+}
+
+// flipByte инвертирует один байт файла
+func flipByte(t *testing.T, path string, offset int64) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[offset] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+}
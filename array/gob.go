@@ -0,0 +1,33 @@
+package array
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// GobEncode кодирует вектор так же, как MarshalBinary: encoding/gob не видит неэкспортируемых полей,
+// а вложенные структуры (например, Vector[*Vector[int]]) кодируются рекурсивно через их GobEncode.
+func (v *Vector[T]) GobEncode() ([]byte, error) {
+	return v.MarshalBinary()
+}
+
+func (v *Vector[T]) GobDecode(data []byte) error {
+	return v.UnmarshalBinary(data)
+}
+
+func (a *NaiveArray[T]) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(a.data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (a *NaiveArray[T]) GobDecode(data []byte) error {
+	var values []T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&values); err != nil {
+		return err
+	}
+	a.data = append(make([]T, 0, len(values)), values...)
+	return nil
+}
@@ -0,0 +1,65 @@
+package array
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gobRoundTrip прогоняет значение через gob так, как это делает RPC-слой: внутри структуры-сообщения
+func gobRoundTrip[T any](t *testing.T, value T) T {
+	t.Helper()
+	type message struct {
+		Payload T
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(message{Payload: value}))
+	var decoded message
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	return decoded.Payload
+}
+
+func TestVector_Gob(t *testing.T) {
+	t.Run("большой и пустой вектор", func(t *testing.T) {
+		for _, size := range []int{0, 1, 33, 5000} {
+			v := buildVector(size)
+			requireVectorEqual(t, v, gobRoundTrip(t, v))
+		}
+	})
+
+	t.Run("вложенные векторы", func(t *testing.T) {
+		outer := NewVector[*Vector[string]]()
+		for i := 0; i < 40; i++ {
+			inner := NewVector[string]()
+			for j := 0; j <= i; j++ {
+				inner = inner.Append(string(rune('a' + j%26)))
+			}
+			outer = outer.Append(inner)
+		}
+
+		decoded := gobRoundTrip(t, outer)
+		require.Equal(t, outer.Len(), decoded.Len())
+		for i := 0; i < outer.Len(); i++ {
+			expected, _ := outer.Get(i)
+			actual, _ := decoded.Get(i)
+			requireVectorEqual(t, expected, actual)
+		}
+	})
+
+	t.Run("NaiveArray", func(t *testing.T) {
+		a := NewNaiveArray[int]()
+		for i := 0; i < 100; i++ {
+			a = a.Append(i)
+		}
+
+		decoded := gobRoundTrip(t, a)
+		assert.Equal(t, a.Len(), decoded.Len())
+		val, _ := decoded.Get(99)
+		assert.Equal(t, 99, val)
+		assert.Equal(t, 0, gobRoundTrip(t, NewNaiveArray[int]()).Len())
+	})
+}
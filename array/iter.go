@@ -0,0 +1,136 @@
+package array
+
+import "iter"
+
+// Итераторы обходят дерево по листьям: каждый внутренний узел посещается один раз, поэтому
+// на элемент приходится O(1) амортизированно, а не спуск от корня, как в Get.
+
+// leaves обходит вектор по листьям, начиная с листа, в котором лежит индекс from: значения
+// каждого листа дерева вместе с самим узлом, в конце - хвост без узла.
+// Срезы указывают во внутренние узлы и не должны изменяться.
+func (v *Vector[T]) leaves(from int) iter.Seq2[[]T, *vectorNode[T]] {
+	return func(yield func([]T, *vectorNode[T]) bool) {
+		if from < v.tailOffset() && !walkLeaves(v.root, v.shift, from, yield) {
+			return
+		}
+		if len(v.tail) > 0 {
+			yield(v.tail, nil)
+		}
+	}
+}
+
+// leavesBackward обходит вектор по листьям в обратном порядке: сначала хвост, затем листья дерева
+func (v *Vector[T]) leavesBackward() iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		if len(v.tail) > 0 && !yield(v.tail) {
+			return
+		}
+		if tailOffset := v.tailOffset(); tailOffset > 0 {
+			walkLeavesBackward(v.root, v.shift, tailOffset-1, yield)
+		}
+	}
+}
+
+// walkLeaves передаёт yield листья поддерева уровня level (0 - лист) по порядку, начиная с листа,
+// в котором лежит индекс from. Возвращает false, если yield остановил обход.
+func walkLeaves[T any](node *vectorNode[T], level uint, from int, yield func([]T, *vectorNode[T]) bool) bool {
+	node = node.resolve()
+	if level == 0 {
+		return yield(node.values[:], node)
+	}
+	for i := (from >> level) & indexMask; i < nodeWidth; i++ {
+		child := node.children[i]
+		if child == nil {
+			break
+		}
+		if !walkLeaves(child, level-shiftStep, from, yield) {
+			return false
+		}
+		// следующие поддеревья обходятся целиком
+		from = 0
+	}
+	return true
+}
+
+// walkLeavesBackward передаёт yield листья поддерева в обратном порядке, начиная с листа,
+// в котором лежит индекс to
+func walkLeavesBackward[T any](node *vectorNode[T], level uint, to int, yield func([]T) bool) bool {
+	node = node.resolve()
+	if level == 0 {
+		return yield(node.values[:])
+	}
+	for i := (to >> level) & indexMask; i >= 0; i-- {
+		if !walkLeavesBackward(node.children[i], level-shiftStep, to, yield) {
+			return false
+		}
+		// предыдущие поддеревья заполнены целиком
+		to = -1
+	}
+	return true
+}
+
+func (v *Vector[T]) All() iter.Seq2[int, T] {
+	return v.Range(0, v.len)
+}
+
+func (v *Vector[T]) Values() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for values := range v.leaves(0) {
+			for _, value := range values {
+				if !yield(value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Chunks обходит вектор по листьям: каждый срез - значения одного листа, по 32 элемента,
+// кроме последнего. Срезы разделяют память с вектором и не должны изменяться.
+func (v *Vector[T]) Chunks() iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		for values := range v.leaves(0) {
+			if !yield(values[:len(values):len(values)]) {
+				return
+			}
+		}
+	}
+}
+
+// Range обходит элементы с индексами из [from, to) вместе с индексами. Границы приводятся
+// к [0, Len), как в Take и Drop; при from >= to последовательность пуста.
+func (v *Vector[T]) Range(from, to int) iter.Seq2[int, T] {
+	from, to = max(0, from), min(to, v.len)
+	return func(yield func(int, T) bool) {
+		if from >= to {
+			return
+		}
+		index := from &^ indexMask
+		for values := range v.leaves(from) {
+			for _, value := range values {
+				if index >= to {
+					return
+				}
+				if index >= from && !yield(index, value) {
+					return
+				}
+				index++
+			}
+		}
+	}
+}
+
+// Backward обходит элементы от последнего к первому вместе с индексами
+func (v *Vector[T]) Backward() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		index := v.len - 1
+		for values := range v.leavesBackward() {
+			for i := len(values) - 1; i >= 0; i-- {
+				if !yield(index, values[i]) {
+					return
+				}
+				index--
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package array
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collect2[T any](seq iter.Seq2[int, T]) (indices []int, values []T) {
+	indices, values = []int{}, []T{}
+	for i, value := range seq {
+		indices = append(indices, i)
+		values = append(values, value)
+	}
+	return indices, values
+}
+
+func TestVector_Iterators(t *testing.T) {
+	// размеры на границах листа, хвоста и уровней дерева
+	for _, size := range []int{0, 1, 31, 32, 33, 64, 1056, 1057, 1088, 33000} {
+		t.Run(fmt.Sprintf("размер %d", size), func(t *testing.T) {
+			v := newTestVector[int](t)
+			expected := make([]int, size)
+			for i := range expected {
+				expected[i] = i * 3
+				v = v.Append(i * 3)
+			}
+			// после Pop дерево может стать ниже, а последний лист - хвостом
+			popped, _, _ := v.Pop()
+
+			for _, version := range []struct {
+				v      *Vector[int]
+				values []int
+			}{{v, expected}, {popped, expected[:max(0, size-1)]}} {
+				v, expected := version.v, version.values
+				indices := make([]int, len(expected))
+				for i := range indices {
+					indices[i] = i
+				}
+
+				gotIndices, gotValues := collect2(v.All())
+				require.Equal(t, indices, gotIndices)
+				require.Equal(t, expected, gotValues)
+				require.Equal(t, expected, slices.AppendSeq([]int{}, v.Values()))
+
+				gotIndices, gotValues = collect2(v.Backward())
+				reversed := slices.Clone(expected)
+				slices.Reverse(reversed)
+				require.Equal(t, reversed, gotValues)
+				require.Len(t, gotIndices, len(expected))
+				for i, index := range gotIndices {
+					require.Equal(t, len(expected)-1-i, index)
+				}
+
+				chunked := []int{}
+				for chunk := range v.Chunks() {
+					require.NotEmpty(t, chunk)
+					require.LessOrEqual(t, len(chunk), nodeWidth)
+					require.Equal(t, len(chunk), cap(chunk), "append к срезу не должен затирать хвост вектора")
+					chunked = append(chunked, chunk...)
+				}
+				require.Equal(t, expected, chunked)
+
+				n := len(expected)
+				for _, bounds := range [][2]int{{0, n}, {-5, n + 5}, {1, n - 1}, {31, 33}, {32, 64}, {n / 2, n / 2}, {n, 0}, {n - 1, n}} {
+					from, to := bounds[0], bounds[1]
+					lo, hi := max(0, from), min(n, to)
+					gotIndices, gotValues := collect2(v.Range(from, to))
+					if lo >= hi {
+						require.Empty(t, gotValues, "Range(%d, %d)", from, to)
+						continue
+					}
+					require.Equal(t, expected[lo:hi], gotValues, "Range(%d, %d)", from, to)
+					require.Equal(t, indices[lo:hi], gotIndices, "Range(%d, %d)", from, to)
+				}
+			}
+		})
+	}
+}
+
+func TestVector_IteratorsBreak(t *testing.T) {
+	v, _ := rangeVector(1000)
+
+	var got []int
+	for i, value := range v.Range(100, 900) {
+		if i == 140 {
+			break
+		}
+		got = append(got, value)
+	}
+	assert.Len(t, got, 40)
+
+	got = got[:0]
+	for _, value := range v.Backward() {
+		if value < 990 {
+			break
+		}
+		got = append(got, value)
+	}
+	assert.Equal(t, []int{999, 998, 997, 996, 995, 994, 993, 992, 991, 990}, got)
+
+	chunks := 0
+	for range v.Chunks() {
+		chunks++
+		if chunks == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, chunks)
+}
@@ -0,0 +1,16 @@
+package array
+
+import "encoding/json"
+
+func (v *Vector[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.ToSlice())
+}
+
+func (v *Vector[T]) UnmarshalJSON(data []byte) error {
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*v = *FromSlice(values)
+	return nil
+}
@@ -0,0 +1,111 @@
+package array
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVector_JSON(t *testing.T) {
+	t.Run("кодирование в JSON-массив", func(t *testing.T) {
+		v := NewVector[int]().Append(1).Append(2).Append(3)
+
+		data, err := json.Marshal(v)
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `[1, 2, 3]`, string(data))
+	})
+
+	t.Run("пустой вектор", func(t *testing.T) {
+		data, err := json.Marshal(NewVector[int]())
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `[]`, string(data))
+
+		var v Vector[int]
+		require.NoError(t, json.Unmarshal([]byte(`[]`), &v))
+		assert.Equal(t, 0, v.Len())
+		assert.Equal(t, 1, v.Append(1).Len(), "декодированный пустой вектор должен быть рабочим")
+	})
+
+	t.Run("ошибка декодирования", func(t *testing.T) {
+		var v Vector[int]
+		assert.Error(t, json.Unmarshal([]byte(`{"a": 1}`), &v))
+	})
+
+	sizes := []int{1, 31, 32, 33, 64, 65, 1024, 1056, 1057, 10000, 40000}
+	for _, size := range sizes {
+		t.Run(fmt.Sprintf("round-trip size_%d", size), func(t *testing.T) {
+			v := NewVector[int]()
+			for i := 0; i < size; i++ {
+				v = v.Append(i)
+			}
+
+			data, err := json.Marshal(v)
+			require.NoError(t, err)
+
+			var decoded Vector[int]
+			require.NoError(t, json.Unmarshal(data, &decoded))
+
+			require.Equal(t, size, decoded.Len())
+			for i := 0; i < size; i++ {
+				val, ok := decoded.Get(i)
+				require.True(t, ok, "элемент %d должен существовать", i)
+				require.Equal(t, i, val)
+			}
+
+			// дерево, построенное снизу вверх, должно поддерживать дальнейшие изменения
+			grown := decoded.Append(size).Set(0, -1)
+			val, _ := grown.Get(size)
+			assert.Equal(t, size, val)
+			val, _ = grown.Get(0)
+			assert.Equal(t, -1, val)
+
+			shrunk := &decoded
+			for i := size - 1; i >= 0; i-- {
+				var last int
+				shrunk, last, _ = shrunk.Pop()
+				require.Equal(t, i, last)
+			}
+			assert.Equal(t, 0, shrunk.Len())
+		})
+	}
+}
+
+func TestVector_JSONNested(t *testing.T) {
+	t.Run("вектор векторов", func(t *testing.T) {
+		outer := NewVector[*Vector[string]]().
+			Append(NewVector[string]().Append("a").Append("b")).
+			Append(NewVector[string]())
+
+		data, err := json.Marshal(outer)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[["a", "b"], []]`, string(data))
+
+		var decoded Vector[*Vector[string]]
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		inner, _ := decoded.Get(0)
+		val, _ := inner.Get(1)
+		assert.Equal(t, "b", val)
+	})
+
+	t.Run("вектор внутри структуры", func(t *testing.T) {
+		type Doc struct {
+			Name  string       `json:"name"`
+			Items *Vector[int] `json:"items"`
+		}
+
+		data, err := json.Marshal(Doc{Name: "doc", Items: NewVector[int]().Append(7)})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name": "doc", "items": [7]}`, string(data))
+
+		var decoded Doc
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		val, _ := decoded.Items.Get(0)
+		assert.Equal(t, 7, val)
+	})
+}
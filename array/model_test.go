@@ -0,0 +1,78 @@
+package array
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ykhdr/persistent-data-structures/pdstest"
+)
+
+// vectorModel сравнивает Vector, начиная с empty, с NaiveArray. Индексы Set и Get выбираются
+// из [-1, Len], чтобы попадать и за границы массива, а AppendN быстро наращивает дерево.
+func vectorModel(empty *Vector[int]) pdstest.Model[*Vector[int], *NaiveArray[int]] {
+	return pdstest.Model[*Vector[int], *NaiveArray[int]]{
+		Ops:   []string{"Append", "AppendN", "Set", "Pop", "Get"},
+		Empty: empty,
+		Ref:   NewNaiveArray[int](),
+		Apply: func(v *Vector[int], a *NaiveArray[int], op int, arg int) (*Vector[int], *NaiveArray[int], error) {
+			index := arg%(a.Len()+2) - 1
+			switch op {
+			case 0:
+				return v.Append(arg), a.Append(arg), nil
+			case 1:
+				for i := 0; i <= arg%70; i++ {
+					v, a = v.Append(arg+i), a.Append(arg+i)
+				}
+				return v, a, nil
+			case 2:
+				return v.Set(index, -arg), a.Set(index, -arg), nil
+			case 3:
+				nextV, got, gotOk := v.Pop()
+				nextA, want, wantOk := a.Pop()
+				if got != want || gotOk != wantOk {
+					return nil, nil, fmt.Errorf("Pop: %d %v, ожидалось %d %v", got, gotOk, want, wantOk)
+				}
+				return nextV, nextA, nil
+			default:
+				got, gotOk := v.Get(index)
+				want, wantOk := a.Get(index)
+				if got != want || gotOk != wantOk {
+					return nil, nil, fmt.Errorf("Get(%d): %d %v, ожидалось %d %v", index, got, gotOk, want, wantOk)
+				}
+				return v, a, nil
+			}
+		},
+		Equal: func(v *Vector[int], a *NaiveArray[int]) error {
+			if v.Len() != a.Len() {
+				return fmt.Errorf("Len: %d, ожидалось %d", v.Len(), a.Len())
+			}
+			return pdstest.EqualSeq(v.Values(), a.Values())
+		},
+	}
+}
+
+func TestVector_Model(t *testing.T) {
+	t.Run("в памяти", func(t *testing.T) {
+		vectorModel(NewVector[int]()).Run(t)
+	})
+
+	t.Run("в файле", func(t *testing.T) {
+		f, err := CreateVectorFile[int](filepath.Join(t.TempDir(), "vector.pds"), 16)
+		require.NoError(t, err)
+		t.Cleanup(func() { f.Close() })
+		vectorModel(f.Empty()).Run(t)
+	})
+}
+
+func FuzzVector(f *testing.F) {
+	f.Add([]byte{0, 0, 1, 0, 1, 1, 40, 0, 3, 1, 0, 0, 2, 1, 5, 0})
+	f.Add([]byte{1, 0, 69, 0, 1, 1, 69, 0, 3, 0, 0, 0, 3, 2, 0, 0, 4, 1, 30, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := vectorModel(NewVector[int]()).Execute(pdstest.StepsFromBytes(data)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
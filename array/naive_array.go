@@ -1,19 +1,23 @@
 package array
 
-import "iter"
+import (
+	"iter"
+
+	pds "github.com/ykhdr/persistent-data-structures"
+)
 
 type NaiveArray[T any] struct {
 	data []T
 }
 
+var _ pds.Indexed[int, *NaiveArray[int]] = (*NaiveArray[int])(nil)
+
 func NewNaiveArray[T any]() *NaiveArray[T] {
 	return &NaiveArray[T]{
 		data: make([]T, 0),
 	}
 }
 
-func (a *NaiveArray[T]) sealed() {}
-
 func (a *NaiveArray[T]) Len() int {
 	return len(a.data)
 }
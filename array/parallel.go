@@ -0,0 +1,197 @@
+package array
+
+import "github.com/ykhdr/persistent-data-structures/internal/parallel"
+
+// Параллельные операции делят дерево на поддеревья одного уровня, начиная с детей корня, и обрабатывают
+// их в workers горутинах; неположительное workers означает GOMAXPROCS. Хвост обрабатывается отдельной
+// задачей. Деление зависит только от длины вектора, поэтому результат не зависит от числа горутин
+// и порядка их выполнения. Функции вызываются конкурентно и не должны разделять изменяемое состояние.
+
+// parallelTasks - сколько поддеревьев нужно набрать, прежде чем остановить спуск: с запасом на
+// неравномерную стоимость задач при любом разумном числе горутин
+const parallelTasks = 64
+
+// subtrees делит дерево на поддеревья одного уровня: спуск идёт от корня, пока поддеревьев меньше
+// parallelTasks. Поддеревья возвращаются в порядке индексов, level - их уровень (0 - листья).
+func (v *Vector[T]) subtrees() ([]*vectorNode[T], uint) {
+	if v.root == nil {
+		return nil, 0
+	}
+
+	nodes, level := []*vectorNode[T]{v.root}, v.shift
+	for len(nodes) < parallelTasks && level > 0 {
+		var children []*vectorNode[T]
+		for _, node := range nodes {
+			for _, child := range node.resolve().children {
+				if child == nil {
+					break
+				}
+				children = append(children, child)
+			}
+		}
+		nodes, level = children, level-shiftStep
+	}
+	return nodes, level
+}
+
+// ParallelMap - параллельный Map. Результат повторяет форму дерева v, поэтому поддеревья
+// отображаются независимо и собираются обратно без перестройки.
+func ParallelMap[T, U any](v *Vector[T], workers int, fn func(T) U) *Vector[U] {
+	result := &Vector[U]{len: v.len, shift: v.shift, tail: make([]U, len(v.tail), nodeWidth)}
+	for i, value := range v.tail {
+		result.tail[i] = fn(value)
+	}
+	if v.root == nil {
+		return result
+	}
+
+	nodes, level := v.subtrees()
+	mapped := make([]*vectorNode[U], len(nodes))
+	parallel.Run(workers, len(nodes), func(i int) {
+		mapped[i] = mapSubtree(nodes[i], level, fn)
+	})
+
+	// верхние уровни над поддеревьями копируются со своей формой, а на уровне level подставляются результаты
+	next := 0
+	var rebuild func(node *vectorNode[T], at uint) *vectorNode[U]
+	rebuild = func(node *vectorNode[T], at uint) *vectorNode[U] {
+		if at == level {
+			next++
+			return mapped[next-1]
+		}
+		copied := &vectorNode[U]{}
+		for i, child := range node.resolve().children {
+			if child == nil {
+				break
+			}
+			copied.children[i] = rebuild(child, at-shiftStep)
+		}
+		return copied
+	}
+	result.root = rebuild(v.root, v.shift)
+	return result
+}
+
+func mapSubtree[T, U any](node *vectorNode[T], level uint, fn func(T) U) *vectorNode[U] {
+	node = node.resolve()
+	mapped := &vectorNode[U]{}
+	if level == 0 {
+		for i, value := range node.values {
+			mapped.values[i] = fn(value)
+		}
+		return mapped
+	}
+	for i, child := range node.children {
+		if child == nil {
+			break
+		}
+		mapped.children[i] = mapSubtree(child, level-shiftStep, fn)
+	}
+	return mapped
+}
+
+// ParallelFilter - параллельный Filter. Каждая горутина отбирает элементы своего поддерева,
+// затем части по своим смещениям раскладываются в листья нового вектора, и дерево строится снизу вверх.
+// Если подходят все элементы, возвращается сам v.
+func ParallelFilter[T any](v *Vector[T], workers int, pred func(T) bool) *Vector[T] {
+	nodes, level := v.subtrees()
+	parts := make([][]T, len(nodes)+1)
+	parallel.Run(workers, len(parts), func(i int) {
+		keep := func(values []T, _ *vectorNode[T]) bool {
+			for _, value := range values {
+				if pred(value) {
+					parts[i] = append(parts[i], value)
+				}
+			}
+			return true
+		}
+		if i == len(nodes) {
+			keep(v.tail, nil)
+		} else {
+			walkLeaves(nodes[i], level, 0, keep)
+		}
+	})
+
+	total := 0
+	offsets := make([]int, len(parts))
+	for i, part := range parts {
+		offsets[i] = total
+		total += len(part)
+	}
+	switch total {
+	case v.len:
+		return v
+	case 0:
+		return NewVector[T]()
+	}
+
+	tailOffset := ((total - 1) >> shiftStep) << shiftStep
+	leaves := make([]*vectorNode[T], tailOffset/nodeWidth)
+	for i := range leaves {
+		leaves[i] = &vectorNode[T]{}
+	}
+	tail := make([]T, total-tailOffset, nodeWidth)
+	// части пишут в непересекающиеся позиции, даже если делят крайний лист
+	parallel.Run(workers, len(parts), func(i int) {
+		for j, value := range parts[i] {
+			if at := offsets[i] + j; at < tailOffset {
+				leaves[at>>shiftStep].values[at&indexMask] = value
+			} else {
+				tail[at-tailOffset] = value
+			}
+		}
+	})
+	return fromLeaves(leaves, tail)
+}
+
+// ParallelReduce сворачивает каждое поддерево функцией fn, начиная с identity, и объединяет
+// результаты поддеревьев слева направо через combine. Результат совпадает с
+// Reduce(v, identity, fn), если combine ассоциативна, identity - её нейтральный элемент, а fn
+// согласована с combine: fn(a, x) == combine(a, fn(identity, x)).
+func ParallelReduce[T, A any](v *Vector[T], workers int, identity A, fn func(A, T) A, combine func(A, A) A) A {
+	nodes, level := v.subtrees()
+	results := make([]A, len(nodes)+1)
+	parallel.Run(workers, len(results), func(i int) {
+		acc := identity
+		reduce := func(values []T, _ *vectorNode[T]) bool {
+			for _, value := range values {
+				acc = fn(acc, value)
+			}
+			return true
+		}
+		if i == len(nodes) {
+			reduce(v.tail, nil)
+		} else {
+			walkLeaves(nodes[i], level, 0, reduce)
+		}
+		results[i] = acc
+	})
+
+	acc := identity
+	for _, r := range results {
+		acc = combine(acc, r)
+	}
+	return acc
+}
+
+// ParallelForEach вызывает fn для каждого элемента с его индексом. Порядок вызовов не определён.
+func ParallelForEach[T any](v *Vector[T], workers int, fn func(index int, value T)) {
+	nodes, level := v.subtrees()
+	span := 1 << (level + shiftStep)
+	parallel.Run(workers, len(nodes)+1, func(i int) {
+		if i == len(nodes) {
+			for j, value := range v.tail {
+				fn(v.tailOffset()+j, value)
+			}
+			return
+		}
+		index := i * span
+		walkLeaves(nodes[i], level, 0, func(values []T, _ *vectorNode[T]) bool {
+			for _, value := range values {
+				fn(index, value)
+				index++
+			}
+			return true
+		})
+	})
+}
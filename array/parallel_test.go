@@ -0,0 +1,101 @@
+package array
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVector_Parallel(t *testing.T) {
+	even := func(x int) bool { return x%3 != 0 }
+	sum := func(acc, x int) int { return acc + x }
+	add := func(a, b int) int { return a + b }
+
+	for _, size := range []int{0, 1, 32, 33, 1057, 40000} {
+		t.Run(fmt.Sprintf("размер %d", size), func(t *testing.T) {
+			v, values := rangeVector(size)
+
+			for _, workers := range []int{1, 2, 8, -1} {
+				mapped := ParallelMap(v, workers, func(x int) string { return strconv.Itoa(x * 2) })
+				assert.Equal(t, Map(v, func(x int) string { return strconv.Itoa(x * 2) }).ToSlice(), mapped.ToSlice(), "workers %d", workers)
+				// результат - полноценный вектор той же формы
+				assert.Equal(t, size+1, mapped.Append("x").Len())
+
+				assert.Equal(t, Filter(v, even).ToSlice(), ParallelFilter(v, workers, even).ToSlice(), "workers %d", workers)
+				assert.Equal(t, Reduce(v, 0, sum), ParallelReduce(v, workers, 0, sum, add), "workers %d", workers)
+
+				seen := make([]int, size)
+				ParallelForEach(v, workers, func(i, x int) { seen[i] = x + 1 })
+				for i, x := range values {
+					require.Equal(t, x+1, seen[i], "workers %d индекс %d", workers, i)
+				}
+			}
+		})
+	}
+}
+
+func TestVector_ParallelDeterminism(t *testing.T) {
+	v, _ := rangeVector(40000)
+
+	// конкатенация строк ассоциативна, но не коммутативна: любой сбой порядка изменит результат
+	concat := func(acc string, x int) string { return acc + strconv.Itoa(x%10) }
+	join := func(a, b string) string { return a + b }
+	expected := Reduce(v, "", concat)
+	for _, workers := range []int{1, 2, 3, 8, 64, -1} {
+		assert.Equal(t, expected, ParallelReduce(v, workers, "", concat, join), "workers %d", workers)
+	}
+
+	// неассоциативная операция: результат другой, но одинаковый при любом числе горутин
+	sub := func(acc, x int) int { return x - acc }
+	first := ParallelReduce(v, 1, 0, sub, sub)
+	for _, workers := range []int{2, 8, -1} {
+		assert.Equal(t, first, ParallelReduce(v, workers, 0, sub, sub), "workers %d", workers)
+	}
+}
+
+func TestVector_ParallelFilterSharing(t *testing.T) {
+	v, _ := rangeVector(5000)
+	assert.Same(t, v, ParallelFilter(v, 4, func(int) bool { return true }))
+	assert.Equal(t, 0, ParallelFilter(v, 4, func(int) bool { return false }).Len())
+}
+
+func TestVector_ParallelForEachConcurrent(t *testing.T) {
+	v, _ := rangeVector(10000)
+
+	var mu sync.Mutex
+	visited := map[int]int{}
+	ParallelForEach(v, 8, func(i, x int) {
+		mu.Lock()
+		visited[i]++
+		mu.Unlock()
+	})
+	require.Len(t, visited, 10000)
+	for i, count := range visited {
+		require.Equal(t, 1, count, "индекс %d", i)
+	}
+}
+
+func TestVector_ParallelDiskBacked(t *testing.T) {
+	f, err := CreateVectorFile[int](filepath.Join(t.TempDir(), "vector.pds"), 4)
+	require.NoError(t, err)
+	defer f.Close()
+
+	v := f.Empty()
+	for i := 0; i < 3000; i++ {
+		v = v.Append(i)
+	}
+	require.NoError(t, f.Checkpoint(v))
+	v, err = f.Load()
+	require.NoError(t, err)
+
+	doubled := ParallelMap(v, 4, func(x int) int { return x * 2 })
+	assert.Equal(t, Map(v, func(x int) int { return x * 2 }).ToSlice(), doubled.ToSlice())
+	assert.Equal(t, Filter(v, func(x int) bool { return x%7 == 0 }).ToSlice(),
+		ParallelFilter(v, 4, func(x int) bool { return x%7 == 0 }).ToSlice())
+	assert.Equal(t, 3000*2999/2, ParallelReduce(v, 4, 0, func(acc, x int) int { return acc + x }, func(a, b int) int { return a + b }))
+}
@@ -0,0 +1,82 @@
+package array
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrIndexOutOfRange возвращают операции вставки и удаления, если индекс или диапазон
+// выходит за границы вектора
+var ErrIndexOutOfRange = errors.New("array: index out of range")
+
+// Insert вставляет values перед элементом index, index из [0, Len]; index == Len добавляет их в конец
+func (v *Vector[T]) Insert(index int, values ...T) (*Vector[T], error) {
+	if index < 0 || index > v.len {
+		return nil, fmt.Errorf("%w: insert at %d, length %d", ErrIndexOutOfRange, index, v.len)
+	}
+	return v.splice(index, 0, values), nil
+}
+
+// Prepend вставляет values в начало вектора
+func (v *Vector[T]) Prepend(values ...T) *Vector[T] {
+	return v.splice(0, 0, values)
+}
+
+// RemoveAt удаляет элемент index, index из [0, Len)
+func (v *Vector[T]) RemoveAt(index int) (*Vector[T], error) {
+	if index < 0 || index >= v.len {
+		return nil, fmt.Errorf("%w: remove at %d, length %d", ErrIndexOutOfRange, index, v.len)
+	}
+	return v.splice(index, 1, nil), nil
+}
+
+// RemoveRange удаляет элементы с индексами из [from, to), 0 <= from <= to <= Len
+func (v *Vector[T]) RemoveRange(from, to int) (*Vector[T], error) {
+	if from < 0 || from > to || to > v.len {
+		return nil, fmt.Errorf("%w: remove [%d, %d), length %d", ErrIndexOutOfRange, from, to, v.len)
+	}
+	return v.splice(from, to-from, nil), nil
+}
+
+// Splice удаляет deleteCount элементов начиная с index и вставляет на их место values.
+// index должен быть из [0, Len], а удаляемые элементы - лежать внутри вектора.
+func (v *Vector[T]) Splice(index, deleteCount int, values ...T) (*Vector[T], error) {
+	if index < 0 || index > v.len || deleteCount < 0 || deleteCount > v.len-index {
+		return nil, fmt.Errorf("%w: splice at %d deleting %d, length %d", ErrIndexOutOfRange, index, deleteCount, v.len)
+	}
+	return v.splice(index, deleteCount, values), nil
+}
+
+// splice выполняет проверенную правку. Если правка не выходит за хвост и хвост остаётся непустым,
+// меняется только хвост, как в Append. Иначе вектор собирается заново через builder:
+// листья до index переиспользуются, а листья после правки - если сдвиг кратен ширине листа.
+// В обоих случаях результат хранится там же, где v.
+func (v *Vector[T]) splice(index, deleteCount int, values []T) *Vector[T] {
+	if deleteCount == 0 && len(values) == 0 {
+		return v
+	}
+
+	tailOffset := v.tailOffset()
+	tailLen := len(v.tail) - deleteCount + len(values)
+	if index >= tailOffset && tailLen <= nodeWidth && (tailLen > 0 || v.root == nil) {
+		at := index - tailOffset
+		newTail := make([]T, 0, tailLen)
+		newTail = append(newTail, v.tail[:at]...)
+		newTail = append(newTail, values...)
+		newTail = append(newTail, v.tail[at+deleteCount:]...)
+		return v.spill(&Vector[T]{
+			root:  v.root,
+			tail:  newTail,
+			len:   tailOffset + tailLen,
+			shift: v.shift,
+		})
+	}
+
+	var b builder[T]
+	b.addRange(v, 0, index)
+	for _, value := range values {
+		b.add(value)
+	}
+	b.addRange(v, index+deleteCount, v.len)
+	return v.spill(b.build())
+}
@@ -0,0 +1,164 @@
+package array
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVector_Splice(t *testing.T) {
+	for _, size := range []int{0, 1, 31, 32, 33, 100, 1057, 5000} {
+		t.Run(fmt.Sprintf("размер %d", size), func(t *testing.T) {
+			v, values := rangeVector(size)
+			inserted := []int{-1, -2, -3}
+
+			for _, index := range []int{0, 1, size / 2, size - 1, size} {
+				if index < 0 || index > size {
+					continue
+				}
+				got, err := v.Insert(index, inserted...)
+				require.NoError(t, err)
+				require.Equal(t, slices.Insert(slices.Clone(values), index, inserted...), got.ToSlice(), "Insert(%d)", index)
+
+				if index < size {
+					got, err = v.RemoveAt(index)
+					require.NoError(t, err)
+					require.Equal(t, slices.Delete(slices.Clone(values), index, index+1), got.ToSlice(), "RemoveAt(%d)", index)
+				}
+
+				to := min(size, index+40)
+				got, err = v.RemoveRange(index, to)
+				require.NoError(t, err)
+				require.Equal(t, slices.Delete(slices.Clone(values), index, to), got.ToSlice(), "RemoveRange(%d, %d)", index, to)
+
+				got, err = v.Splice(index, to-index, inserted...)
+				require.NoError(t, err)
+				require.Equal(t, slices.Replace(slices.Clone(values), index, to, inserted...), got.ToSlice(), "Splice(%d, %d)", index, to-index)
+			}
+
+			assert.Equal(t, slices.Concat(inserted, values), v.Prepend(inserted...).ToSlice())
+			assert.Equal(t, values, v.ToSlice(), "исходный вектор не меняется")
+		})
+	}
+}
+
+func TestVector_SpliceRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	v := NewVector[int]()
+	var expected []int
+
+	for step := 0; step < 2000; step++ {
+		index := r.Intn(len(expected) + 1)
+		deleteCount := r.Intn(len(expected) - index + 1)
+		if r.Intn(2) == 0 {
+			deleteCount = min(deleteCount, 3)
+		}
+		values := make([]int, r.Intn(50))
+		for i := range values {
+			values[i] = step*100 + i
+		}
+
+		next, err := v.Splice(index, deleteCount, values...)
+		require.NoError(t, err)
+		expected = slices.Replace(expected, index, index+deleteCount, values...)
+		require.Equal(t, expected, next.ToSlice(), "шаг %d: Splice(%d, %d, %d значений)", step, index, deleteCount, len(values))
+		// результат - обычный вектор, к которому применимы остальные операции
+		if next.Len() > 0 {
+			popped, last, _ := next.Pop()
+			require.Equal(t, expected[len(expected)-1], last)
+			require.Equal(t, len(expected)-1, popped.Len())
+		}
+		v = next
+	}
+}
+
+func TestVector_SpliceErrors(t *testing.T) {
+	v, _ := rangeVector(10)
+
+	for name, call := range map[string]func() (*Vector[int], error){
+		"Insert < 0":            func() (*Vector[int], error) { return v.Insert(-1, 1) },
+		"Insert > Len":          func() (*Vector[int], error) { return v.Insert(11, 1) },
+		"RemoveAt Len":          func() (*Vector[int], error) { return v.RemoveAt(10) },
+		"RemoveAt < 0":          func() (*Vector[int], error) { return v.RemoveAt(-1) },
+		"RemoveRange from > to": func() (*Vector[int], error) { return v.RemoveRange(5, 4) },
+		"RemoveRange to > Len":  func() (*Vector[int], error) { return v.RemoveRange(5, 11) },
+		"Splice за концом":      func() (*Vector[int], error) { return v.Splice(8, 3) },
+		"Splice count < 0":      func() (*Vector[int], error) { return v.Splice(0, -1) },
+		"RemoveAt пустого":      func() (*Vector[int], error) { return NewVector[int]().RemoveAt(0) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := call()
+			assert.ErrorIs(t, err, ErrIndexOutOfRange)
+			assert.Nil(t, got)
+		})
+	}
+
+	_, err := v.RemoveRange(5, 11)
+	assert.EqualError(t, err, "array: index out of range: remove [5, 11), length 10")
+}
+
+func TestVector_SpliceSharing(t *testing.T) {
+	v, _ := rangeVector(5000)
+
+	same, err := v.Splice(100, 0)
+	require.NoError(t, err)
+	assert.Same(t, v, same, "пустая правка возвращает тот же вектор")
+
+	// правка внутри хвоста меняет только хвост
+	tailEdit, err := v.Insert(4995, -1)
+	require.NoError(t, err)
+	assert.Same(t, v.root, tailEdit.root)
+
+	// сдвиг на целый лист сохраняет выравнивание: листья после вставки переиспользуются
+	prepended := v.Prepend(make([]int, nodeWidth)...)
+	for i := 0; i < v.tailOffset(); i += nodeWidth {
+		require.Same(t, v.getLeaf(i), prepended.getLeaf(i+nodeWidth), "лист %d", i)
+	}
+
+	// листья до места правки переиспользуются всегда
+	removed, err := v.RemoveAt(2000)
+	require.NoError(t, err)
+	for i := 0; i < 2000&^indexMask; i += nodeWidth {
+		require.Same(t, v.getLeaf(i), removed.getLeaf(i), "лист %d", i)
+	}
+}
+
+func TestVector_SpliceDiskBacked(t *testing.T) {
+	f, err := CreateVectorFile[int](filepath.Join(t.TempDir(), "vector.pds"), 4)
+	require.NoError(t, err)
+	defer f.Close()
+
+	v := f.Empty()
+	expected := make([]int, 0, 3000)
+	for i := 0; i < 3000; i++ {
+		v = v.Append(i)
+		expected = append(expected, i)
+	}
+
+	// правки в середине собираются через builder, но результат остаётся в файле
+	v, err = v.Insert(1000, -1, -2)
+	require.NoError(t, err)
+	expected = slices.Insert(expected, 1000, -1, -2)
+	v, err = v.RemoveAt(17)
+	require.NoError(t, err)
+	expected = slices.Delete(expected, 17, 18)
+	v, err = v.Splice(500, 40, 7, 8, 9)
+	require.NoError(t, err)
+	expected = slices.Replace(expected, 500, 540, 7, 8, 9)
+	v = v.Prepend(-3)
+	expected = slices.Insert(expected, 0, -3)
+
+	assert.Equal(t, f, v.loader, "результат правки хранится в том же файле")
+	require.NotNil(t, v.root.lazy, "корень записан в файл")
+	assert.Equal(t, expected, v.ToSlice())
+
+	require.NoError(t, f.Checkpoint(v))
+	loaded, err := f.Load()
+	require.NoError(t, err)
+	assert.Equal(t, expected, loaded.ToSlice())
+}
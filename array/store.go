@@ -0,0 +1,203 @@
+package array
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ykhdr/persistent-data-structures/store"
+)
+
+const (
+	objectLeaf byte = iota + 1
+	objectInternal
+	objectVersion
+)
+
+// storeCacheSize - сколько прочитанных из хранилища узлов VectorStore держит в памяти
+const storeCacheSize = 4096
+
+// VectorStore сохраняет версии вектора в store.Store: каждый узел дерева - отдельный объект,
+// адресуемый хэшем содержимого, поэтому неизменённые поддеревья разных версий хранятся один раз.
+//
+// Load читает только объект версии, а узлы дерева подгружаются при первом обращении и держатся
+// в кэше на storeCacheSize узлов. Как и у VectorFile, ошибка чтения узла или повреждённый объект
+// приводят к панике *store.NodeError при обращении к нему, поэтому объекты загруженной версии
+// должны оставаться достижимыми из ссылок, пока версия используется.
+type VectorStore[T any] struct {
+	store *store.Store
+	refs  *store.HashRefs
+	cache *store.Cache[*vectorNode[T]]
+	// хэши резидентных узлов последней сохранённой версии:
+	// при следующем Save общие с ней поддеревья не обходятся повторно
+	hashes map[*vectorNode[T]]store.Hash
+}
+
+func NewVectorStore[T any](s *store.Store) *VectorStore[T] {
+	return &VectorStore[T]{
+		store:  s,
+		refs:   store.NewHashRefs(),
+		cache:  store.NewCache[*vectorNode[T]](storeCacheSize),
+		hashes: make(map[*vectorNode[T]]store.Hash),
+	}
+}
+
+// Save записывает версию и возвращает хэш её корневого объекта.
+// Поддеревья, загруженные этим VectorStore и не изменённые с тех пор, не читаются и не записываются.
+func (s *VectorStore[T]) Save(v *Vector[T]) (root store.Hash, err error) {
+	defer s.store.Pin()()
+	defer store.Recover(&err)
+
+	hashes := make(map[*vectorNode[T]]store.Hash)
+
+	var links []store.Hash
+	if v.root != nil {
+		h, err := s.saveNode(v.root, v.shift, hashes)
+		if err != nil {
+			return store.Hash{}, err
+		}
+		links = append(links, h)
+	}
+
+	tail, err := store.EncodeValues(v.tail)
+	if err != nil {
+		return store.Hash{}, err
+	}
+	data := []byte{objectVersion}
+	data = binary.AppendUvarint(data, uint64(v.len))
+	data = binary.AppendUvarint(data, uint64(v.shift))
+	data = append(data, tail...)
+
+	h, err := s.store.Put(store.Object{Links: links, Data: data})
+	if err != nil {
+		return store.Hash{}, err
+	}
+
+	s.hashes = hashes
+	return h, nil
+}
+
+func (s *VectorStore[T]) saveNode(node *vectorNode[T], level uint, hashes map[*vectorNode[T]]store.Hash) (store.Hash, error) {
+	if node.lazy != nil && node.lazy.loader == s {
+		return s.refs.Hash(node.lazy.ref), nil
+	}
+	if h, ok := hashes[node]; ok {
+		return h, nil
+	}
+	if h, ok := s.hashes[node]; ok {
+		hashes[node] = h
+		return h, nil
+	}
+
+	var obj store.Object
+	if level == 0 {
+		values, err := store.EncodeValues(node.resolve().values[:])
+		if err != nil {
+			return store.Hash{}, err
+		}
+		obj.Data = append([]byte{objectLeaf}, values...)
+	} else {
+		obj.Data = []byte{objectInternal}
+		for _, child := range node.resolve().children {
+			if child == nil {
+				break
+			}
+			h, err := s.saveNode(child, level-shiftStep, hashes)
+			if err != nil {
+				return store.Hash{}, err
+			}
+			obj.Links = append(obj.Links, h)
+		}
+	}
+
+	h, err := s.store.Put(obj)
+	if err != nil {
+		return store.Hash{}, err
+	}
+	hashes[node] = h
+	return h, nil
+}
+
+// Load читает версию по хэшу корня. Читается только объект версии: узлы дерева подгружаются
+// при первом обращении, а узлы, уже прочитанные этим VectorStore, разделяются между версиями.
+func (s *VectorStore[T]) Load(root store.Hash) (*Vector[T], error) {
+	obj, err := s.store.Get(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(obj.Data) == 0 || obj.Data[0] != objectVersion || len(obj.Links) > 1 {
+		return nil, fmt.Errorf("%w: %s is not a vector version", store.ErrCorrupt, root)
+	}
+
+	data := obj.Data[1:]
+	length, n := binary.Uvarint(data)
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: %s bad length", store.ErrCorrupt, root)
+	}
+	data = data[n:]
+	shift, n := binary.Uvarint(data)
+	if n <= 0 || shift < shiftStep || shift%shiftStep != 0 {
+		return nil, fmt.Errorf("%w: %s bad shift", store.ErrCorrupt, root)
+	}
+	tail, err := store.DecodeValues[T](data[n:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", store.ErrCorrupt, root, err)
+	}
+
+	v := &Vector[T]{
+		tail:   make([]T, len(tail), nodeWidth),
+		len:    int(length),
+		shift:  uint(shift),
+		loader: s,
+	}
+	copy(v.tail, tail)
+	if len(obj.Links) == 1 {
+		v.root = s.stub(obj.Links[0])
+	}
+
+	if v.len-v.tailOffset() != len(v.tail) || (v.root == nil) != (v.tailOffset() == 0) {
+		return nil, fmt.Errorf("%w: %s length does not match tree", store.ErrCorrupt, root)
+	}
+	return v, nil
+}
+
+func (s *VectorStore[T]) stub(h store.Hash) *vectorNode[T] {
+	return &vectorNode[T]{lazy: &lazyNode[T]{loader: s, ref: s.refs.Ref(h)}}
+}
+
+func (s *VectorStore[T]) loadNode(ref uint64) *vectorNode[T] {
+	if node, ok := s.cache.Get(ref); ok {
+		return node
+	}
+
+	h := s.refs.Hash(ref)
+	obj, err := s.store.Get(h)
+	if err != nil {
+		panic(&store.NodeError{Err: fmt.Errorf("array: load node %s: %w", h, err)})
+	}
+
+	node := &vectorNode[T]{}
+	switch {
+	case len(obj.Data) > 0 && obj.Data[0] == objectLeaf && len(obj.Links) == 0:
+		values, err := store.DecodeValues[T](obj.Data[1:])
+		if err != nil || len(values) != nodeWidth {
+			panic(&store.NodeError{Err: fmt.Errorf("array: load node %s: %w: bad leaf", h, store.ErrCorrupt)})
+		}
+		copy(node.values[:], values)
+
+	case len(obj.Data) > 0 && obj.Data[0] == objectInternal && len(obj.Links) <= nodeWidth:
+		for i, link := range obj.Links {
+			node.children[i] = s.stub(link)
+		}
+
+	default:
+		panic(&store.NodeError{Err: fmt.Errorf("array: load node %s: %w: unexpected object", h, store.ErrCorrupt)})
+	}
+
+	s.cache.Put(ref, node)
+	return node
+}
+
+// spillNode оставляет новые узлы в памяти: в хранилище их записывает следующий Save
+func (s *VectorStore[T]) spillNode(node *vectorNode[T], _ uint) *vectorNode[T] {
+	return node
+}
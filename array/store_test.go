@@ -0,0 +1,162 @@
+package array
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ykhdr/persistent-data-structures/store"
+)
+
+func TestVectorStore_SaveLoad(t *testing.T) {
+	t.Run("сохранение и загрузка версий", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+
+		for _, size := range []int{0, 1, 32, 33, 1057, 5000} {
+			v := buildVector(size)
+
+			root, err := NewVectorStore[int](s).Save(v)
+			require.NoError(t, err)
+
+			loaded, err := NewVectorStore[int](s).Load(root)
+			require.NoError(t, err)
+			requireVectorEqual(t, v, loaded)
+		}
+	})
+
+	t.Run("неизменённые поддеревья записываются один раз", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+		vs := NewVectorStore[int](s)
+
+		base := buildVector(10000)
+		_, err = vs.Save(base)
+		require.NoError(t, err)
+
+		objectsBefore := 0
+		v1 := base.Set(5000, -1)
+		root1, err := vs.Save(v1)
+		require.NoError(t, err)
+		_, err = s.GC(root1)
+		require.NoError(t, err)
+		objectsBefore = countStoreObjects(t, s, root1)
+
+		// изменение одного элемента добавляет лист, путь до него и объект версии
+		v2 := v1.Set(7000, -2)
+		root2, err := vs.Save(v2)
+		require.NoError(t, err)
+		removed, err := s.GC(root1, root2)
+		require.NoError(t, err)
+		assert.Equal(t, 0, removed)
+
+		shared := countStoreObjects(t, s, root1, root2)
+		assert.Equal(t, objectsBefore+int(v2.shift/shiftStep)+2, shared)
+	})
+
+	t.Run("одинаковое содержимое даёт одинаковый хэш", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+
+		root1, err := NewVectorStore[int](s).Save(buildVector(3000))
+		require.NoError(t, err)
+		root2, err := NewVectorStore[int](s).Save(buildVector(3000))
+		require.NoError(t, err)
+
+		assert.Equal(t, root1, root2)
+	})
+
+	t.Run("загруженные версии разделяют узлы", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+		vs := NewVectorStore[int](s)
+
+		base := buildVector(5000)
+		root1, err := vs.Save(base)
+		require.NoError(t, err)
+		root2, err := vs.Save(base.Set(10, -1))
+		require.NoError(t, err)
+
+		loader := NewVectorStore[int](s)
+		loaded1, err := loader.Load(root1)
+		require.NoError(t, err)
+		loaded2, err := loader.Load(root2)
+		require.NoError(t, err)
+
+		assert.Same(t, loaded1.getLeaf(3000), loaded2.getLeaf(3000))
+		val, _ := loaded2.Get(10)
+		assert.Equal(t, -1, val)
+	})
+
+	t.Run("узлы читаются при первом обращении", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+
+		base := buildVector(5000)
+		root, err := NewVectorStore[int](s).Save(base)
+		require.NoError(t, err)
+
+		vs := NewVectorStore[int](s)
+		loaded, err := vs.Load(root)
+		require.NoError(t, err)
+		assert.Equal(t, 0, vs.cache.Len(), "Load читает только объект версии")
+
+		val, ok := loaded.Get(1234)
+		require.True(t, ok)
+		assert.Equal(t, 1234, val)
+		assert.Equal(t, int(loaded.shift/shiftStep)+1, vs.cache.Len(), "читается только путь до листа")
+
+		requireVectorEqual(t, base, loaded)
+	})
+
+	t.Run("Save загруженной версии пишет только изменённый путь", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+
+		root1, err := NewVectorStore[int](s).Save(buildVector(5000))
+		require.NoError(t, err)
+
+		vs := NewVectorStore[int](s)
+		loaded, err := vs.Load(root1)
+		require.NoError(t, err)
+		changed := loaded.Set(100, -1)
+		root2, err := vs.Save(changed)
+		require.NoError(t, err)
+
+		assert.Equal(t, int(changed.shift/shiftStep)+1, vs.cache.Len(), "Save не читает неизменённые поддеревья")
+		assert.Equal(t, countStoreObjects(t, s, root1)+int(changed.shift/shiftStep)+2, countStoreObjects(t, s, root1, root2))
+
+		reloaded, err := NewVectorStore[int](s).Load(root2)
+		require.NoError(t, err)
+		requireVectorEqual(t, changed, reloaded)
+	})
+
+	t.Run("чужой объект вместо версии", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+
+		h, err := s.Put(store.Object{Data: []byte{0xff}})
+		require.NoError(t, err)
+
+		_, err = NewVectorStore[int](s).Load(h)
+		assert.ErrorIs(t, err, store.ErrCorrupt)
+	})
+}
+
+func countStoreObjects(t *testing.T, s *store.Store, roots ...store.Hash) int {
+	t.Helper()
+	seen := make(map[store.Hash]bool)
+	stack := roots
+	for len(stack) > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		obj, err := s.Get(h)
+		require.NoError(t, err)
+		stack = append(stack, obj.Links...)
+	}
+	return len(seen)
+}
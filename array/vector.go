@@ -1,6 +1,6 @@
 package array
 
-import "iter"
+import pds "github.com/ykhdr/persistent-data-structures"
 
 const (
 	shiftStep = 5  // бит на уровень
@@ -11,6 +11,31 @@ const (
 type vectorNode[T any] struct {
 	children [nodeWidth]*vectorNode[T] // значения для внутренних узлов
 	values   [nodeWidth]T              // значения в листовом узле
+	lazy     *lazyNode[T]              // не nil у заглушки узла, который ещё не загружен с диска
+}
+
+// lazyNode - ссылка на узел, лежащий вне памяти
+type lazyNode[T any] struct {
+	loader nodeLoader[T]
+	ref    uint64
+}
+
+// nodeLoader - источник узлов, которых нет в памяти. У вектора в памяти loader равен nil,
+// и все узлы дерева резидентны.
+type nodeLoader[T any] interface {
+	// loadNode возвращает узел по ссылке; дети загруженного узла - снова заглушки
+	loadNode(ref uint64) *vectorNode[T]
+	// spillNode записывает новый узел уровня level вместе с его новыми потомками и возвращает заглушку.
+	// Loader, который записывает узлы только по явному Save (VectorStore), возвращает сам узел.
+	spillNode(node *vectorNode[T], level uint) *vectorNode[T]
+}
+
+// resolve возвращает резидентный узел: для заглушки он подгружается через loader
+func (n *vectorNode[T]) resolve() *vectorNode[T] {
+	if n.lazy == nil {
+		return n
+	}
+	return n.lazy.loader.loadNode(n.lazy.ref)
 }
 
 func (n *vectorNode[T]) cloneInternal() *vectorNode[T] {
@@ -30,8 +55,12 @@ type Vector[T any] struct {
 	tail  []T            // буфер последних элементов (оптимизация)
 	len   int            // количество элементов в структуре
 	shift uint           // глубина дерева x 5 - нужна для побитового сдвига
+	// loader не nil у вектора, узлы которого хранятся на диске (см. VectorFile и VectorStore)
+	loader nodeLoader[T]
 }
 
+var _ pds.Indexed[int, *Vector[int]] = (*Vector[int])(nil)
+
 func NewVector[T any]() *Vector[T] {
 	return &Vector[T]{
 		root:  nil,
@@ -41,7 +70,14 @@ func NewVector[T any]() *Vector[T] {
 	}
 }
 
-func (v *Vector[T]) sealed() {}
+// spill сбрасывает новые узлы версии next на диск, если вектор хранится на диске
+func (v *Vector[T]) spill(next *Vector[T]) *Vector[T] {
+	next.loader = v.loader
+	if v.loader != nil && next.root != nil {
+		next.root = v.loader.spillNode(next.root, next.shift)
+	}
+	return next
+}
 
 func (v *Vector[T]) Len() int {
 	return v.len
@@ -55,11 +91,11 @@ func (v *Vector[T]) tailOffset() int {
 }
 
 func (v *Vector[T]) getLeaf(index int) *vectorNode[T] {
-	node := v.root
+	node := v.root.resolve()
 	for level := v.shift; level > shiftStep; level -= shiftStep {
-		node = node.children[(index>>level)&indexMask]
+		node = node.children[(index>>level)&indexMask].resolve()
 	}
-	return node.children[(index>>shiftStep)&indexMask]
+	return node.children[(index>>shiftStep)&indexMask].resolve()
 }
 
 func (v *Vector[T]) Get(index int) (T, bool) {
@@ -85,27 +121,28 @@ func (v *Vector[T]) Set(index int, value T) *Vector[T] {
 		newTail := make([]T, len(v.tail))
 		copy(newTail, v.tail)
 		newTail[index-v.tailOffset()] = value
-		return &Vector[T]{
+		return v.spill(&Vector[T]{
 			root:  v.root,
 			tail:  newTail,
 			len:   v.len,
 			shift: v.shift,
-		}
+		})
 	}
 
-	return &Vector[T]{
+	return v.spill(&Vector[T]{
 		root:  v.setInNode(v.root, v.shift, index, value),
 		tail:  v.tail,
 		len:   v.len,
 		shift: v.shift,
-	}
+	})
 }
 
 func (v *Vector[T]) setInNode(node *vectorNode[T], level uint, index int, value T) *vectorNode[T] {
+	node = node.resolve()
 	if level == shiftStep {
 		newNode := node.cloneInternal()
 		childIndex := (index >> shiftStep) & indexMask
-		leaf := node.children[childIndex].cloneLeaf()
+		leaf := node.children[childIndex].resolve().cloneLeaf()
 		leaf.values[index&indexMask] = value
 		newNode.children[childIndex] = leaf
 		return newNode
@@ -122,12 +159,12 @@ func (v *Vector[T]) Append(value T) *Vector[T] {
 		newTail := make([]T, len(v.tail)+1)
 		copy(newTail, v.tail)
 		newTail[len(v.tail)] = value
-		return &Vector[T]{
+		return v.spill(&Vector[T]{
 			root:  v.root,
 			tail:  newTail,
 			len:   v.len + 1,
 			shift: v.shift,
-		}
+		})
 	}
 
 	tailNode := &vectorNode[T]{}
@@ -148,12 +185,12 @@ func (v *Vector[T]) Append(value T) *Vector[T] {
 		newRoot = v.pushTail(v.shift, v.root, tailNode)
 	}
 
-	return &Vector[T]{
+	return v.spill(&Vector[T]{
 		root:  newRoot,
 		tail:  []T{value},
 		len:   v.len + 1,
 		shift: newShift,
-	}
+	})
 }
 
 func (v *Vector[T]) newPath(level uint, leaf *vectorNode[T]) *vectorNode[T] {
@@ -169,6 +206,7 @@ func (v *Vector[T]) newPath(level uint, leaf *vectorNode[T]) *vectorNode[T] {
 
 func (v *Vector[T]) pushTail(level uint, parent *vectorNode[T], tailNode *vectorNode[T]) *vectorNode[T] {
 	subIndex := ((v.len - 1) >> level) & indexMask
+	parent = parent.resolve()
 	newNode := parent.cloneInternal()
 
 	if level == shiftStep {
@@ -193,19 +231,19 @@ func (v *Vector[T]) Pop() (*Vector[T], T, bool) {
 
 	if v.len == 1 {
 		value := v.tail[0]
-		return NewVector[T](), value, true
+		return v.spill(NewVector[T]()), value, true
 	}
 
 	if len(v.tail) > 1 {
 		newTail := make([]T, len(v.tail)-1)
 		copy(newTail, v.tail[:len(v.tail)-1])
 		value := v.tail[len(v.tail)-1]
-		return &Vector[T]{
+		return v.spill(&Vector[T]{
 			root:  v.root,
 			tail:  newTail,
 			len:   v.len - 1,
 			shift: v.shift,
-		}, value, true
+		}), value, true
 	}
 
 	value := v.tail[0]
@@ -220,12 +258,12 @@ func (v *Vector[T]) Pop() (*Vector[T], T, bool) {
 		newShift -= shiftStep
 	}
 
-	return &Vector[T]{
+	return v.spill(&Vector[T]{
 		root:  newRoot,
 		tail:  newTail,
 		len:   v.len - 1,
 		shift: newShift,
-	}, value, true
+	}), value, true
 }
 
 func (v *Vector[T]) leafValuesToSlice(index int) []T {
@@ -237,6 +275,7 @@ func (v *Vector[T]) leafValuesToSlice(index int) []T {
 
 func (v *Vector[T]) popTail(level uint, node *vectorNode[T]) *vectorNode[T] {
 	subIndex := ((v.len - 2) >> level) & indexMask
+	node = node.resolve()
 
 	if level > shiftStep {
 		newChild := v.popTail(level-shiftStep, node.children[subIndex])
@@ -256,25 +295,3 @@ func (v *Vector[T]) popTail(level uint, node *vectorNode[T]) *vectorNode[T] {
 	newNode.children[subIndex] = nil
 	return newNode
 }
-
-func (v *Vector[T]) All() iter.Seq2[int, T] {
-	return func(yield func(int, T) bool) {
-		for i := 0; i < v.len; i++ {
-			value, _ := v.Get(i)
-			if !yield(i, value) {
-				return
-			}
-		}
-	}
-}
-
-func (v *Vector[T]) Values() iter.Seq[T] {
-	return func(yield func(T) bool) {
-		for i := 0; i < v.len; i++ {
-			value, _ := v.Get(i)
-			if !yield(value) {
-				return
-			}
-		}
-	}
-}
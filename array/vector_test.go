@@ -9,7 +9,7 @@ import (
 )
 
 func TestVector_NewVector(t *testing.T) {
-	v := NewVector[int]()
+	v := newTestVector[int](t)
 
 	assert.NotNil(t, v)
 	assert.Equal(t, 0, v.Len(), "новый вектор должен быть пустым")
@@ -17,7 +17,7 @@ func TestVector_NewVector(t *testing.T) {
 
 func TestVector_Append(t *testing.T) {
 	t.Run("добавление одного элемента", func(t *testing.T) {
-		v := NewVector[int]().Append(42)
+		v := newTestVector[int](t).Append(42)
 
 		assert.Equal(t, 1, v.Len())
 		val, ok := v.Get(0)
@@ -26,7 +26,7 @@ func TestVector_Append(t *testing.T) {
 	})
 
 	t.Run("добавление множества элементов", func(t *testing.T) {
-		v := NewVector[int]()
+		v := newTestVector[int](t)
 		for i := 0; i < 100; i++ {
 			v = v.Append(i)
 		}
@@ -40,7 +40,7 @@ func TestVector_Append(t *testing.T) {
 	})
 
 	t.Run("цепочка вызовов", func(t *testing.T) {
-		v := NewVector[string]().
+		v := newTestVector[string](t).
 			Append("a").
 			Append("b").
 			Append("c")
@@ -59,7 +59,7 @@ func TestVector_Append(t *testing.T) {
 }
 
 func TestVector_Get(t *testing.T) {
-	v := NewVector[int]().Append(10).Append(20).Append(30)
+	v := newTestVector[int](t).Append(10).Append(20).Append(30)
 
 	t.Run("корректные индексы", func(t *testing.T) {
 		val, ok := v.Get(0)
@@ -89,14 +89,14 @@ func TestVector_Get(t *testing.T) {
 	})
 
 	t.Run("пустой вектор", func(t *testing.T) {
-		empty := NewVector[int]()
+		empty := newTestVector[int](t)
 		_, ok := empty.Get(0)
 		assert.False(t, ok, "Get на пустом векторе должен вернуть false")
 	})
 }
 
 func TestVector_Set(t *testing.T) {
-	v := NewVector[int]().Append(1).Append(2).Append(3)
+	v := newTestVector[int](t).Append(1).Append(2).Append(3)
 
 	t.Run("корректное изменение", func(t *testing.T) {
 		v2 := v.Set(1, 100)
@@ -133,7 +133,7 @@ func TestVector_Set(t *testing.T) {
 
 func TestVector_Pop(t *testing.T) {
 	t.Run("удаление из непустого вектора", func(t *testing.T) {
-		v := NewVector[int]().Append(1).Append(2).Append(3)
+		v := newTestVector[int](t).Append(1).Append(2).Append(3)
 
 		v2, val, ok := v.Pop()
 
@@ -144,7 +144,7 @@ func TestVector_Pop(t *testing.T) {
 	})
 
 	t.Run("удаление всех элементов", func(t *testing.T) {
-		v := NewVector[int]().Append(1).Append(2)
+		v := newTestVector[int](t).Append(1).Append(2)
 
 		v, val, ok := v.Pop()
 		require.True(t, ok)
@@ -158,7 +158,7 @@ func TestVector_Pop(t *testing.T) {
 	})
 
 	t.Run("удаление из пустого вектора", func(t *testing.T) {
-		v := NewVector[int]()
+		v := newTestVector[int](t)
 
 		v2, _, ok := v.Pop()
 
@@ -167,7 +167,7 @@ func TestVector_Pop(t *testing.T) {
 	})
 
 	t.Run("удаление единственного элемента", func(t *testing.T) {
-		v := NewVector[int]().Append(42)
+		v := newTestVector[int](t).Append(42)
 
 		v2, val, ok := v.Pop()
 
@@ -179,7 +179,7 @@ func TestVector_Pop(t *testing.T) {
 
 func TestVector_Persistence(t *testing.T) {
 	t.Run("Append создаёт новую копию", func(t *testing.T) {
-		v1 := NewVector[int]()
+		v1 := newTestVector[int](t)
 		v2 := v1.Append(1)
 		v3 := v2.Append(2)
 
@@ -189,7 +189,7 @@ func TestVector_Persistence(t *testing.T) {
 	})
 
 	t.Run("Set создаёт новую копию", func(t *testing.T) {
-		v1 := NewVector[int]().Append(1).Append(2).Append(3)
+		v1 := newTestVector[int](t).Append(1).Append(2).Append(3)
 		v2 := v1.Set(1, 100)
 
 		val1, _ := v1.Get(1)
@@ -200,7 +200,7 @@ func TestVector_Persistence(t *testing.T) {
 	})
 
 	t.Run("Pop создаёт новую копию", func(t *testing.T) {
-		v1 := NewVector[int]().Append(1).Append(2)
+		v1 := newTestVector[int](t).Append(1).Append(2)
 		v2, _, _ := v1.Pop()
 
 		assert.Equal(t, 2, v1.Len(), "оригинал не должен измениться")
@@ -208,7 +208,7 @@ func TestVector_Persistence(t *testing.T) {
 	})
 
 	t.Run("ветвление версий", func(t *testing.T) {
-		base := NewVector[int]().Append(1).Append(2)
+		base := newTestVector[int](t).Append(1).Append(2)
 
 		branch1 := base.Append(3)
 		branch2 := base.Append(100)
@@ -229,7 +229,7 @@ func TestVector_LargeDataset(t *testing.T) {
 
 	for _, size := range sizes {
 		t.Run(fmt.Sprintf("size_%d", size), func(t *testing.T) {
-			v := NewVector[int]()
+			v := newTestVector[int](t)
 
 			for i := 0; i < size; i++ {
 				v = v.Append(i)
@@ -248,7 +248,7 @@ func TestVector_LargeDataset(t *testing.T) {
 
 func TestVector_Iterator(t *testing.T) {
 	t.Run("итератор All", func(t *testing.T) {
-		v := NewVector[int]().Append(10).Append(20).Append(30)
+		v := newTestVector[int](t).Append(10).Append(20).Append(30)
 
 		var indices []int
 		var values []int
@@ -263,7 +263,7 @@ func TestVector_Iterator(t *testing.T) {
 	})
 
 	t.Run("итератор Values", func(t *testing.T) {
-		v := NewVector[int]().Append(1).Append(2).Append(3)
+		v := newTestVector[int](t).Append(1).Append(2).Append(3)
 
 		sum := 0
 		for val := range v.Values() {
@@ -274,7 +274,7 @@ func TestVector_Iterator(t *testing.T) {
 	})
 
 	t.Run("итерация пустого вектора", func(t *testing.T) {
-		v := NewVector[int]()
+		v := newTestVector[int](t)
 
 		count := 0
 		for range v.All() {
@@ -285,7 +285,7 @@ func TestVector_Iterator(t *testing.T) {
 	})
 
 	t.Run("ранний выход из итератора", func(t *testing.T) {
-		v := NewVector[int]().Append(1).Append(2).Append(3).Append(4).Append(5)
+		v := newTestVector[int](t).Append(1).Append(2).Append(3).Append(4).Append(5)
 
 		count := 0
 		for _, val := range v.All() {
@@ -301,10 +301,10 @@ func TestVector_Iterator(t *testing.T) {
 
 func TestVector_NestedStructures(t *testing.T) {
 	t.Run("вектор векторов", func(t *testing.T) {
-		inner1 := NewVector[int]().Append(1).Append(2)
-		inner2 := NewVector[int]().Append(3).Append(4)
+		inner1 := newTestVector[int](t).Append(1).Append(2)
+		inner2 := newTestVector[int](t).Append(3).Append(4)
 
-		outer := NewVector[*Vector[int]]().Append(inner1).Append(inner2)
+		outer := newTestVector[*Vector[int]](t).Append(inner1).Append(inner2)
 
 		assert.Equal(t, 2, outer.Len())
 
@@ -322,7 +322,7 @@ func TestVector_NestedStructures(t *testing.T) {
 			Age  int
 		}
 
-		v := NewVector[Person]().
+		v := newTestVector[Person](t).
 			Append(Person{"Alice", 30}).
 			Append(Person{"Bob", 25})
 
@@ -335,7 +335,7 @@ func TestVector_NestedStructures(t *testing.T) {
 
 func TestVector_EdgeCases(t *testing.T) {
 	t.Run("граница tail (32 элемента)", func(t *testing.T) {
-		v := NewVector[int]()
+		v := newTestVector[int](t)
 
 		for i := 0; i < 32; i++ {
 			v = v.Append(i)
@@ -351,7 +351,7 @@ func TestVector_EdgeCases(t *testing.T) {
 	})
 
 	t.Run("несколько сбросов tail в дерево", func(t *testing.T) {
-		v := NewVector[int]()
+		v := newTestVector[int](t)
 
 		for i := 0; i < 100; i++ {
 			v = v.Append(i)
@@ -365,7 +365,7 @@ func TestVector_EdgeCases(t *testing.T) {
 	})
 
 	t.Run("Set в дереве vs в tail", func(t *testing.T) {
-		v := NewVector[int]()
+		v := newTestVector[int](t)
 		for i := 0; i < 50; i++ {
 			v = v.Append(i)
 		}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// mutexLRU - классический изменяемый LRU под мьютексом для сравнения
+type mutexLRU struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[int]*list.Element
+	order    *list.List
+}
+
+type mutexEntry struct {
+	key   int
+	value int
+}
+
+func newMutexLRU(capacity int) *mutexLRU {
+	return &mutexLRU{
+		capacity: capacity,
+		items:    make(map[int]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (c *mutexLRU) Get(key int) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return 0, false
+	}
+	c.order.MoveToBack(el)
+	return el.Value.(*mutexEntry).value, true
+}
+
+func (c *mutexLRU) Set(key, value int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*mutexEntry).value = value
+		c.order.MoveToBack(el)
+		return
+	}
+	c.items[key] = c.order.PushBack(&mutexEntry{key: key, value: value})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*mutexEntry).key)
+	}
+}
+
+func pregenKeys(size int, n int) []int {
+	r := rand.New(rand.NewSource(1))
+	keys := make([]int, n)
+	for i := 0; i < n; i++ {
+		keys[i] = r.Intn(size)
+	}
+	return keys
+}
+
+func buildLRU(size int) *LRU[int, int] {
+	c := NewLRU[int, int](size)
+	for i := 0; i < size; i++ {
+		c = c.Set(i, i)
+	}
+	return c
+}
+
+func buildMutexLRU(size int) *mutexLRU {
+	c := newMutexLRU(size)
+	for i := 0; i < size; i++ {
+		c.Set(i, i)
+	}
+	return c
+}
+
+func BenchmarkSet(b *testing.B) {
+	sizes := []int{100, 1000, 10000}
+
+	for _, size := range sizes {
+		// ключи из вдвое большего диапазона, чтобы половина Set вызывала вытеснение
+		keys := pregenKeys(size*2, 1<<16)
+
+		b.Run(fmt.Sprintf("LRU/size_%d", size), func(b *testing.B) {
+			c := buildLRU(size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c = c.Set(keys[i&(len(keys)-1)], i)
+			}
+		})
+
+		b.Run(fmt.Sprintf("MutexLRU/size_%d", size), func(b *testing.B) {
+			c := buildMutexLRU(size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c.Set(keys[i&(len(keys)-1)], i)
+			}
+		})
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	sizes := []int{100, 1000, 10000}
+
+	for _, size := range sizes {
+		keys := pregenKeys(size, 1<<16)
+
+		b.Run(fmt.Sprintf("LRU/size_%d/touch", size), func(b *testing.B) {
+			c := buildLRU(size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				c, _, _ = c.Get(keys[i&(len(keys)-1)])
+			}
+		})
+
+		b.Run(fmt.Sprintf("LRU/size_%d/peek", size), func(b *testing.B) {
+			c := buildLRU(size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = c.Peek(keys[i&(len(keys)-1)])
+			}
+		})
+
+		b.Run(fmt.Sprintf("MutexLRU/size_%d", size), func(b *testing.B) {
+			c := buildMutexLRU(size)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = c.Get(keys[i&(len(keys)-1)])
+			}
+		})
+	}
+}
+
+// Параллельные читатели: снимок LRU читается без блокировок, MutexLRU берёт мьютекс на каждый Get
+func BenchmarkParallelRead(b *testing.B) {
+	size := 10000
+	keys := pregenKeys(size, 1<<16)
+
+	b.Run("LRU/snapshot", func(b *testing.B) {
+		snapshot := buildLRU(size)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				_, _ = snapshot.Peek(keys[i&(len(keys)-1)])
+				i++
+			}
+		})
+	})
+
+	b.Run("MutexLRU", func(b *testing.B) {
+		c := buildMutexLRU(size)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 0
+			for pb.Next() {
+				_, _ = c.Get(keys[i&(len(keys)-1)])
+				i++
+			}
+		})
+	})
+}
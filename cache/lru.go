@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"iter"
+	"time"
+
+	"github.com/ykhdr/persistent-data-structures/hashmap"
+)
+
+type lruEntry[V any] struct {
+	value   V
+	tick    int
+	expires time.Time // нулевое значение - без срока жизни
+}
+
+type Option[K comparable, V any] func(*LRU[K, V])
+
+// WithTTL включает режим со сроком жизни: запись считается отсутствующей через ttl после Set
+func WithTTL[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(c *LRU[K, V]) {
+		c.ttl = ttl
+	}
+}
+
+// WithEvictCallback задаёт функцию, которая вызывается для каждой вытесненной или истёкшей записи.
+// Она вызывается только из операций писателя - Set и RemoveExpired, но не из Get, который вызывают читатели снимков.
+func WithEvictCallback[K comparable, V any](onEvict func(key K, value V)) Option[K, V] {
+	return func(c *LRU[K, V]) {
+		c.onEvict = onEvict
+	}
+}
+
+// WithClock подменяет источник времени для TTL
+func WithClock[K comparable, V any](now func() time.Time) Option[K, V] {
+	return func(c *LRU[K, V]) {
+		c.now = now
+	}
+}
+
+// LRU - персистентный кэш с вытеснением давно неиспользуемых записей.
+// Каждая операция возвращает новую версию, поэтому снимок можно отдать читателям,
+// пока писатель продолжает работать с новыми версиями.
+type LRU[K comparable, V any] struct {
+	entries  *hashmap.HashMap[K, lruEntry[V]]
+	order    *hashmap.IntMap[K] // tick -> key, от давних к свежим
+	tick     int
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+	onEvict  func(key K, value V)
+}
+
+func NewLRU[K comparable, V any](capacity int, opts ...Option[K, V]) *LRU[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	c := &LRU[K, V]{
+		entries:  hashmap.NewHashMap[K, lruEntry[V]](),
+		order:    hashmap.NewIntMap[K](),
+		tick:     0,
+		capacity: capacity,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *LRU[K, V]) Len() int {
+	return c.entries.Len()
+}
+
+func (c *LRU[K, V]) Cap() int {
+	return c.capacity
+}
+
+func (c *LRU[K, V]) with(entries *hashmap.HashMap[K, lruEntry[V]], order *hashmap.IntMap[K], tick int) *LRU[K, V] {
+	return &LRU[K, V]{
+		entries:  entries,
+		order:    order,
+		tick:     tick,
+		capacity: c.capacity,
+		ttl:      c.ttl,
+		now:      c.now,
+		onEvict:  c.onEvict,
+	}
+}
+
+func (c *LRU[K, V]) expired(e lruEntry[V]) bool {
+	return !e.expires.IsZero() && !c.now().Before(e.expires)
+}
+
+func (c *LRU[K, V]) evict(key K, value V) {
+	if c.onEvict != nil {
+		c.onEvict(key, value)
+	}
+}
+
+// Peek возвращает значение без изменения порядка вытеснения
+func (c *LRU[K, V]) Peek(key K) (V, bool) {
+	var zero V
+	e, ok := c.entries.Get(key)
+	if !ok || c.expired(e) {
+		return zero, false
+	}
+	return e.value, true
+}
+
+func (c *LRU[K, V]) Contains(key K) bool {
+	_, ok := c.Peek(key)
+	return ok
+}
+
+// Get возвращает значение и новую версию кэша, в которой ключ отмечен как самый свежий.
+// Истёкшая запись считается отсутствующей, но не удаляется и callback вытеснения не вызывает:
+// один снимок читают многие, и callback срабатывал бы у каждого. Её удаляют Set при переполнении
+// и RemoveExpired.
+func (c *LRU[K, V]) Get(key K) (*LRU[K, V], V, bool) {
+	var zero V
+	e, ok := c.entries.Get(key)
+	if !ok || c.expired(e) {
+		return c, zero, false
+	}
+
+	if e.tick == c.tick-1 {
+		// ключ и так самый свежий
+		return c, e.value, true
+	}
+
+	newEntry := lruEntry[V]{value: e.value, tick: c.tick, expires: e.expires}
+	return c.with(
+		c.entries.Set(key, newEntry),
+		c.order.Delete(e.tick).Set(c.tick, key),
+		c.tick+1,
+	), e.value, true
+}
+
+func (c *LRU[K, V]) Set(key K, value V) *LRU[K, V] {
+	entries := c.entries
+	order := c.order
+
+	if e, ok := entries.Get(key); ok {
+		order = order.Delete(e.tick)
+	}
+
+	newEntry := lruEntry[V]{value: value, tick: c.tick}
+	if c.ttl > 0 {
+		newEntry.expires = c.now().Add(c.ttl)
+	}
+	entries = entries.Set(key, newEntry)
+	order = order.Set(c.tick, key)
+
+	for entries.Len() > c.capacity {
+		tick, oldest, _ := order.Min()
+		e, _ := entries.Get(oldest)
+		entries = entries.Delete(oldest)
+		order = order.Delete(tick)
+		c.evict(oldest, e.value)
+	}
+
+	return c.with(entries, order, c.tick+1)
+}
+
+func (c *LRU[K, V]) Delete(key K) *LRU[K, V] {
+	e, ok := c.entries.Get(key)
+	if !ok {
+		return c
+	}
+	return c.with(c.entries.Delete(key), c.order.Delete(e.tick), c.tick)
+}
+
+// RemoveExpired удаляет все истёкшие записи, вызывая для них callback вытеснения
+func (c *LRU[K, V]) RemoveExpired() *LRU[K, V] {
+	if c.ttl <= 0 {
+		return c
+	}
+
+	entries := c.entries
+	order := c.order
+	for tick, key := range c.order.All() {
+		e, _ := c.entries.Get(key)
+		if !c.expired(e) {
+			continue
+		}
+		entries = entries.Delete(key)
+		order = order.Delete(tick)
+		c.evict(key, e.value)
+	}
+
+	if entries == c.entries {
+		return c
+	}
+	return c.with(entries, order, c.tick)
+}
+
+// All перебирает актуальные записи от давно использованных к недавним
+func (c *LRU[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, key := range c.order.All() {
+			e, _ := c.entries.Get(key)
+			if c.expired(e) {
+				continue
+			}
+			if !yield(key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+func (c *LRU[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range c.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lruKeys[K comparable, V any](c *LRU[K, V]) []K {
+	var keys []K
+	for k := range c.Keys() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestLRU_SetAndPeek(t *testing.T) {
+	t.Run("добавление и чтение", func(t *testing.T) {
+		c := NewLRU[string, int](3).Set("a", 1).Set("b", 2)
+
+		assert.Equal(t, 2, c.Len())
+		assert.Equal(t, 3, c.Cap())
+
+		val, ok := c.Peek("a")
+		require.True(t, ok)
+		assert.Equal(t, 1, val)
+
+		_, ok = c.Peek("missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("Set создаёт новую версию", func(t *testing.T) {
+		c1 := NewLRU[string, int](3).Set("a", 1)
+		c2 := c1.Set("a", 100)
+
+		val, _ := c1.Peek("a")
+		assert.Equal(t, 1, val, "оригинал не должен измениться")
+		val, _ = c2.Peek("a")
+		assert.Equal(t, 100, val)
+		assert.Equal(t, 1, c2.Len())
+	})
+}
+
+func TestLRU_Eviction(t *testing.T) {
+	t.Run("вытесняется давно неиспользуемый ключ", func(t *testing.T) {
+		var evicted []string
+		c := NewLRU[string, int](2, WithEvictCallback(func(k string, v int) {
+			evicted = append(evicted, k)
+		}))
+
+		c = c.Set("a", 1).Set("b", 2).Set("c", 3)
+
+		assert.Equal(t, 2, c.Len())
+		assert.False(t, c.Contains("a"))
+		assert.Equal(t, []string{"a"}, evicted)
+	})
+
+	t.Run("Get отмечает ключ как свежий", func(t *testing.T) {
+		c := NewLRU[string, int](2).Set("a", 1).Set("b", 2)
+
+		c, val, ok := c.Get("a")
+		require.True(t, ok)
+		assert.Equal(t, 1, val)
+
+		c = c.Set("c", 3)
+
+		assert.True(t, c.Contains("a"), "после Get ключ 'a' не должен вытесняться")
+		assert.False(t, c.Contains("b"))
+		assert.Equal(t, []string{"a", "c"}, lruKeys(c))
+	})
+
+	t.Run("Peek не меняет порядок", func(t *testing.T) {
+		c := NewLRU[string, int](2).Set("a", 1).Set("b", 2)
+
+		c.Peek("a")
+		c = c.Set("c", 3)
+
+		assert.False(t, c.Contains("a"))
+	})
+
+	t.Run("снимок не меняется при работе писателя", func(t *testing.T) {
+		snapshot := NewLRU[int, int](10)
+		for i := 0; i < 10; i++ {
+			snapshot = snapshot.Set(i, i)
+		}
+
+		writer := snapshot
+		for i := 10; i < 20; i++ {
+			writer = writer.Set(i, i)
+		}
+
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, lruKeys(snapshot))
+		assert.Equal(t, []int{10, 11, 12, 13, 14, 15, 16, 17, 18, 19}, lruKeys(writer))
+	})
+}
+
+func TestLRU_Delete(t *testing.T) {
+	c := NewLRU[string, int](3).Set("a", 1).Set("b", 2)
+
+	c2 := c.Delete("a")
+
+	assert.Equal(t, 1, c2.Len())
+	assert.False(t, c2.Contains("a"))
+	assert.True(t, c.Contains("a"), "оригинал не должен измениться")
+	assert.Same(t, c, c.Delete("missing"))
+}
+
+func TestLRU_TTL(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := func() time.Time { return now }
+
+	t.Run("истёкшая запись не возвращается", func(t *testing.T) {
+		var evicted []string
+		c := NewLRU[string, int](10,
+			WithTTL[string, int](time.Minute),
+			WithClock[string, int](clock),
+			WithEvictCallback(func(k string, v int) { evicted = append(evicted, k) }),
+		)
+		c = c.Set("a", 1)
+
+		now = now.Add(30 * time.Second)
+		c = c.Set("b", 2)
+		assert.True(t, c.Contains("a"))
+
+		now = now.Add(31 * time.Second)
+		assert.False(t, c.Contains("a"), "запись 'a' должна истечь")
+		assert.True(t, c.Contains("b"))
+
+		// читатели одного снимка не вызывают callback
+		for i := 0; i < 3; i++ {
+			got, _, ok := c.Get("a")
+			assert.False(t, ok)
+			assert.Same(t, c, got)
+		}
+		assert.Empty(t, evicted)
+
+		c = c.RemoveExpired()
+		assert.Equal(t, 1, c.Len())
+		assert.Equal(t, []string{"a"}, evicted)
+	})
+
+	t.Run("RemoveExpired удаляет все истёкшие записи", func(t *testing.T) {
+		c := NewLRU[int, int](10, WithTTL[int, int](time.Second), WithClock[int, int](clock))
+		for i := 0; i < 5; i++ {
+			c = c.Set(i, i)
+		}
+		now = now.Add(2 * time.Second)
+		c = c.Set(100, 100)
+
+		c2 := c.RemoveExpired()
+
+		assert.Equal(t, 6, c.Len())
+		assert.Equal(t, 1, c2.Len())
+		assert.Equal(t, []int{100}, lruKeys(c2))
+	})
+}
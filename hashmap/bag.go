@@ -0,0 +1,85 @@
+package hashmap
+
+import "iter"
+
+// Bag - мультимножество: для каждого элемента хранится число его вхождений
+type Bag[T comparable] struct {
+	counts *HashMap[T, int]
+	len    int // общее количество элементов с учётом повторов
+}
+
+func NewBag[T comparable]() *Bag[T] {
+	return &Bag[T]{
+		counts: NewHashMap[T, int](),
+		len:    0,
+	}
+}
+
+func (b *Bag[T]) Len() int {
+	return b.len
+}
+
+func (b *Bag[T]) DistinctCount() int {
+	return b.counts.Len()
+}
+
+func (b *Bag[T]) Count(value T) int {
+	count, _ := b.counts.Get(value)
+	return count
+}
+
+func (b *Bag[T]) Contains(value T) bool {
+	return b.counts.Contains(value)
+}
+
+func (b *Bag[T]) Add(value T) *Bag[T] {
+	return b.AddN(value, 1)
+}
+
+func (b *Bag[T]) AddN(value T, n int) *Bag[T] {
+	if n <= 0 {
+		return b
+	}
+
+	count, _ := b.counts.Get(value)
+	return &Bag[T]{
+		counts: b.counts.Set(value, count+n),
+		len:    b.len + n,
+	}
+}
+
+// Remove удаляет одно вхождение элемента
+func (b *Bag[T]) Remove(value T) *Bag[T] {
+	return b.RemoveN(value, 1)
+}
+
+func (b *Bag[T]) RemoveN(value T, n int) *Bag[T] {
+	count, ok := b.counts.Get(value)
+	if !ok || n <= 0 {
+		return b
+	}
+
+	if n >= count {
+		return &Bag[T]{
+			counts: b.counts.Delete(value),
+			len:    b.len - count,
+		}
+	}
+
+	return &Bag[T]{
+		counts: b.counts.Set(value, count-n),
+		len:    b.len - n,
+	}
+}
+
+func (b *Bag[T]) RemoveAll(value T) *Bag[T] {
+	return b.RemoveN(value, b.Count(value))
+}
+
+func (b *Bag[T]) Distinct() iter.Seq[T] {
+	return b.counts.Keys()
+}
+
+func (b *Bag[T]) All() iter.Seq2[T, int] {
+	return b.counts.All()
+}
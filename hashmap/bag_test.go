@@ -0,0 +1,69 @@
+package hashmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBag_AddCount(t *testing.T) {
+	t.Run("подсчёт вхождений", func(t *testing.T) {
+		b := NewBag[string]().Add("a").Add("b").Add("a").AddN("c", 3)
+
+		assert.Equal(t, 6, b.Len(), "общее количество с повторами")
+		assert.Equal(t, 3, b.DistinctCount())
+		assert.Equal(t, 2, b.Count("a"))
+		assert.Equal(t, 1, b.Count("b"))
+		assert.Equal(t, 3, b.Count("c"))
+		assert.Equal(t, 0, b.Count("missing"))
+	})
+
+	t.Run("Add создаёт новую версию", func(t *testing.T) {
+		b1 := NewBag[int]().Add(1)
+		b2 := b1.Add(1)
+
+		assert.Equal(t, 1, b1.Count(1), "оригинал не должен измениться")
+		assert.Equal(t, 2, b2.Count(1))
+	})
+}
+
+func TestBag_Remove(t *testing.T) {
+	base := NewBag[string]().AddN("a", 3).Add("b")
+
+	t.Run("удаление одного вхождения", func(t *testing.T) {
+		b := base.Remove("a")
+
+		assert.Equal(t, 2, b.Count("a"))
+		assert.Equal(t, 3, b.Len())
+		assert.Equal(t, 3, base.Count("a"), "оригинал не должен измениться")
+	})
+
+	t.Run("удаление последнего вхождения", func(t *testing.T) {
+		b := base.Remove("b")
+
+		assert.False(t, b.Contains("b"))
+		assert.Equal(t, 1, b.DistinctCount())
+	})
+
+	t.Run("удаление всех вхождений", func(t *testing.T) {
+		b := base.RemoveAll("a")
+
+		assert.Equal(t, 0, b.Count("a"))
+		assert.Equal(t, 1, b.Len())
+	})
+
+	t.Run("удаление отсутствующего элемента", func(t *testing.T) {
+		assert.Same(t, base, base.Remove("missing"))
+	})
+}
+
+func TestBag_Distinct(t *testing.T) {
+	b := NewBag[int]().AddN(1, 5).AddN(2, 2)
+
+	var distinct []int
+	for v := range b.Distinct() {
+		distinct = append(distinct, v)
+	}
+
+	assert.ElementsMatch(t, []int{1, 2}, distinct)
+}
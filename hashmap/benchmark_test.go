@@ -2,6 +2,7 @@ package hashmap
 
 import (
 	"fmt"
+	"maps"
 	"math/rand"
 	"testing"
 )
@@ -24,7 +25,15 @@ func buildNaive(size int) *NaiveHashMap[int, int] {
 }
 
 func buildSharded(size int) *ShardedHashMap[int, int] {
-	m := NewShardedHashMap[int, int]()
+	m := NewShardedHashMap(WithMaxLoad[int, int](8))
+	for i := 0; i < size; i++ {
+		m = m.Set(i, i)
+	}
+	return m
+}
+
+func buildIntMap(size int) *IntMap[int] {
+	m := NewIntMap[int]()
 	for i := 0; i < size; i++ {
 		m = m.Set(i, i)
 	}
@@ -79,7 +88,7 @@ func BenchmarkBuild(b *testing.B) {
 			b.ReportAllocs()
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				m := NewShardedHashMap[int, int]()
+				m := NewShardedHashMap(WithMaxLoad[int, int](8))
 				for j := 0; j < size; j++ {
 					m = m.Set(j, j)
 				}
@@ -489,7 +498,7 @@ func BenchmarkMixedOperations(b *testing.B) {
 		b.ResetTimer()
 
 		for i := 0; i < b.N; i++ {
-			m := NewShardedHashMap[int, int]()
+			m := NewShardedHashMap(WithMaxLoad[int, int](8))
 			for j := 0; j < size; j++ {
 				m = m.Set(j, j)
 			}
@@ -573,3 +582,348 @@ func BenchmarkMemoryAllocation(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkIntMapBuild(b *testing.B) {
+	sizes := []int{100, 1000, 10000}
+
+	for _, size := range sizes {
+		b.Run(fmt.Sprintf("IntMap/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m := NewIntMap[int]()
+				for j := 0; j < size; j++ {
+					m = m.Set(j, j)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("HashMap/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m := NewHashMap[int, int]()
+				for j := 0; j < size; j++ {
+					m = m.Set(j, j)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkIntMapGet(b *testing.B) {
+	sizes := []int{100, 1000, 10000, 100000}
+
+	for _, size := range sizes {
+		im := buildIntMap(size)
+		hm := buildPersistent(size)
+
+		keysHit := pregenKeys(size, 1<<16)
+
+		b.Run(fmt.Sprintf("IntMap/size_%d/hit", size), func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				k := keysHit[i&(len(keysHit)-1)]
+				_, _ = im.Get(k)
+			}
+		})
+
+		b.Run(fmt.Sprintf("HashMap/size_%d/hit", size), func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				k := keysHit[i&(len(keysHit)-1)]
+				_, _ = hm.Get(k)
+			}
+		})
+
+		b.Run(fmt.Sprintf("IntMap/size_%d/miss", size), func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				k := keysHit[i&(len(keysHit)-1)] + size + 1
+				_, _ = im.Get(k)
+			}
+		})
+
+		b.Run(fmt.Sprintf("HashMap/size_%d/miss", size), func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				k := keysHit[i&(len(keysHit)-1)] + size + 1
+				_, _ = hm.Get(k)
+			}
+		})
+	}
+}
+
+func BenchmarkIntMapSetUpdate(b *testing.B) {
+	sizes := []int{100, 1000, 10000, 100000}
+
+	for _, size := range sizes {
+		im := buildIntMap(size)
+		hm := buildPersistent(size)
+
+		keys := pregenKeys(size, 1<<16)
+
+		b.Run(fmt.Sprintf("IntMap/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				k := keys[i&(len(keys)-1)]
+				_ = im.Set(k, 999)
+			}
+		})
+
+		b.Run(fmt.Sprintf("HashMap/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				k := keys[i&(len(keys)-1)]
+				_ = hm.Set(k, 999)
+			}
+		})
+	}
+}
+
+func BenchmarkIntMapDelete(b *testing.B) {
+	sizes := []int{100, 1000, 10000, 100000}
+
+	for _, size := range sizes {
+		im := buildIntMap(size)
+		hm := buildPersistent(size)
+
+		keys := pregenKeys(size, 1<<16)
+
+		b.Run(fmt.Sprintf("IntMap/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				k := keys[i&(len(keys)-1)]
+				_ = im.Delete(k)
+			}
+		})
+
+		b.Run(fmt.Sprintf("HashMap/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				k := keys[i&(len(keys)-1)]
+				_ = hm.Delete(k)
+			}
+		})
+	}
+}
+
+func BenchmarkIntMapIterate(b *testing.B) {
+	sizes := []int{100, 1000, 10000}
+
+	for _, size := range sizes {
+		im := buildIntMap(size)
+		hm := buildPersistent(size)
+
+		b.Run(fmt.Sprintf("IntMap/size_%d", size), func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for k, v := range im.All() {
+					_, _ = k, v
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("HashMap/size_%d", size), func(b *testing.B) {
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for k, v := range hm.All() {
+					_, _ = k, v
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkIntMapUnion(b *testing.B) {
+	sizes := []int{1000, 10000}
+
+	for _, size := range sizes {
+		// половина ключей пересекается
+		leftIm := buildIntMap(size)
+		rightIm := NewIntMap[int]()
+		leftHm := buildPersistent(size)
+		rightHm := NewHashMap[int, int]()
+		for i := size / 2; i < size+size/2; i++ {
+			rightIm = rightIm.Set(i, i)
+			rightHm = rightHm.Set(i, i)
+		}
+
+		b.Run(fmt.Sprintf("IntMap/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = leftIm.Union(rightIm)
+			}
+		})
+
+		b.Run(fmt.Sprintf("HashMapLoop/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m := leftHm
+				for k, v := range rightHm.All() {
+					if !m.Contains(k) {
+						m = m.Set(k, v)
+					}
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("IntMapIntersection/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = leftIm.Intersection(rightIm)
+			}
+		})
+
+		b.Run(fmt.Sprintf("HashMapIntersectionLoop/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m := NewHashMap[int, int]()
+				for k, v := range leftHm.All() {
+					if rightHm.Contains(k) {
+						m = m.Set(k, v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFromMap(b *testing.B) {
+	sizes := []int{1000, 10000, 100000}
+
+	for _, size := range sizes {
+		source := buildGoMap(size)
+
+		b.Run(fmt.Sprintf("SetLoop/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m := NewHashMap[int, int]()
+				for k, v := range source {
+					m = m.Set(k, v)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("FromMap/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				FromMap(source)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Collect/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Collect(maps.All(source))
+			}
+		})
+	}
+}
+
+func BenchmarkToMap(b *testing.B) {
+	sizes := []int{1000, 10000, 100000}
+
+	for _, size := range sizes {
+		m := buildPersistent(size)
+
+		b.Run(fmt.Sprintf("ToMap/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.ToMap()
+			}
+		})
+	}
+}
+
+// mix - функция с ненулевой стоимостью на запись, чтобы замер не сводился к обходу дерева
+func mix(x int) int {
+	h := uint64(x)
+	for i := 0; i < 16; i++ {
+		h ^= h >> 33
+		h *= 0xff51afd7ed558ccd
+	}
+	return int(h)
+}
+
+func BenchmarkParallel(b *testing.B) {
+	const size = 1_000_000
+	m := FromMap(buildGoMap(size))
+	value := func(_, v int) int { return mix(v) }
+	keep := func(_, v int) bool { return mix(v)%2 == 0 }
+	sum := func(acc, _, v int) int { return acc + mix(v) }
+	add := func(a, b int) int { return a + b }
+
+	b.Run("Map/Sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			MapValues(m, value)
+		}
+	})
+	b.Run("Filter/Sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Filter(m, keep)
+		}
+	})
+	b.Run("Reduce/Sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			Reduce(m, 0, sum)
+		}
+	})
+
+	for _, workers := range []int{1, -1} {
+		name := "workers_1"
+		if workers < 0 {
+			name = "workers_GOMAXPROCS"
+		}
+		b.Run("Map/Parallel/"+name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ParallelMap(m, workers, value)
+			}
+		})
+		b.Run("Filter/Parallel/"+name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ParallelFilter(m, workers, keep)
+			}
+		})
+		b.Run("Reduce/Parallel/"+name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ParallelReduce(m, workers, 0, sum, add)
+			}
+		})
+	}
+}
+
+// BenchmarkShardedReshard - Set и Delete после решардинга до большого числа бакетов:
+// стоимость копирования каталога страниц растёт с числом бакетов
+func BenchmarkShardedReshard(b *testing.B) {
+	const size = 10000
+	for _, buckets := range []int{1 << 8, 1 << 12, 1 << 16, 1 << 20} {
+		m := NewShardedHashMap[int, int]()
+		for i := 0; i < size; i++ {
+			m = m.Set(i, i)
+		}
+		m = m.reshard(buckets)
+
+		b.Run(fmt.Sprintf("Set/buckets_%d", buckets), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = m.Set(i%size, i)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Delete/buckets_%d", buckets), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = m.Delete(i % size)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package hashmap
+
+import (
+	"errors"
+	"iter"
+)
+
+var ErrBiMapConflict = errors.New("bimap: value already bound to another key")
+
+// ConflictPolicy определяет поведение Put, когда значение уже связано с другим ключом
+type ConflictPolicy int
+
+const (
+	// ReplaceOnConflict удаляет старую пару, в которой было значение
+	ReplaceOnConflict ConflictPolicy = iota
+	// RejectOnConflict оставляет отображение без изменений и возвращает ErrBiMapConflict
+	RejectOnConflict
+)
+
+// BiMap - взаимно однозначное отображение K <-> V.
+// Хранит прямой и обратный HashMap, которые всегда меняются вместе.
+type BiMap[K comparable, V comparable] struct {
+	forward  *HashMap[K, V]
+	backward *HashMap[V, K]
+	policy   ConflictPolicy
+}
+
+func NewBiMap[K comparable, V comparable]() *BiMap[K, V] {
+	return NewBiMapWithPolicy[K, V](ReplaceOnConflict)
+}
+
+func NewBiMapWithPolicy[K comparable, V comparable](policy ConflictPolicy) *BiMap[K, V] {
+	return &BiMap[K, V]{
+		forward:  NewHashMap[K, V](),
+		backward: NewHashMap[V, K](),
+		policy:   policy,
+	}
+}
+
+func (m *BiMap[K, V]) Len() int {
+	return m.forward.Len()
+}
+
+func (m *BiMap[K, V]) Policy() ConflictPolicy {
+	return m.policy
+}
+
+func (m *BiMap[K, V]) GetByKey(key K) (V, bool) {
+	return m.forward.Get(key)
+}
+
+func (m *BiMap[K, V]) GetByValue(value V) (K, bool) {
+	return m.backward.Get(value)
+}
+
+func (m *BiMap[K, V]) ContainsKey(key K) bool {
+	return m.forward.Contains(key)
+}
+
+func (m *BiMap[K, V]) ContainsValue(value V) bool {
+	return m.backward.Contains(value)
+}
+
+// Put связывает key и value. Старое значение ключа освобождается.
+// Если value уже связано с другим ключом, поведение определяет политика:
+// ReplaceOnConflict удаляет ту пару, RejectOnConflict возвращает ErrBiMapConflict.
+func (m *BiMap[K, V]) Put(key K, value V) (*BiMap[K, V], error) {
+	if oldKey, ok := m.backward.Get(value); ok {
+		if oldKey == key {
+			return m, nil
+		}
+		if m.policy == RejectOnConflict {
+			return m, ErrBiMapConflict
+		}
+	}
+
+	forward := m.forward
+	backward := m.backward
+
+	if oldKey, ok := backward.Get(value); ok {
+		forward = forward.Delete(oldKey)
+	}
+	if oldValue, ok := forward.Get(key); ok {
+		backward = backward.Delete(oldValue)
+	}
+
+	return &BiMap[K, V]{
+		forward:  forward.Set(key, value),
+		backward: backward.Set(value, key),
+		policy:   m.policy,
+	}, nil
+}
+
+func (m *BiMap[K, V]) DeleteByKey(key K) *BiMap[K, V] {
+	value, ok := m.forward.Get(key)
+	if !ok {
+		return m
+	}
+
+	return &BiMap[K, V]{
+		forward:  m.forward.Delete(key),
+		backward: m.backward.Delete(value),
+		policy:   m.policy,
+	}
+}
+
+func (m *BiMap[K, V]) DeleteByValue(value V) *BiMap[K, V] {
+	key, ok := m.backward.Get(value)
+	if !ok {
+		return m
+	}
+
+	return &BiMap[K, V]{
+		forward:  m.forward.Delete(key),
+		backward: m.backward.Delete(value),
+		policy:   m.policy,
+	}
+}
+
+// Inverse возвращает отображение V -> K за O(1): оба направления уже хранятся
+func (m *BiMap[K, V]) Inverse() *BiMap[V, K] {
+	return &BiMap[V, K]{
+		forward:  m.backward,
+		backward: m.forward,
+		policy:   m.policy,
+	}
+}
+
+func (m *BiMap[K, V]) All() iter.Seq2[K, V] {
+	return m.forward.All()
+}
+
+func (m *BiMap[K, V]) Keys() iter.Seq[K] {
+	return m.forward.Keys()
+}
+
+func (m *BiMap[K, V]) Values() iter.Seq[V] {
+	return m.forward.Values()
+}
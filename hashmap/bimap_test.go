@@ -0,0 +1,130 @@
+package hashmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBiMap_Put(t *testing.T) {
+	t.Run("поиск в обе стороны", func(t *testing.T) {
+		m, err := NewBiMap[int, string]().Put(1, "one")
+		require.NoError(t, err)
+		m, err = m.Put(2, "two")
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, m.Len())
+
+		val, ok := m.GetByKey(1)
+		require.True(t, ok)
+		assert.Equal(t, "one", val)
+
+		key, ok := m.GetByValue("two")
+		require.True(t, ok)
+		assert.Equal(t, 2, key)
+	})
+
+	t.Run("новое значение для ключа освобождает старое", func(t *testing.T) {
+		m, _ := NewBiMap[int, string]().Put(1, "one")
+
+		m2, err := m.Put(1, "uno")
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, m2.Len())
+		assert.False(t, m2.ContainsValue("one"), "старое значение должно быть освобождено")
+		key, _ := m2.GetByValue("uno")
+		assert.Equal(t, 1, key)
+
+		val, _ := m.GetByKey(1)
+		assert.Equal(t, "one", val, "оригинал не должен измениться")
+	})
+
+	t.Run("повторная пара возвращает ту же версию", func(t *testing.T) {
+		m, _ := NewBiMap[int, string]().Put(1, "one")
+
+		m2, err := m.Put(1, "one")
+		require.NoError(t, err)
+		assert.Same(t, m, m2)
+	})
+}
+
+func TestBiMap_ConflictPolicy(t *testing.T) {
+	t.Run("ReplaceOnConflict удаляет старую пару", func(t *testing.T) {
+		m, _ := NewBiMap[int, string]().Put(1, "one")
+		m, _ = m.Put(2, "two")
+
+		m2, err := m.Put(3, "one")
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, m2.Len())
+		assert.False(t, m2.ContainsKey(1), "ключ, связанный со значением, должен быть удалён")
+		key, _ := m2.GetByValue("one")
+		assert.Equal(t, 3, key)
+	})
+
+	t.Run("RejectOnConflict возвращает ошибку", func(t *testing.T) {
+		m, _ := NewBiMapWithPolicy[int, string](RejectOnConflict).Put(1, "one")
+
+		m2, err := m.Put(3, "one")
+
+		assert.ErrorIs(t, err, ErrBiMapConflict)
+		assert.Same(t, m, m2, "при отказе отображение не должно измениться")
+	})
+
+	t.Run("замена значения ключа без конфликта разрешена при RejectOnConflict", func(t *testing.T) {
+		m, _ := NewBiMapWithPolicy[int, string](RejectOnConflict).Put(1, "one")
+
+		m2, err := m.Put(1, "uno")
+
+		require.NoError(t, err)
+		assert.False(t, m2.ContainsValue("one"))
+	})
+}
+
+func TestBiMap_Delete(t *testing.T) {
+	m, _ := NewBiMap[int, string]().Put(1, "one")
+	m, _ = m.Put(2, "two")
+
+	t.Run("удаление по ключу", func(t *testing.T) {
+		m2 := m.DeleteByKey(1)
+
+		assert.Equal(t, 1, m2.Len())
+		assert.False(t, m2.ContainsKey(1))
+		assert.False(t, m2.ContainsValue("one"))
+		assert.True(t, m.ContainsKey(1), "оригинал не должен измениться")
+	})
+
+	t.Run("удаление по значению", func(t *testing.T) {
+		m2 := m.DeleteByValue("two")
+
+		assert.Equal(t, 1, m2.Len())
+		assert.False(t, m2.ContainsKey(2))
+		assert.False(t, m2.ContainsValue("two"))
+	})
+
+	t.Run("удаление отсутствующего", func(t *testing.T) {
+		assert.Same(t, m, m.DeleteByKey(100))
+		assert.Same(t, m, m.DeleteByValue("missing"))
+	})
+}
+
+func TestBiMap_Inverse(t *testing.T) {
+	m, _ := NewBiMap[int, string]().Put(1, "one")
+	m, _ = m.Put(2, "two")
+
+	inv := m.Inverse()
+
+	assert.Equal(t, 2, inv.Len())
+	key, ok := inv.GetByKey("one")
+	require.True(t, ok)
+	assert.Equal(t, 1, key)
+	assert.Equal(t, m.Policy(), inv.Policy())
+
+	inv2, err := inv.Put("three", 3)
+	require.NoError(t, err)
+	val, ok := inv2.Inverse().GetByKey(3)
+	require.True(t, ok)
+	assert.Equal(t, "three", val)
+	assert.Equal(t, 2, m.Len(), "оригинал не должен измениться")
+}
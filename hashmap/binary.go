@@ -0,0 +1,406 @@
+package hashmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"math/bits"
+	"slices"
+)
+
+var ErrCorruptStream = errors.New("hashmap: corrupt binary stream")
+
+const (
+	recordNode uint8 = iota + 1
+	recordVersion
+)
+
+// hmapRecord - единица бинарного потока. Узлы пишутся один раз в post-order и получают ID,
+// версии ссылаются на корень по ID. Поля экспортированы для encoding/gob.
+type hmapRecord[K comparable, V any] struct {
+	Kind     uint8
+	ID       uint64
+	DataMap  uint32
+	NodeMap  uint32
+	Keys     []K
+	Values   []V
+	Children []uint64 // ID поддеревьев в порядке позиций
+	Root     uint64
+	Len      int
+	// Layout - номер первой версии потока с той же хэш-функцией, что у этой: раскладки узлов
+	// у таких версий согласованы, и декодер может восстанавливать одну по разнице с другой
+	Layout int
+}
+
+// hmapLayout отличает хэш-функции версий. Функции WithHashFunc несравнимы,
+// поэтому все версии с собственной функцией считаются одной раскладкой.
+type hmapLayout struct {
+	seed   maphash.Seed
+	custom bool
+}
+
+// HashMapEncoder пишет несколько версий HashMap в один поток.
+// Узлы, общие для нескольких версий, записываются только один раз.
+type HashMapEncoder[K comparable, V any] struct {
+	enc     *gob.Encoder
+	ids     map[*hmapNode[K, V]]uint64
+	nextID  uint64
+	layouts []hmapLayout
+}
+
+func NewHashMapEncoder[K comparable, V any](w io.Writer) *HashMapEncoder[K, V] {
+	return &HashMapEncoder[K, V]{
+		enc:    gob.NewEncoder(w),
+		ids:    make(map[*hmapNode[K, V]]uint64),
+		nextID: 1,
+	}
+}
+
+func (e *HashMapEncoder[K, V]) Encode(m *HashMap[K, V]) error {
+	node := m.root
+	if node == nil {
+		node = &hmapNode[K, V]{}
+	}
+	root, err := e.encodeNode(node)
+	if err != nil {
+		return err
+	}
+
+	layout := hmapLayout{seed: m.seed, custom: m.hashFn != nil}
+	first := slices.Index(e.layouts, layout)
+	if first < 0 {
+		first = len(e.layouts)
+	}
+	if err := e.enc.Encode(hmapRecord[K, V]{Kind: recordVersion, Root: root, Len: m.len, Layout: first}); err != nil {
+		return err
+	}
+	e.layouts = append(e.layouts, layout)
+	return nil
+}
+
+// encodeNode пишет поддерево в post-order, чтобы поддеревья всегда шли раньше родителя
+func (e *HashMapEncoder[K, V]) encodeNode(node *hmapNode[K, V]) (uint64, error) {
+	if id, ok := e.ids[node]; ok {
+		return id, nil
+	}
+
+	resolved := node.resolve()
+	record := hmapRecord[K, V]{
+		Kind:     recordNode,
+		DataMap:  resolved.dataMap,
+		NodeMap:  resolved.nodeMap,
+		Keys:     make([]K, len(resolved.entries)),
+		Values:   make([]V, len(resolved.entries)),
+		Children: make([]uint64, len(resolved.nodes)),
+	}
+	for i, e := range resolved.entries {
+		record.Keys[i], record.Values[i] = e.key, e.value
+	}
+	for i, child := range resolved.nodes {
+		id, err := e.encodeNode(child)
+		if err != nil {
+			return 0, err
+		}
+		record.Children[i] = id
+	}
+
+	record.ID = e.nextID
+	if err := e.enc.Encode(record); err != nil {
+		return 0, err
+	}
+	e.ids[node] = e.nextID
+	e.nextID++
+	return record.ID, nil
+}
+
+type hmapDiff[K comparable, V any] struct {
+	keys    []K
+	values  []V
+	deleted []K
+}
+
+// diffChild сравнивает два поддерева, лежащих в одной позиции. Совпадающие указатели означают
+// общее поддерево. Записи сначала сравниваются по адресу слота (узлы разделяют неизменённые срезы записей),
+// а соседи изменённой записи, скопированные вместе с ней, - по значению.
+func (d *hmapDiff[K, V]) diffChild(a, b *hmapNode[K, V]) {
+	if a == b {
+		return
+	}
+	if a != nil {
+		a = a.resolve()
+	}
+	if b != nil {
+		b = b.resolve()
+	}
+
+	old := make(map[K]*entry[K, V])
+	var fresh []*entry[K, V]
+	collectOld := func(e *entry[K, V]) { old[e.key] = e }
+	collectFresh := func(e *entry[K, V]) { fresh = append(fresh, e) }
+
+	if a == nil || b == nil || a.isCollision() || b.isCollision() {
+		collectEntries(a, collectOld)
+		collectEntries(b, collectFresh)
+	} else {
+		for i := uint32(0); i <= hmapMask; i++ {
+			bit := uint32(1) << i
+			an, bn := a.nodeAt(bit), b.nodeAt(bit)
+			if an != nil && bn != nil {
+				d.diffChild(an, bn)
+				continue
+			}
+
+			ae, be := a.entryAt(bit), b.entryAt(bit)
+			if ae != nil && ae == be {
+				continue
+			}
+			if ae != nil {
+				collectOld(ae)
+			}
+			if be != nil {
+				collectFresh(be)
+			}
+			collectEntries(an, collectOld)
+			collectEntries(bn, collectFresh)
+		}
+	}
+
+	for _, e := range fresh {
+		if prev, ok := old[e.key]; !ok || prev != e && !sameValue(prev.value, e.value) {
+			d.keys = append(d.keys, e.key)
+			d.values = append(d.values, e.value)
+		}
+		delete(old, e.key)
+	}
+	for k := range old {
+		d.deleted = append(d.deleted, k)
+	}
+}
+
+// sameValue сообщает, что значения заведомо равны. Значения несравнимых типов считаются разными.
+func sameValue[V any](a, b V) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return any(a) == any(b)
+}
+
+func (n *hmapNode[K, V]) nodeAt(bit uint32) *hmapNode[K, V] {
+	if n.nodeMap&bit == 0 {
+		return nil
+	}
+	return n.nodes[n.nodeIndex(bit)]
+}
+
+func (n *hmapNode[K, V]) entryAt(bit uint32) *entry[K, V] {
+	if n.dataMap&bit == 0 {
+		return nil
+	}
+	return &n.entries[n.dataIndex(bit)]
+}
+
+func collectEntries[K comparable, V any](node *hmapNode[K, V], fn func(*entry[K, V])) {
+	if node == nil {
+		return
+	}
+	node = node.resolve()
+	for i := range node.entries {
+		fn(&node.entries[i])
+	}
+	for _, child := range node.nodes {
+		collectEntries(child, fn)
+	}
+}
+
+// HashMapDecoder читает версии, записанные HashMapEncoder.
+//
+// Раскладка узлов зависит от хэш-функции, а случайный maphash.Seed не сериализуется. Поэтому узлы
+// восстанавливаются как есть, с тем же шарингом, что в исходных версиях, только если декодер создан
+// с той же детерминированной хэш-функцией (WithSeed или WithHashFunc), что и записанные отображения;
+// соответствие раскладки проверяется. Иначе все версии потока получают один новый seed: первая версия
+// раскладки строится снизу вверх, а остальные - применением к ней разницы, которая вычисляется
+// по общим ID узлов, поэтому неизменённые поддеревья снова разделяются через path copying.
+type HashMapDecoder[K comparable, V any] struct {
+	dec     *gob.Decoder
+	hashFn  func(K) uint64
+	nodes   map[uint64]*hmapNode[K, V]
+	counts  map[*hmapNode[K, V]]int // число записей в поддереве
+	checked map[*hmapNode[K, V]]bool
+	// raw - корни версий в раскладке потока, versions - декодированные версии
+	raw      []*hmapNode[K, V]
+	layouts  []int
+	versions []*HashMap[K, V]
+}
+
+func NewHashMapDecoder[K comparable, V any](r io.Reader, opts ...Option[K, V]) *HashMapDecoder[K, V] {
+	var template HashMap[K, V]
+	for _, opt := range opts {
+		opt(&template)
+	}
+	return &HashMapDecoder[K, V]{
+		dec:     gob.NewDecoder(r),
+		hashFn:  template.hashFn,
+		nodes:   make(map[uint64]*hmapNode[K, V]),
+		counts:  make(map[*hmapNode[K, V]]int),
+		checked: make(map[*hmapNode[K, V]]bool),
+	}
+}
+
+// Decode возвращает следующую версию из потока или io.EOF, если версий больше нет
+func (d *HashMapDecoder[K, V]) Decode() (*HashMap[K, V], error) {
+	for {
+		var record hmapRecord[K, V]
+		if err := d.dec.Decode(&record); err != nil {
+			return nil, err
+		}
+
+		switch record.Kind {
+		case recordNode:
+			if err := d.node(record); err != nil {
+				return nil, err
+			}
+		case recordVersion:
+			return d.version(record)
+		default:
+			return nil, fmt.Errorf("%w: unknown record kind %d", ErrCorruptStream, record.Kind)
+		}
+	}
+}
+
+func (d *HashMapDecoder[K, V]) node(record hmapRecord[K, V]) error {
+	if len(record.Keys) != len(record.Values) ||
+		record.DataMap&record.NodeMap != 0 ||
+		record.DataMap|record.NodeMap != 0 && bits.OnesCount32(record.DataMap) != len(record.Keys) ||
+		bits.OnesCount32(record.NodeMap) != len(record.Children) {
+		return fmt.Errorf("%w: node %d does not match its bitmaps", ErrCorruptStream, record.ID)
+	}
+
+	node := &hmapNode[K, V]{dataMap: record.DataMap, nodeMap: record.NodeMap}
+	count := len(record.Keys)
+	if len(record.Keys) > 0 {
+		node.entries = make([]entry[K, V], len(record.Keys))
+		for i := range record.Keys {
+			node.entries[i] = entry[K, V]{key: record.Keys[i], value: record.Values[i]}
+		}
+	}
+	if len(record.Children) > 0 {
+		node.nodes = make([]*hmapNode[K, V], len(record.Children))
+		for i, id := range record.Children {
+			child, ok := d.nodes[id]
+			if !ok {
+				return fmt.Errorf("%w: unknown node %d", ErrCorruptStream, id)
+			}
+			node.nodes[i] = child
+			count += d.counts[child]
+		}
+	}
+	d.nodes[record.ID] = node
+	d.counts[node] = count
+	return nil
+}
+
+func (d *HashMapDecoder[K, V]) version(record hmapRecord[K, V]) (*HashMap[K, V], error) {
+	root, ok := d.nodes[record.Root]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown root %d", ErrCorruptStream, record.Root)
+	}
+	if d.counts[root] != record.Len {
+		return nil, fmt.Errorf("%w: tree has %d entries, expected %d", ErrCorruptStream, d.counts[root], record.Len)
+	}
+	if record.Layout < 0 || record.Layout > len(d.versions) ||
+		record.Layout < len(d.versions) && d.layouts[record.Layout] != record.Layout {
+		return nil, fmt.Errorf("%w: bad layout %d", ErrCorruptStream, record.Layout)
+	}
+
+	var m *HashMap[K, V]
+	if d.hashFn != nil {
+		m = &HashMap[K, V]{root: root, len: record.Len, hashFn: d.hashFn}
+		if !d.check(m, root) {
+			return nil, fmt.Errorf("%w: node layout does not match the decoder hash function", ErrCorruptStream)
+		}
+	} else {
+		m = d.rebuild(root, record)
+	}
+
+	d.raw = append(d.raw, root)
+	d.layouts = append(d.layouts, record.Layout)
+	d.versions = append(d.versions, m)
+	return m, nil
+}
+
+// check проверяет, что каждую запись ещё не проверенных узлов можно найти по её хэшу в m
+func (d *HashMapDecoder[K, V]) check(m *HashMap[K, V], node *hmapNode[K, V]) bool {
+	if d.checked[node] {
+		return true
+	}
+	for _, e := range node.entries {
+		if _, ok := m.Get(e.key); !ok {
+			return false
+		}
+	}
+	for _, child := range node.nodes {
+		if !d.check(m, child) {
+			return false
+		}
+	}
+	d.checked[node] = true
+	return true
+}
+
+// rebuild строит версию под новым seed: по разнице с последней версией той же раскладки
+// или снизу вверх, если такой версии нет
+func (d *HashMapDecoder[K, V]) rebuild(root *hmapNode[K, V], record hmapRecord[K, V]) *HashMap[K, V] {
+	for i := len(d.versions) - 1; i >= 0; i-- {
+		if d.layouts[i] != record.Layout {
+			continue
+		}
+		diff := &hmapDiff[K, V]{}
+		diff.diffChild(d.raw[i], root)
+		m := d.versions[i]
+		for _, k := range diff.deleted {
+			m = m.Delete(k)
+		}
+		for j := range diff.keys {
+			m = m.Set(diff.keys[j], diff.values[j])
+		}
+		return m
+	}
+
+	empty := NewHashMap[K, V]()
+	if len(d.versions) > 0 {
+		empty.seed = d.versions[0].seed
+	}
+	entries := make([]entry[K, V], 0, record.Len)
+	collectEntries(root, func(e *entry[K, V]) { entries = append(entries, *e) })
+	return fromEntries(empty, entries)
+}
+
+func (m *HashMap[K, V]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewHashMapEncoder[K, V](&buf).Encode(m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *HashMap[K, V]) UnmarshalBinary(data []byte) error {
+	decoded, err := NewHashMapDecoder[K, V](bytes.NewReader(data)).Decode()
+	if err != nil {
+		return err
+	}
+	if m.hashFn != nil {
+		// получатель создан с детерминированным хэшем: перестраиваем дерево под него
+		entries := make([]entry[K, V], 0, decoded.len)
+		for k, v := range decoded.All() {
+			entries = append(entries, entry[K, V]{key: k, value: v})
+		}
+		decoded = fromEntries(m.emptyLike(), entries)
+	}
+	*m = *decoded
+	return nil
+}
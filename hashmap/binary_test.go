@@ -0,0 +1,187 @@
+package hashmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireHashMapEqual[K comparable, V any](t *testing.T, expected, actual *HashMap[K, V]) {
+	t.Helper()
+	require.Equal(t, expected.Len(), actual.Len())
+	for k, v := range expected.All() {
+		got, ok := actual.Get(k)
+		require.True(t, ok, "ключ %v должен существовать", k)
+		require.Equal(t, v, got)
+	}
+}
+
+func TestHashMap_Binary(t *testing.T) {
+	t.Run("одна версия через BinaryMarshaler", func(t *testing.T) {
+		for _, size := range []int{0, 1, 100, 5000} {
+			m := buildPersistent(size)
+
+			data, err := m.MarshalBinary()
+			require.NoError(t, err)
+
+			var decoded HashMap[int, int]
+			require.NoError(t, decoded.UnmarshalBinary(data))
+			requireHashMapEqual(t, m, &decoded)
+		}
+	})
+
+	t.Run("несколько версий с общими узлами", func(t *testing.T) {
+		base := buildPersistent(5000)
+		versions := []*HashMap[int, int]{
+			base,
+			base.Set(10, -1),
+			base.Delete(20).Set(100000, 1),
+			base.Set(10, -1).Delete(30),
+		}
+
+		var buf bytes.Buffer
+		enc := NewHashMapEncoder[int, int](&buf)
+		for _, m := range versions {
+			require.NoError(t, enc.Encode(m))
+		}
+
+		dec := NewHashMapDecoder[int, int](&buf)
+		var decoded []*HashMap[int, int]
+		for {
+			m, err := dec.Decode()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			decoded = append(decoded, m)
+		}
+
+		require.Len(t, decoded, len(versions))
+		for i := range versions {
+			requireHashMapEqual(t, versions[i], decoded[i])
+		}
+		assert.False(t, decoded[2].Contains(20))
+		assert.True(t, decoded[0].Contains(20))
+
+		// версии, полученные изменением одного ключа, разделяют большую часть корня
+		sharedChildren := 0
+		for i := range decoded[0].root.nodes {
+			if decoded[0].root.nodes[i] == decoded[1].root.nodes[i] {
+				sharedChildren++
+			}
+		}
+		assert.Equal(t, len(decoded[0].root.nodes)-1, sharedChildren)
+	})
+
+	t.Run("общие поддеревья не дублируются в потоке", func(t *testing.T) {
+		base := buildPersistent(10000)
+
+		var shared bytes.Buffer
+		enc := NewHashMapEncoder[int, int](&shared)
+		var independent int
+		for i := 0; i < 10; i++ {
+			m := base.Set(i*1000, -i)
+			require.NoError(t, enc.Encode(m))
+
+			data, err := m.MarshalBinary()
+			require.NoError(t, err)
+			independent += len(data)
+		}
+
+		assert.Less(t, shared.Len()*3, independent, "поток с шарингом должен быть в разы меньше")
+	})
+
+	t.Run("строковые ключи и коллизии внутри версии", func(t *testing.T) {
+		m := NewHashMap[string, string]()
+		for i := 0; i < 1000; i++ {
+			m = m.Set(fmt.Sprintf("key_%d", i), fmt.Sprintf("value_%d", i))
+		}
+
+		var buf bytes.Buffer
+		enc := NewHashMapEncoder[string, string](&buf)
+		require.NoError(t, enc.Encode(m))
+		require.NoError(t, enc.Encode(m.Delete("key_1")))
+
+		dec := NewHashMapDecoder[string, string](&buf)
+		first, err := dec.Decode()
+		require.NoError(t, err)
+		second, err := dec.Decode()
+		require.NoError(t, err)
+
+		requireHashMapEqual(t, m, first)
+		requireHashMapEqual(t, m.Delete("key_1"), second)
+	})
+
+	t.Run("ссылка на неизвестный узел", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(hmapRecord[int, int]{Kind: recordVersion, Root: 5, Len: 1}))
+
+		_, err := NewHashMapDecoder[int, int](&buf).Decode()
+		assert.ErrorIs(t, err, ErrCorruptStream)
+	})
+
+	t.Run("узел не совпадает с битовыми картами", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := gob.NewEncoder(&buf)
+		require.NoError(t, enc.Encode(hmapRecord[int, int]{Kind: recordNode, ID: 1, DataMap: 0b11, Keys: []int{1}, Values: []int{1}}))
+
+		_, err := NewHashMapDecoder[int, int](&buf).Decode()
+		assert.ErrorIs(t, err, ErrCorruptStream)
+	})
+}
+
+func TestHashMap_BinarySharedNodes(t *testing.T) {
+	opt := WithSeed[int, int](7)
+	base := NewHashMap[int, int](opt)
+	for i := 0; i < 5000; i++ {
+		base = base.Set(i, i)
+	}
+	versions := []*HashMap[int, int]{base, base.Set(10, -1), base.Delete(20), base.Set(10, -1).Delete(30)}
+
+	var buf bytes.Buffer
+	enc := NewHashMapEncoder[int, int](&buf)
+	for _, m := range versions {
+		require.NoError(t, enc.Encode(m))
+	}
+	data := buf.Bytes()
+
+	t.Run("декодер с той же хэш-функцией восстанавливает узлы как есть", func(t *testing.T) {
+		dec := NewHashMapDecoder[int, int](bytes.NewReader(data), opt)
+		var decoded []*HashMap[int, int]
+		for range versions {
+			m, err := dec.Decode()
+			require.NoError(t, err)
+			decoded = append(decoded, m)
+		}
+
+		for i := range versions {
+			requireHashMapEqual(t, versions[i], decoded[i])
+			assert.Equal(t, dumpShape(versions[i]), dumpShape(decoded[i]))
+		}
+		// узел, общий для исходных версий, общий и для декодированных
+		for i := range versions[0].root.nodes {
+			assert.Equal(t, versions[0].root.nodes[i] == versions[1].root.nodes[i],
+				decoded[0].root.nodes[i] == decoded[1].root.nodes[i], "поддерево %d", i)
+		}
+		// декодированная версия - полноценное отображение с той же хэш-функцией
+		assert.Equal(t, dumpShape(versions[0].Set(-1, -1)), dumpShape(decoded[0].Set(-1, -1)))
+	})
+
+	t.Run("декодер с другой хэш-функцией", func(t *testing.T) {
+		_, err := NewHashMapDecoder[int, int](bytes.NewReader(data), WithSeed[int, int](8)).Decode()
+		assert.ErrorIs(t, err, ErrCorruptStream)
+	})
+
+	t.Run("узлы записываются один раз", func(t *testing.T) {
+		var single bytes.Buffer
+		require.NoError(t, NewHashMapEncoder[int, int](&single).Encode(base))
+		// три производные версии добавляют только скопированные пути
+		assert.Less(t, len(data), single.Len()*2)
+	})
+}
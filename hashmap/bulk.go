@@ -0,0 +1,106 @@
+package hashmap
+
+import (
+	"iter"
+	"math/bits"
+)
+
+type hashedEntry[K comparable, V any] struct {
+	hash  uint64
+	entry *entry[K, V]
+}
+
+// FromMap строит HashMap из копии m снизу вверх, без path copying. Опции те же, что у NewHashMap.
+func FromMap[K comparable, V any](m map[K]V, opts ...Option[K, V]) *HashMap[K, V] {
+	entries := make([]entry[K, V], 0, len(m))
+	for k, v := range m {
+		entries = append(entries, entry[K, V]{key: k, value: v})
+	}
+	return fromEntries(NewHashMap(opts...), entries)
+}
+
+// Collect строит HashMap из последовательности пар. Если ключ встречается несколько раз,
+// остаётся последнее значение, как при последовательных Set.
+func Collect[K comparable, V any](seq iter.Seq2[K, V], opts ...Option[K, V]) *HashMap[K, V] {
+	var entries []entry[K, V]
+	index := make(map[K]int)
+	for k, v := range seq {
+		if i, ok := index[k]; ok {
+			entries[i].value = v
+			continue
+		}
+		index[k] = len(entries)
+		entries = append(entries, entry[K, V]{key: k, value: v})
+	}
+	return fromEntries(NewHashMap(opts...), entries)
+}
+
+// ToMap копирует записи в новую map
+func (m *HashMap[K, V]) ToMap() map[K]V {
+	result := make(map[K]V, m.len)
+	for k, v := range m.All() {
+		result[k] = v
+	}
+	return result
+}
+
+// fromEntries строит HashMap снизу вверх: записи раскладываются по 5-битным
+// сегментам хэша, и каждый узел создаётся один раз, без path copying.
+// Ключи в entries должны быть уникальными.
+func fromEntries[K comparable, V any](m *HashMap[K, V], entries []entry[K, V]) *HashMap[K, V] {
+	if len(entries) == 0 {
+		return m
+	}
+
+	hashed := make([]hashedEntry[K, V], len(entries))
+	for i := range entries {
+		hashed[i] = hashedEntry[K, V]{
+			hash:  m.hash(entries[i].key),
+			entry: &entries[i],
+		}
+	}
+
+	return m.spill(&HashMap[K, V]{
+		root: m.buildNode(hashed, 0),
+		len:  len(entries),
+		seed: m.seed,
+	})
+}
+
+func (m *HashMap[K, V]) buildNode(entries []hashedEntry[K, V], shift uint) *hmapNode[K, V] {
+	if shift > hmapLastShift || shift == hmapSecondShift && len(entries) <= collisionThreshold {
+		// совпадают все биты хэша
+		collided := make([]entry[K, V], len(entries))
+		for i, e := range entries {
+			collided[i] = *e.entry
+		}
+		return m.newCollision(collided, shift)
+	}
+	if shift == hmapSecondShift {
+		// большой узел коллизий: дальше записи ветвятся по вторичному хэшу
+		for i := range entries {
+			entries[i].hash = secondaryHash(entries[i].entry.key)
+		}
+	}
+
+	var buckets [hmapMask + 1][]hashedEntry[K, V]
+	for _, e := range entries {
+		bit := bitAt(e.hash, shift)
+		idx := bits.TrailingZeros32(bit)
+		buckets[idx] = append(buckets[idx], e)
+	}
+
+	node := &hmapNode[K, V]{}
+	for idx, bucket := range buckets {
+		switch len(bucket) {
+		case 0:
+		case 1:
+			node.dataMap |= uint32(1) << idx
+			node.entries = append(node.entries, *bucket[0].entry)
+		default:
+			node.nodeMap |= uint32(1) << idx
+			node.nodes = append(node.nodes, m.buildNode(bucket, shift+hmapShift))
+		}
+	}
+	return node
+}
@@ -0,0 +1,65 @@
+package hashmap
+
+import (
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashMap_FromMap(t *testing.T) {
+	for _, size := range []int{0, 1, 100, 5000} {
+		t.Run(fmt.Sprintf("размер %d", size), func(t *testing.T) {
+			source := make(map[string]int, size)
+			expected := NewHashMap[string, int]()
+			for i := 0; i < size; i++ {
+				source[fmt.Sprintf("key-%d", i)] = i
+				expected = expected.Set(fmt.Sprintf("key-%d", i), i)
+			}
+
+			m := FromMap(source)
+			requireHashMapEqual(t, expected, m)
+			assert.Equal(t, source, m.ToMap())
+			assert.Equal(t, source, Collect(maps.All(source)).ToMap())
+		})
+	}
+
+	t.Run("форма как после Set с тем же seed", func(t *testing.T) {
+		seeded := WithSeed[int, int](7)
+		expected := NewHashMap(seeded)
+		source := map[int]int{}
+		for i := 0; i < 3000; i++ {
+			expected = expected.Set(i, i*i)
+			source[i] = i * i
+		}
+		assert.Equal(t, dumpShape(expected), dumpShape(FromMap(source, seeded)))
+		assert.Equal(t, dumpShape(expected), dumpShape(Collect(expected.All(), seeded)))
+	})
+
+	t.Run("Collect оставляет последнее значение ключа", func(t *testing.T) {
+		m := Collect(func(yield func(string, int) bool) {
+			for i := 0; i < 100; i++ {
+				if !yield(fmt.Sprintf("key-%d", i%10), i) {
+					return
+				}
+			}
+		})
+		require.Equal(t, 10, m.Len())
+		value, _ := m.Get("key-3")
+		assert.Equal(t, 93, value)
+	})
+
+	t.Run("map копируется", func(t *testing.T) {
+		source := map[string]int{"a": 1}
+		m := FromMap(source)
+		source["a"] = 2
+		out := m.ToMap()
+		out["b"] = 3
+
+		value, _ := m.Get("a")
+		assert.Equal(t, 1, value)
+		assert.False(t, m.Contains("b"))
+	})
+}
@@ -0,0 +1,61 @@
+package hashmap
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var errBadDelta = errors.New("hashmap: bad hashmap delta")
+
+// hmapDelta - изменения между двумя версиями: новые и изменённые записи и удалённые ключи
+type hmapDelta[K comparable, V any] struct {
+	Keys    []K `json:"k,omitempty"`
+	Values  []V `json:"v,omitempty"`
+	Deleted []K `json:"d,omitempty"`
+}
+
+// HashMapCodec кодирует версии отображения для history.DurableHistory: версию целиком - как JSON-объект,
+// а следующую версию - как разницу с предыдущей. Разница ищется тем же обходом, что и в HashMapEncoder:
+// общие поддеревья пропускаются.
+type HashMapCodec[K comparable, V any] struct{}
+
+func NewHashMapCodec[K comparable, V any]() *HashMapCodec[K, V] {
+	return &HashMapCodec[K, V]{}
+}
+
+func (c *HashMapCodec[K, V]) Marshal(m *HashMap[K, V]) ([]byte, error) {
+	return m.MarshalJSON()
+}
+
+func (c *HashMapCodec[K, V]) Unmarshal(data []byte) (*HashMap[K, V], error) {
+	m := NewHashMap[K, V]()
+	if err := m.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *HashMapCodec[K, V]) Diff(prev, next *HashMap[K, V]) ([]byte, error) {
+	d := &hmapDiff[K, V]{}
+	d.diffChild(prev.root, next.root)
+	return json.Marshal(hmapDelta[K, V]{Keys: d.keys, Values: d.values, Deleted: d.deleted})
+}
+
+func (c *HashMapCodec[K, V]) Apply(prev *HashMap[K, V], data []byte) (*HashMap[K, V], error) {
+	var delta hmapDelta[K, V]
+	if err := json.Unmarshal(data, &delta); err != nil {
+		return nil, err
+	}
+	if len(delta.Keys) != len(delta.Values) {
+		return nil, errBadDelta
+	}
+
+	m := prev
+	for _, k := range delta.Deleted {
+		m = m.Delete(k)
+	}
+	for i, k := range delta.Keys {
+		m = m.Set(k, delta.Values[i])
+	}
+	return m, nil
+}
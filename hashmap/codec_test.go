@@ -0,0 +1,36 @@
+package hashmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashMapCodec_DiffApply(t *testing.T) {
+	codec := NewHashMapCodec[int, int]()
+	base := NewHashMap[int, int]()
+	for i := 0; i < 5000; i++ {
+		base = base.Set(i, i)
+	}
+
+	next := base.Set(7, -7).Delete(100).Delete(4999).Set(10_000, 1)
+	delta, err := codec.Diff(base, next)
+	require.NoError(t, err)
+	applied, err := codec.Apply(base, delta)
+	require.NoError(t, err)
+	requireHashMapEqual(t, next, applied)
+
+	full, err := codec.Marshal(next)
+	require.NoError(t, err)
+	assert.Less(t, len(delta)*100, len(full), "разница не должна содержать общие поддеревья")
+
+	t.Run("версии с разным seed", func(t *testing.T) {
+		other := NewHashMap[int, int]().Set(1, 1).Set(2, 20).Set(3, 3)
+		delta, err := codec.Diff(base, other)
+		require.NoError(t, err)
+		applied, err := codec.Apply(base, delta)
+		require.NoError(t, err)
+		requireHashMapEqual(t, other, applied)
+	})
+}
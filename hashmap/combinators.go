@@ -0,0 +1,301 @@
+package hashmap
+
+import "github.com/ykhdr/persistent-data-structures/internal/parallel"
+
+// MapValues заменяет каждое значение результатом fn. Ключи не меняются, поэтому дерево результата
+// повторяет форму исходного узел в узел, и ни один ключ не хэшируется заново.
+// Результат хранится в памяти, даже если m хранится в файле.
+func MapValues[K comparable, V, U any](m *HashMap[K, V], fn func(K, V) U) *HashMap[K, U] {
+	return &HashMap[K, U]{
+		root:   mapNode(m.root, fn),
+		len:    m.len,
+		seed:   m.seed,
+		hashFn: m.hashFn,
+	}
+}
+
+func mapNode[K comparable, V, U any](node *hmapNode[K, V], fn func(K, V) U) *hmapNode[K, U] {
+	node = node.resolve()
+	mapped := &hmapNode[K, U]{dataMap: node.dataMap, nodeMap: node.nodeMap}
+	if len(node.entries) > 0 {
+		mapped.entries = make([]entry[K, U], len(node.entries))
+		for i, e := range node.entries {
+			mapped.entries[i] = entry[K, U]{key: e.key, value: fn(e.key, e.value)}
+		}
+	}
+	if len(node.nodes) > 0 {
+		mapped.nodes = make([]*hmapNode[K, U], len(node.nodes))
+		for i, child := range node.nodes {
+			mapped.nodes[i] = mapNode(child, fn)
+		}
+	}
+	return mapped
+}
+
+// Reduce сворачивает записи в порядке обхода All, начиная с init
+func Reduce[K comparable, V, A any](m *HashMap[K, V], init A, fn func(A, K, V) A) A {
+	acc := init
+	for k, v := range m.All() {
+		acc = fn(acc, k, v)
+	}
+	return acc
+}
+
+// Filter оставляет записи, для которых pred вернул true. Поддерево, из которого ничего не удалено,
+// переходит в результат тем же указателем, а если не удалено ничего, возвращается сам m.
+func Filter[K comparable, V any](m *HashMap[K, V], pred func(K, V) bool) *HashMap[K, V] {
+	kept, _, keptLen, _ := m.splitNode(m.root, 0, pred, false, 1)
+	if keptLen == m.len {
+		return m
+	}
+	return m.withRoot(kept, keptLen)
+}
+
+// Partition делит отображение на записи, для которых pred вернул true, и остальные.
+// pred вызывается для каждой записи один раз. Как и в Filter, целиком попавшие в одну часть
+// поддеревья разделяются с m.
+func Partition[K comparable, V any](m *HashMap[K, V], pred func(K, V) bool) (matched, rest *HashMap[K, V]) {
+	yes, no, yesLen, noLen := m.splitNode(m.root, 0, pred, true, 1)
+	switch m.len {
+	case yesLen:
+		return m, m.withRoot(nil, 0)
+	case noLen:
+		return m.withRoot(nil, 0), m
+	}
+	return m.withRoot(yes, yesLen), m.withRoot(no, noLen)
+}
+
+// withRoot возвращает версию с тем же хэшем и хранилищем, что у m. Пустое дерево - nil.
+func (m *HashMap[K, V]) withRoot(root *hmapNode[K, V], length int) *HashMap[K, V] {
+	if root == nil {
+		root = &hmapNode[K, V]{}
+	}
+	return m.spill(&HashMap[K, V]{
+		root: root,
+		len:  length,
+		seed: m.seed,
+	})
+}
+
+// splitNode делит поддерево по pred и возвращает обе части с числом записей в них. Пустая часть - nil,
+// часть, в которую попало всё поддерево, - исходный узел. Если both == false, строится только первая часть.
+// Дочерние поддеревья узла делятся в workers горутинах, а их потомки - последовательно.
+// Части приводятся к канонической форме, как после Delete: поддерево из одной записи встраивается
+// в родителя, а маленькое поддерево по вторичному хэшу сворачивается в линейный узел коллизий.
+func (m *HashMap[K, V]) splitNode(node *hmapNode[K, V], shift uint, pred func(K, V) bool, both bool, workers int) (yes, no *hmapNode[K, V], yesLen, noLen int) {
+	original := node
+	node = node.resolve()
+
+	matched := make([]bool, len(node.entries))
+	for i, e := range node.entries {
+		matched[i] = pred(e.key, e.value)
+		if matched[i] {
+			yesLen++
+		} else {
+			noLen++
+		}
+	}
+
+	if node.linearAt(shift) {
+		switch {
+		case noLen == 0:
+			return original, nil, yesLen, 0
+		case yesLen == 0:
+			return nil, original, 0, noLen
+		}
+		yes = &hmapNode[K, V]{entries: pick(node.entries, matched, true)}
+		if both {
+			no = &hmapNode[K, V]{entries: pick(node.entries, matched, false)}
+		}
+		return yes, no, yesLen, noLen
+	}
+
+	yesChildren := make([]*hmapNode[K, V], len(node.nodes))
+	noChildren := make([]*hmapNode[K, V], len(node.nodes))
+	yesCounts := make([]int, len(node.nodes))
+	noCounts := make([]int, len(node.nodes))
+	parallel.Run(workers, len(node.nodes), func(i int) {
+		yesChildren[i], noChildren[i], yesCounts[i], noCounts[i] = m.splitNode(node.nodes[i], shift+hmapShift, pred, both, 1)
+	})
+	for i := range node.nodes {
+		yesLen += yesCounts[i]
+		noLen += noCounts[i]
+	}
+
+	switch {
+	case noLen == 0:
+		return original, nil, yesLen, 0
+	case yesLen == 0:
+		return nil, original, 0, noLen
+	}
+	yes = m.assemble(node, shift, matched, true, yesChildren, yesLen)
+	if both {
+		no = m.assemble(node, shift, matched, false, noChildren, noLen)
+	}
+	return yes, no, yesLen, noLen
+}
+
+// assemble собирает часть узла: записи, у которых matched совпадает с side, и непустые поддеревья
+// из children. Поддерево из одной записи встраивается на свою позицию.
+func (m *HashMap[K, V]) assemble(node *hmapNode[K, V], shift uint, matched []bool, side bool, children []*hmapNode[K, V], length int) *hmapNode[K, V] {
+	if length == 0 {
+		return nil
+	}
+
+	part := &hmapNode[K, V]{}
+	for i := uint32(0); i <= hmapMask; i++ {
+		bit := uint32(1) << i
+		switch {
+		case node.dataMap&bit != 0:
+			idx := node.dataIndex(bit)
+			if matched[idx] == side {
+				part.dataMap |= bit
+				part.entries = append(part.entries, node.entries[idx])
+			}
+		case node.nodeMap&bit != 0:
+			child := children[node.nodeIndex(bit)]
+			switch {
+			case child == nil:
+			case child.lazy == nil && child.singleEntry():
+				part.dataMap |= bit
+				part.entries = append(part.entries, child.entries[0])
+			default:
+				part.nodeMap |= bit
+				part.nodes = append(part.nodes, child)
+			}
+		}
+	}
+
+	if shift == hmapSecondShift && length <= collisionThreshold {
+		entries := make([]entry[K, V], 0, length)
+		collectEntries(part, func(e *entry[K, V]) { entries = append(entries, *e) })
+		return m.newCollision(entries, shift)
+	}
+	return part
+}
+
+func pick[T any](values []T, matched []bool, side bool) []T {
+	var out []T
+	for i, v := range values {
+		if matched[i] == side {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Update заменяет значение ключа результатом fn. fn получает текущее значение и признак наличия ключа
+// и возвращает новое значение и признак того, что ключ должен остаться: false удаляет ключ.
+// Ключ хэшируется один раз, а дерево проходится одним спуском, как в Set и Delete.
+// Если ключа нет и fn его не добавляет, возвращается сам m.
+func Update[K comparable, V any](m *HashMap[K, V], key K, fn func(V, bool) (V, bool)) *HashMap[K, V] {
+	root, delta, changed := m.updateNode(m.root, key, m.hash(key), 0, fn)
+	if !changed {
+		return m
+	}
+	return m.spill(&HashMap[K, V]{
+		root: root,
+		len:  m.len + delta,
+		seed: m.seed,
+	})
+}
+
+// updateNode находит позицию ключа так же, как setNode и deleteNode, и вызывает fn один раз.
+// Возвращает новый узел, изменение числа записей и признак того, что дерево изменилось.
+// Удаление приводит узлы к той же канонической форме, что и deleteNode.
+func (m *HashMap[K, V]) updateNode(node *hmapNode[K, V], key K, hash uint64, shift uint, fn func(V, bool) (V, bool)) (*hmapNode[K, V], int, bool) {
+	node = node.resolve()
+	var zero V
+
+	if node.linearAt(shift) {
+		for i, e := range node.entries {
+			if e.key != key {
+				continue
+			}
+			value, keep := fn(e.value, true)
+			if !keep {
+				return &hmapNode[K, V]{entries: removeAt(node.entries, i)}, -1, true
+			}
+			return &hmapNode[K, V]{entries: replaceAt(node.entries, i, entry[K, V]{key: key, value: value})}, 0, true
+		}
+		value, keep := fn(zero, false)
+		if !keep {
+			return node, 0, false
+		}
+		return m.newCollision(insertAt(node.entries, len(node.entries), entry[K, V]{key: key, value: value}), shift), 1, true
+	}
+	if shift == hmapSecondShift {
+		hash = secondaryHash(key)
+	}
+
+	bit := bitAt(hash, shift)
+	newNode := &hmapNode[K, V]{dataMap: node.dataMap, nodeMap: node.nodeMap, entries: node.entries, nodes: node.nodes}
+	var delta int
+
+	switch {
+	case node.dataMap&bit != 0:
+		idx := node.dataIndex(bit)
+		existing := node.entries[idx]
+		if existing.key == key {
+			value, keep := fn(existing.value, true)
+			if keep {
+				newNode.entries = replaceAt(node.entries, idx, entry[K, V]{key: key, value: value})
+				return newNode, 0, true
+			}
+			newNode.dataMap &^= bit
+			newNode.entries = removeAt(node.entries, idx)
+			delta = -1
+			break
+		}
+
+		value, keep := fn(zero, false)
+		if !keep {
+			return node, 0, false
+		}
+		// позиция занята другим ключом: обе записи уходят в новое поддерево
+		child := m.mergeEntries(existing, m.hashAt(existing.key, shift), entry[K, V]{key: key, value: value}, hash, shift+hmapShift)
+		newNode.dataMap &^= bit
+		newNode.entries = removeAt(node.entries, idx)
+		newNode.nodeMap |= bit
+		newNode.nodes = insertAt(node.nodes, node.nodeIndex(bit), child)
+		return newNode, 1, true
+
+	case node.nodeMap&bit != 0:
+		idx := node.nodeIndex(bit)
+		child, childDelta, changed := m.updateNode(node.nodes[idx], key, hash, shift+hmapShift, fn)
+		if !changed {
+			return node, 0, false
+		}
+		if childDelta >= 0 {
+			newNode.nodes = replaceAt(node.nodes, idx, child)
+			return newNode, childDelta, true
+		}
+
+		if child.singleEntry() {
+			newNode.nodeMap &^= bit
+			newNode.nodes = removeAt(node.nodes, idx)
+			newNode.dataMap |= bit
+			newNode.entries = insertAt(node.entries, node.dataIndex(bit), child.entries[0])
+		} else {
+			newNode.nodes = replaceAt(node.nodes, idx, child)
+		}
+		delta = childDelta
+
+	default:
+		value, keep := fn(zero, false)
+		if !keep {
+			return node, 0, false
+		}
+		newNode.dataMap |= bit
+		newNode.entries = insertAt(node.entries, node.dataIndex(bit), entry[K, V]{key: key, value: value})
+		return newNode, 1, true
+	}
+
+	if shift == hmapSecondShift && countEntries(newNode, collisionThreshold+1) <= collisionThreshold {
+		// поддерево по вторичному хэшу снова помещается в линейный узел коллизий
+		entries := make([]entry[K, V], 0, collisionThreshold)
+		collectEntries(newNode, func(e *entry[K, V]) { entries = append(entries, *e) })
+		return m.newCollision(entries, shift), delta, true
+	}
+	return newNode, delta, true
+}
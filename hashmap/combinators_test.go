@@ -0,0 +1,200 @@
+package hashmap
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashMap_Combinators(t *testing.T) {
+	hashers := map[string]Option[int, int]{
+		"seed":                WithSeed[int, int](11),
+		"16 значений хэша":    WithHashFunc[int, int](func(k int) uint64 { return uint64(k % 16) }),
+		"одинаковый хэш":      WithHashFunc[int, int](func(int) uint64 { return 7 }),
+		"различаются старшие": WithHashFunc[int, int](func(k int) uint64 { return uint64(k%64) << 58 }),
+	}
+	for name, opt := range hashers {
+		t.Run(name, func(t *testing.T) {
+			for _, size := range []int{0, 1, 9, 100, 3000} {
+				source := make(map[int]int, size)
+				for i := 0; i < size; i++ {
+					source[i] = i * 10
+				}
+				m := FromMap(source, opt)
+
+				for _, div := range []int{1, 2, 3, 7, size + 1} {
+					pred := func(k, v int) bool { return k%div != 0 }
+					expectedYes, expectedNo := map[int]int{}, map[int]int{}
+					for k, v := range source {
+						if pred(k, v) {
+							expectedYes[k] = v
+						} else {
+							expectedNo[k] = v
+						}
+					}
+
+					filtered := Filter(m, pred)
+					assert.Equal(t, expectedYes, filtered.ToMap(), "Filter размер %d делитель %d", size, div)
+					// форма та же, что у отображения, построенного с нуля
+					assert.Equal(t, dumpShape(FromMap(expectedYes, opt)), dumpShape(filtered), "Filter размер %d делитель %d", size, div)
+
+					matched, rest := Partition(m, pred)
+					require.Equal(t, len(expectedYes), matched.Len())
+					require.Equal(t, len(expectedNo), rest.Len())
+					assert.Equal(t, dumpShape(FromMap(expectedYes, opt)), dumpShape(matched))
+					assert.Equal(t, dumpShape(FromMap(expectedNo, opt)), dumpShape(rest))
+				}
+
+				strs := MapValues(m, func(k, v int) string { return strconv.Itoa(k + v) })
+				require.Equal(t, size, strs.Len())
+				for k, v := range source {
+					got, ok := strs.Get(k)
+					require.True(t, ok)
+					require.Equal(t, strconv.Itoa(k+v), got)
+				}
+				// новые версии результата продолжают использовать хэш исходного отображения
+				assert.Equal(t, dumpShape(MapValues(m.Set(-1, 0), func(k, v int) string { return strconv.Itoa(k + v) })),
+					dumpShape(strs.Set(-1, "-1")))
+
+				assert.Equal(t, size*(size-1)*5, Reduce(m, 0, func(acc, _, v int) int { return acc + v }))
+			}
+		})
+	}
+}
+
+func TestHashMap_FilterSharing(t *testing.T) {
+	m := NewHashMap[string, int]()
+	for i := 0; i < 5000; i++ {
+		m = m.Set(fmt.Sprintf("key-%d", i), i)
+	}
+
+	t.Run("без удалений возвращается тот же указатель", func(t *testing.T) {
+		assert.Same(t, m, Filter(m, func(string, int) bool { return true }))
+		matched, rest := Partition(m, func(string, int) bool { return false })
+		assert.Same(t, m, rest)
+		assert.Equal(t, 0, matched.Len())
+	})
+
+	t.Run("нетронутые поддеревья разделяются", func(t *testing.T) {
+		filtered := Filter(m, func(k string, _ int) bool { return k != "key-42" })
+		require.Equal(t, m.Len()-1, filtered.Len())
+		assert.False(t, filtered.Contains("key-42"))
+
+		shared := 0
+		for i, child := range filtered.root.nodes {
+			if child == m.root.nodes[i] {
+				shared++
+			}
+		}
+		assert.Equal(t, len(m.root.nodes)-1, shared, "копируется только путь к удалённому ключу")
+	})
+
+	t.Run("Partition разделяет поддеревья с исходным", func(t *testing.T) {
+		// все ключи одного поддерева корня уходят в одну часть
+		first := m.root.nodes[0]
+		inFirst := map[string]bool{}
+		collectEntries(first, func(e *entry[string, int]) { inFirst[e.key] = true })
+
+		matched, rest := Partition(m, func(k string, _ int) bool { return inFirst[k] })
+		assert.Equal(t, len(inFirst), matched.Len())
+		assert.Equal(t, m.Len()-len(inFirst), rest.Len())
+		assert.Same(t, first, matched.root.nodes[0])
+		assert.Same(t, m.root.nodes[1], rest.root.nodes[0])
+	})
+}
+
+func TestHashMap_Update(t *testing.T) {
+	m := NewHashMap[string, int]().Set("a", 1).Set("b", 2)
+	increment := func(v int, ok bool) (int, bool) { return v + 1, true }
+
+	updated := Update(m, "a", increment)
+	value, _ := updated.Get("a")
+	assert.Equal(t, 2, value)
+	value, _ = m.Get("a")
+	assert.Equal(t, 1, value, "старая версия не меняется")
+
+	added := Update(m, "c", increment)
+	value, ok := added.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 3, added.Len())
+
+	removed := Update(m, "b", func(int, bool) (int, bool) { return 0, false })
+	assert.False(t, removed.Contains("b"))
+	assert.Equal(t, 1, removed.Len())
+
+	assert.Same(t, m, Update(m, "missing", func(v int, ok bool) (int, bool) {
+		assert.False(t, ok)
+		return v, false
+	}))
+
+	hashers := map[string]Option[int, int]{
+		"seed":                WithSeed[int, int](11),
+		"16 значений хэша":    WithHashFunc[int, int](func(k int) uint64 { return uint64(k % 16) }),
+		"одинаковый хэш":      WithHashFunc[int, int](func(int) uint64 { return 7 }),
+		"различаются старшие": WithHashFunc[int, int](func(k int) uint64 { return uint64(k%64) << 58 }),
+	}
+	for name, opt := range hashers {
+		t.Run(name, func(t *testing.T) {
+			// Update даёт то же дерево, что Set и Delete, и вызывает fn ровно один раз
+			updated, expected := NewHashMap(opt), NewHashMap(opt)
+			for i := 0; i < 600; i++ {
+				key := i * 7 % 200
+				calls := 0
+				updated = Update(updated, key, func(v int, ok bool) (int, bool) {
+					calls++
+					return v + 1, !ok || i%3 != 0
+				})
+				require.Equal(t, 1, calls)
+
+				v, ok := expected.Get(key)
+				if !ok || i%3 != 0 {
+					expected = expected.Set(key, v+1)
+				} else {
+					expected = expected.Delete(key)
+				}
+				require.Equal(t, expected.Len(), updated.Len())
+			}
+			requireHashMapEqual(t, expected, updated)
+			assert.Equal(t, dumpShape(expected), dumpShape(updated))
+		})
+	}
+}
+
+func TestHashMap_CombinatorsDiskBacked(t *testing.T) {
+	f, err := CreateHashMapFile[int, int](filepath.Join(t.TempDir(), "hashmap.pds"), 4)
+	require.NoError(t, err)
+	defer f.Close()
+
+	source := map[int]int{}
+	m := f.Empty()
+	for i := 0; i < 2000; i++ {
+		m = m.Set(i, i)
+		source[i] = i
+	}
+
+	evens := Filter(m, func(k, _ int) bool { return k%2 == 0 })
+	assert.Equal(t, f, evens.loader, "результат Filter хранится в том же файле")
+	maps.DeleteFunc(source, func(k, _ int) bool { return k%2 != 0 })
+	assert.Equal(t, source, evens.ToMap())
+
+	doubled := MapValues(evens, func(_, v int) int { return v * 2 })
+	assert.Nil(t, doubled.loader)
+	value, _ := doubled.Get(10)
+	assert.Equal(t, 20, value)
+
+	bumped := Update(evens, 10, func(v int, _ bool) (int, bool) { return v + 1, true })
+	assert.Equal(t, f, bumped.loader, "результат Update хранится в том же файле")
+	value, _ = bumped.Get(10)
+	assert.Equal(t, 11, value)
+
+	require.NoError(t, f.Checkpoint(evens))
+	loaded, err := f.Load()
+	require.NoError(t, err)
+	assert.True(t, Equal(evens, loaded))
+}
@@ -0,0 +1,42 @@
+package hashmap
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ykhdr/persistent-data-structures/pdstest"
+)
+
+func TestMap_Contract(t *testing.T) {
+	str := func(i int) string { return fmt.Sprintf("key-%d", i) }
+	id := func(i int) int { return i }
+
+	t.Run("HashMap", func(t *testing.T) {
+		pdstest.Map(t, func() *HashMap[string, int] { return NewHashMap[string, int]() }, str, id)
+	})
+	t.Run("ShardedHashMap", func(t *testing.T) {
+		pdstest.Map(t, func() *ShardedHashMap[string, int] { return NewShardedHashMap[string, int]() }, str, id)
+	})
+	t.Run("ShardedHashMap с решардингом", func(t *testing.T) {
+		pdstest.Map(t, func() *ShardedHashMap[string, int] {
+			return NewShardedHashMap(WithBuckets[string, int](16), WithMaxLoad[string, int](1))
+		}, str, id)
+	})
+	t.Run("NaiveHashMap", func(t *testing.T) { pdstest.Map(t, NewNaiveHashMap[string, int], str, id) })
+	t.Run("OrderedMap", func(t *testing.T) { pdstest.Map(t, NewOrderedMap[string, int], str, id) })
+	t.Run("IntMap", func(t *testing.T) { pdstest.Map(t, NewIntMap[int], func(i int) int { return i * 7919 }, id) })
+	t.Run("HashMap с коллизиями", func(t *testing.T) {
+		pdstest.Map(t, func() *HashMap[int, int] {
+			return NewHashMap(WithHashFunc[int, int](func(k int) uint64 { return uint64(k % 4) }))
+		}, id, id)
+	})
+	t.Run("HashMapFile", func(t *testing.T) {
+		f, err := CreateHashMapFile[string, int](filepath.Join(t.TempDir(), "hashmap.pds"), 4)
+		require.NoError(t, err)
+		t.Cleanup(func() { f.Close() })
+		pdstest.Map(t, f.Empty, str, id)
+	})
+}
@@ -0,0 +1,288 @@
+package hashmap
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"reflect"
+
+	"github.com/ykhdr/persistent-data-structures/store"
+)
+
+// ErrKeyType возвращают HashMapFile и HashMapStore для типа ключа, который не переживает кодирование
+// узла в JSON: указатели, каналы и интерфейсы теряют идентичность, а неэкспортированные поля структур
+// не записываются вовсе, и после повторного открытия ключ был бы другим.
+var ErrKeyType = errors.New("hashmap: key type does not round-trip through JSON")
+
+// diskNode - узел в файле: записи узла и ссылки на его поддеревья в порядке позиций
+type diskNode[K comparable, V any] struct {
+	Keys   []K      `json:"k,omitempty"`
+	Values []V      `json:"v,omitempty"`
+	Refs   []uint64 `json:"n,omitempty"`
+}
+
+// valid проверяет, что число записей и ссылок на поддеревья (refs) совпадает с битовыми картами.
+// У узла коллизий обе карты пусты, а записей больше одной.
+func (n *diskNode[K, V]) valid(dataMap, nodeMap uint64, refs int) bool {
+	if len(n.Keys) != len(n.Values) || refs != bits.OnesCount64(nodeMap) {
+		return false
+	}
+	if dataMap == 0 && nodeMap == 0 && len(n.Keys) > 1 {
+		return true
+	}
+	return len(n.Keys) == bits.OnesCount64(dataMap)
+}
+
+// HashMapFile хранит узлы отображений в файле store.PageFile и подгружает их по требованию,
+// поэтому отображение может быть больше оперативной памяти. В памяти держатся только
+// заглушки и не больше cacheSize загруженных узлов.
+//
+// Раскладка узлов на диске должна совпадать между запусками, поэтому отображения файла
+// хэшируют ключи детерминированным hashKey с нулевым seed вместо maphash. Отображения,
+// полученные из Empty или Load, дописывают новые узлы каждой версии в конец файла.
+// Ключи записываются в JSON, поэтому CreateHashMapFile и OpenHashMapFile возвращают ErrKeyType
+// для типа ключа, который не восстанавливается из JSON без потерь.
+//
+// У операций отображения (Get, Set, Delete, обход и другие) нет возвращаемой ошибки, поэтому ошибка
+// ввода-вывода или повреждённый узел при чтении и записи приводят к панике со значением
+// *store.NodeError. Её можно перевести в ошибку через defer store.Recover(&err).
+// Load и Checkpoint не паникуют и возвращают такие ошибки сами.
+type HashMapFile[K comparable, V any] struct {
+	pages *store.PageFile
+	cache *store.Cache[*hmapNode[K, V]]
+}
+
+func CreateHashMapFile[K comparable, V any](path string, cacheSize int) (*HashMapFile[K, V], error) {
+	if err := checkDiskKey[K](); err != nil {
+		return nil, err
+	}
+	pages, err := store.CreatePageFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &HashMapFile[K, V]{pages: pages, cache: store.NewCache[*hmapNode[K, V]](cacheSize)}, nil
+}
+
+func OpenHashMapFile[K comparable, V any](path string, cacheSize int) (*HashMapFile[K, V], error) {
+	if err := checkDiskKey[K](); err != nil {
+		return nil, err
+	}
+	pages, err := store.OpenPageFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &HashMapFile[K, V]{pages: pages, cache: store.NewCache[*hmapNode[K, V]](cacheSize)}, nil
+}
+
+// Empty возвращает пустое отображение, узлы которого будут храниться в файле
+func (f *HashMapFile[K, V]) Empty() *HashMap[K, V] {
+	m := NewHashMap[K, V]()
+	m.hashFn = diskHash[K]
+	m.loader = f
+	m.root = f.spillNode(m.root)
+	return m
+}
+
+// Checkpoint записывает версию m и делает её той, которую вернёт Load после повторного открытия.
+// Отображение в памяти перестраивается со стабильным хэшем и целиком копируется в файл.
+func (f *HashMapFile[K, V]) Checkpoint(m *HashMap[K, V]) (err error) {
+	defer store.Recover(&err)
+
+	if m.loader != f {
+		entries := make([]entry[K, V], 0, m.len)
+		for k, v := range m.All() {
+			entries = append(entries, entry[K, V]{key: k, value: v})
+		}
+		m = fromEntries(f.Empty(), entries)
+	}
+
+	data := []byte{objectVersion}
+	data = binary.AppendUvarint(data, uint64(m.len))
+	data = binary.AppendUvarint(data, m.root.lazy.ref)
+
+	offset, err := f.pages.Append(data)
+	if err != nil {
+		return err
+	}
+	return f.pages.SetCheckpoint(offset)
+}
+
+// Load возвращает версию, сохранённую последним Checkpoint. Читается только корневой узел:
+// повреждённый checkpoint обнаруживается здесь, а не при первом обращении к отображению.
+func (f *HashMapFile[K, V]) Load() (_ *HashMap[K, V], err error) {
+	defer store.Recover(&err)
+
+	offset, err := f.pages.Checkpoint()
+	if err != nil {
+		return nil, err
+	}
+	data, err := f.pages.Read(offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || data[0] != objectVersion {
+		return nil, fmt.Errorf("%w: record at %d is not a hashmap version", store.ErrCorrupt, offset)
+	}
+
+	length, n := binary.Uvarint(data[1:])
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: record at %d bad length", store.ErrCorrupt, offset)
+	}
+	root, m := binary.Uvarint(data[1+n:])
+	if m <= 0 || root == 0 {
+		return nil, fmt.Errorf("%w: record at %d bad root", store.ErrCorrupt, offset)
+	}
+
+	result := NewHashMap[K, V]()
+	result.root = f.stub(root)
+	result.len = int(length)
+	result.hashFn = diskHash[K]
+	result.loader = f
+	result.root.resolve()
+	return result, nil
+}
+
+func (f *HashMapFile[K, V]) Close() error {
+	return f.pages.Close()
+}
+
+func (f *HashMapFile[K, V]) stub(ref uint64) *hmapNode[K, V] {
+	return &hmapNode[K, V]{lazy: &hmapLazy[K, V]{loader: f, ref: ref}}
+}
+
+func (f *HashMapFile[K, V]) loadNode(ref uint64) *hmapNode[K, V] {
+	if node, ok := f.cache.Get(ref); ok {
+		return node
+	}
+
+	data, err := f.pages.Read(ref)
+	if err != nil {
+		panic(&store.NodeError{Err: fmt.Errorf("hashmap: load node at %d: %w", ref, err)})
+	}
+	if len(data) == 0 || data[0] != objectInternal {
+		panic(&store.NodeError{Err: fmt.Errorf("hashmap: load node at %d: %w: unexpected record", ref, store.ErrCorrupt)})
+	}
+	dataMap, n := binary.Uvarint(data[1:])
+	if n <= 0 {
+		panic(&store.NodeError{Err: fmt.Errorf("hashmap: load node at %d: %w: bad node", ref, store.ErrCorrupt)})
+	}
+	nodeMap, k := binary.Uvarint(data[1+n:])
+	var stored diskNode[K, V]
+	if k <= 0 || json.Unmarshal(data[1+n+k:], &stored) != nil || !stored.valid(dataMap, nodeMap, len(stored.Refs)) {
+		panic(&store.NodeError{Err: fmt.Errorf("hashmap: load node at %d: %w: bad node", ref, store.ErrCorrupt)})
+	}
+
+	node := &hmapNode[K, V]{dataMap: uint32(dataMap), nodeMap: uint32(nodeMap)}
+	if len(stored.Keys) > 0 {
+		node.entries = make([]entry[K, V], len(stored.Keys))
+		for i := range stored.Keys {
+			node.entries[i] = entry[K, V]{key: stored.Keys[i], value: stored.Values[i]}
+		}
+	}
+	if len(stored.Refs) > 0 {
+		node.nodes = make([]*hmapNode[K, V], len(stored.Refs))
+		for i, r := range stored.Refs {
+			node.nodes[i] = f.stub(r)
+		}
+	}
+
+	f.cache.Put(ref, node)
+	return node
+}
+
+// spillNode записывает узел и все его дочерние узлы, которых ещё нет в файле.
+// Узлы не изменяются: записывается копия, в которой дочерние узлы заменены заглушками.
+func (f *HashMapFile[K, V]) spillNode(node *hmapNode[K, V]) *hmapNode[K, V] {
+	if node.lazy != nil && node.lazy.loader == f {
+		return node
+	}
+	node = node.resolve()
+
+	written := &hmapNode[K, V]{dataMap: node.dataMap, nodeMap: node.nodeMap, entries: node.entries}
+	stored := diskNode[K, V]{Keys: make([]K, len(node.entries)), Values: make([]V, len(node.entries))}
+	for i, e := range node.entries {
+		stored.Keys[i] = e.key
+		stored.Values[i] = e.value
+	}
+	if len(node.nodes) > 0 {
+		written.nodes = make([]*hmapNode[K, V], len(node.nodes))
+		stored.Refs = make([]uint64, len(node.nodes))
+		for i, child := range node.nodes {
+			stub := f.spillNode(child)
+			written.nodes[i] = stub
+			stored.Refs[i] = stub.lazy.ref
+		}
+	}
+
+	encoded, err := json.Marshal(stored)
+	if err != nil {
+		panic(&store.NodeError{Err: fmt.Errorf("hashmap: spill node: %w", err)})
+	}
+	data := binary.AppendUvarint([]byte{objectInternal}, uint64(node.dataMap))
+	data = binary.AppendUvarint(data, uint64(node.nodeMap))
+	data = append(data, encoded...)
+
+	ref, err := f.pages.Append(data)
+	if err != nil {
+		panic(&store.NodeError{Err: fmt.Errorf("hashmap: spill node: %w", err)})
+	}
+	f.cache.Put(ref, written)
+	return f.stub(ref)
+}
+
+// diskHash - стабильный между запусками хэш ключа: раскладка узлов в файле от него зависит
+func diskHash[K comparable](key K) uint64 {
+	return hashKey(0, key)
+}
+
+var (
+	jsonMarshalerType   = reflect.TypeFor[json.Marshaler]()
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// checkDiskKey проверяет, что ключи типа K после записи узла в JSON и чтения обратно равны исходным
+func checkDiskKey[K comparable]() error {
+	if t := reflect.TypeFor[K](); !roundTrips(t) {
+		return fmt.Errorf("%w: %v", ErrKeyType, t)
+	}
+	return nil
+}
+
+// roundTrips сообщает, что значения типа t восстанавливаются из JSON без потерь. Типы со своим
+// кодированием (json.Marshaler или encoding.TextMarshaler вместе с парным Unmarshaler) считаются
+// корректными; у структуры учитываются все поля, кроме пустых "_", потому что по ним сравниваются ключи.
+func roundTrips(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	if t.Implements(jsonMarshalerType) && ptr.Implements(jsonUnmarshalerType) ||
+		t.Implements(textMarshalerType) && ptr.Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Array:
+		return roundTrips(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if field.Name == "_" {
+				continue
+			}
+			if !field.IsExported() || field.Tag.Get("json") == "-" || !roundTrips(field.Type) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
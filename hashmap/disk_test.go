@@ -0,0 +1,221 @@
+package hashmap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ykhdr/persistent-data-structures/store"
+)
+
+// diskBacked переключает newTestHashMap на отображения, хранящиеся в HashMapFile
+var diskBacked = false
+
+// newTestHashMap создаёт пустое отображение для тестов из hashmap_test.go.
+// В дисковом режиме кэш узлов крошечный, чтобы узлы постоянно перечитывались из файла.
+func newTestHashMap[K comparable, V any](t *testing.T) *HashMap[K, V] {
+	if !diskBacked {
+		return NewHashMap[K, V]()
+	}
+
+	f, err := CreateHashMapFile[K, V](filepath.Join(t.TempDir(), "hashmap.pds"), 4)
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+	return f.Empty()
+}
+
+func TestHashMap_DiskBacked(t *testing.T) {
+	diskBacked = true
+	defer func() { diskBacked = false }()
+
+	for name, test := range map[string]func(*testing.T){
+		"SetAndGet":       TestHashMap_SetAndGet,
+		"GetMissing":      TestHashMap_GetMissing,
+		"Overwrite":       TestHashMap_Overwrite,
+		"Persistence":     TestHashMap_Persistence,
+		"Delete":          TestHashMap_Delete,
+		"IntKeys":         TestHashMap_IntKeys,
+		"LargeDataset":    TestHashMap_LargeDataset,
+		"Iterator":        TestHashMap_Iterator,
+		"Keys":            TestHashMap_Keys,
+		"Values":          TestHashMap_Values,
+		"Contains":        TestHashMap_Contains,
+		"CanonicalDelete": TestHashMap_CanonicalDelete,
+		"Equal":           TestHashMap_Equal,
+	} {
+		t.Run(name, test)
+	}
+}
+
+func TestHashMapFile_Checkpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashmap.pds")
+
+	f, err := CreateHashMapFile[string, int](path, 8)
+	require.NoError(t, err)
+
+	m := f.Empty()
+	expected := NewHashMap[string, int]()
+	for i := 0; i < 3000; i++ {
+		m = m.Set(fmt.Sprintf("key-%d", i), i)
+		expected = expected.Set(fmt.Sprintf("key-%d", i), i)
+	}
+	for i := 0; i < 3000; i += 3 {
+		m = m.Delete(fmt.Sprintf("key-%d", i))
+		expected = expected.Delete(fmt.Sprintf("key-%d", i))
+	}
+	require.NoError(t, f.Checkpoint(m))
+	require.NoError(t, f.Close())
+
+	f, err = OpenHashMapFile[string, int](path, 8)
+	require.NoError(t, err)
+	defer f.Close()
+
+	loaded, err := f.Load()
+	require.NoError(t, err)
+	requireHashMapEqual(t, expected, loaded)
+	assert.LessOrEqual(t, f.cache.Len(), 8, "в памяти не должно быть больше cacheSize узлов")
+
+	// после повторного открытия версия продолжает дописываться в тот же файл
+	next := loaded.Set("key-0", 0).Delete("key-1")
+	assert.Equal(t, loaded.Len(), next.Len())
+	assert.True(t, next.Contains("key-0"))
+	assert.False(t, loaded.Contains("key-0"), "загруженная версия не должна измениться")
+}
+
+func TestHashMapFile_CheckpointInMemory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashmap.pds")
+	f, err := CreateHashMapFile[int, string](path, 16)
+	require.NoError(t, err)
+
+	m := NewHashMap[int, string]()
+	for i := 0; i < 2000; i++ {
+		m = m.Set(i*31, fmt.Sprint(i))
+	}
+	require.NoError(t, f.Checkpoint(m))
+	require.NoError(t, f.Close())
+
+	f, err = OpenHashMapFile[int, string](path, 16)
+	require.NoError(t, err)
+	defer f.Close()
+
+	loaded, err := f.Load()
+	require.NoError(t, err)
+	requireHashMapEqual(t, m, loaded)
+}
+
+func TestHashMapFile_Errors(t *testing.T) {
+	t.Run("файл без checkpoint", func(t *testing.T) {
+		f, err := CreateHashMapFile[string, int](filepath.Join(t.TempDir(), "hashmap.pds"), 4)
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = f.Load()
+		assert.ErrorIs(t, err, store.ErrNoCheckpoint)
+	})
+
+	t.Run("повреждённый заголовок", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hashmap.pds")
+		require.NoError(t, os.WriteFile(path, []byte("garbage garbage garbage"), 0o644))
+
+		_, err := OpenHashMapFile[string, int](path, 4)
+		assert.ErrorIs(t, err, store.ErrCorrupt)
+	})
+
+	t.Run("повреждённый узел", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hashmap.pds")
+		f, err := CreateHashMapFile[int, int](path, 4)
+		require.NoError(t, err)
+		require.NoError(t, f.Checkpoint(buildPersistent(3000)))
+		loaded, err := f.Load()
+		require.NoError(t, err)
+		root := loaded.root.lazy.ref
+		require.NoError(t, f.Close())
+
+		// запись в середине файла - один из узлов отображения
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		flipByte(t, path, info.Size()/2)
+
+		f, err = OpenHashMapFile[int, int](path, 4)
+		require.NoError(t, err)
+		loaded, err = f.Load()
+		require.NoError(t, err)
+
+		readAll := func() (err error) {
+			defer store.Recover(&err)
+			for range loaded.All() {
+			}
+			return nil
+		}
+		err = readAll()
+		var nodeErr *store.NodeError
+		assert.ErrorAs(t, err, &nodeErr, "обычные операции сообщают о повреждении паникой NodeError")
+		assert.ErrorIs(t, err, store.ErrCorrupt)
+
+		// повреждённый корень обнаруживает уже Load
+		require.NoError(t, f.Close())
+		flipByte(t, path, int64(root)+4)
+		f, err = OpenHashMapFile[int, int](path, 4)
+		require.NoError(t, err)
+		defer f.Close()
+		_, err = f.Load()
+		assert.ErrorIs(t, err, store.ErrCorrupt)
+	})
+
+	t.Run("тип ключа не переживает JSON", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "hashmap.pds")
+		type hidden struct{ id int }
+
+		_, err := CreateHashMapFile[hidden, int](path, 4)
+		assert.ErrorIs(t, err, ErrKeyType, "неэкспортированные поля не записываются")
+		_, err = CreateHashMapFile[*int, int](path, 4)
+		assert.ErrorIs(t, err, ErrKeyType, "указатель теряет идентичность")
+		_, err = CreateHashMapFile[any, int](path, 4)
+		assert.ErrorIs(t, err, ErrKeyType, "интерфейс читается другим типом")
+		_, err = CreateHashMapFile[chan int, int](path, 4)
+		assert.ErrorIs(t, err, ErrKeyType)
+		_, err = OpenHashMapFile[[2]hidden, int](path, 4)
+		assert.ErrorIs(t, err, ErrKeyType)
+		assert.NoFileExists(t, path, "файл не создаётся для неподходящего ключа")
+
+		type point struct {
+			X, Y int
+			_    int
+		}
+		f, err := CreateHashMapFile[point, int](path, 4)
+		require.NoError(t, err)
+		m := f.Empty().Set(point{X: 1, Y: 2}, 3)
+		require.NoError(t, f.Checkpoint(m))
+		require.NoError(t, f.Close())
+
+		f, err = OpenHashMapFile[point, int](path, 4)
+		require.NoError(t, err)
+		defer f.Close()
+		loaded, err := f.Load()
+		require.NoError(t, err)
+		val, ok := loaded.Get(point{X: 1, Y: 2})
+		require.True(t, ok, "ключ-структура с экспортированными полями переживает повторное открытие")
+		assert.Equal(t, 3, val)
+	})
+
+	t.Run("Checkpoint после закрытия файла", func(t *testing.T) {
+		f, err := CreateHashMapFile[int, int](filepath.Join(t.TempDir(), "hashmap.pds"), 4)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		assert.Error(t, f.Checkpoint(buildPersistent(100)), "ошибка записи узла возвращается, а не паникует")
+	})
+}
+
+// flipByte инвертирует один байт файла
+func flipByte(t *testing.T, path string, offset int64) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[offset] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+}
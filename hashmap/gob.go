@@ -0,0 +1,254 @@
+package hashmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// Все структуры пакета хранят данные в неэкспортируемых полях, которые encoding/gob не видит.
+// GobEncode / GobDecode кодируют их содержимое в виде срезов с экспортируемыми полями,
+// поэтому вложенные структуры (например, HashMap[string, *array.Vector[int]]) кодируются рекурсивно.
+
+type gobPairs[K comparable, V any] struct {
+	Keys   []K
+	Values []V
+}
+
+type gobBiMap[K comparable, V comparable] struct {
+	Keys   []K
+	Values []V
+	Policy ConflictPolicy
+}
+
+type gobMultimap[K comparable, V comparable] struct {
+	Keys   []K
+	Values [][]V
+}
+
+func gobEncode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gobDecode(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func collectPairs[K comparable, V any](n int, all func(yield func(K, V) bool)) gobPairs[K, V] {
+	pairs := gobPairs[K, V]{Keys: make([]K, 0, n), Values: make([]V, 0, n)}
+	for k, v := range all {
+		pairs.Keys = append(pairs.Keys, k)
+		pairs.Values = append(pairs.Values, v)
+	}
+	return pairs
+}
+
+func decodePairs[K comparable, V any](data []byte) (gobPairs[K, V], error) {
+	var pairs gobPairs[K, V]
+	if err := gobDecode(data, &pairs); err != nil {
+		return pairs, err
+	}
+	if len(pairs.Keys) != len(pairs.Values) {
+		return pairs, fmt.Errorf("%w: %d keys for %d values", ErrCorruptStream, len(pairs.Keys), len(pairs.Values))
+	}
+	return pairs, nil
+}
+
+// GobEncode кодирует HashMap так же, как MarshalBinary
+func (m *HashMap[K, V]) GobEncode() ([]byte, error) {
+	return m.MarshalBinary()
+}
+
+func (m *HashMap[K, V]) GobDecode(data []byte) error {
+	return m.UnmarshalBinary(data)
+}
+
+func (m *ShardedHashMap[K, V]) GobEncode() ([]byte, error) {
+	return gobEncode(collectPairs(m.len, m.All()))
+}
+
+func (m *ShardedHashMap[K, V]) GobDecode(data []byte) error {
+	pairs, err := decodePairs[K, V](data)
+	if err != nil {
+		return err
+	}
+
+	// бакеты сразу рассчитаны на все записи, поэтому решардинг при декодировании не нужен
+	decoded := NewShardedHashMap[K, V]()
+	buckets := decoded.Buckets()
+	if decoded.maxLoad > 0 {
+		buckets = max(buckets, (len(pairs.Keys)+decoded.maxLoad-1)/decoded.maxLoad)
+	}
+	decoded = decoded.reshard(buckets)
+	for i, k := range pairs.Keys {
+		decoded.put(k, pairs.Values[i])
+	}
+	*m = *decoded
+	return nil
+}
+
+func (m *NaiveHashMap[K, V]) GobEncode() ([]byte, error) {
+	return gobEncode(collectPairs(len(m.data), func(yield func(K, V) bool) {
+		for k, v := range m.data {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}))
+}
+
+func (m *NaiveHashMap[K, V]) GobDecode(data []byte) error {
+	pairs, err := decodePairs[K, V](data)
+	if err != nil {
+		return err
+	}
+
+	m.data = make(map[K]V, len(pairs.Keys))
+	for i, k := range pairs.Keys {
+		m.data[k] = pairs.Values[i]
+	}
+	return nil
+}
+
+func (m *IntMap[V]) GobEncode() ([]byte, error) {
+	return gobEncode(collectPairs(m.len, m.All()))
+}
+
+func (m *IntMap[V]) GobDecode(data []byte) error {
+	pairs, err := decodePairs[int, V](data)
+	if err != nil {
+		return err
+	}
+
+	decoded := NewIntMap[V]()
+	for i, k := range pairs.Keys {
+		decoded = decoded.Set(k, pairs.Values[i])
+	}
+	*m = *decoded
+	return nil
+}
+
+// GobEncode сохраняет записи в порядке вставки
+func (m *OrderedMap[K, V]) GobEncode() ([]byte, error) {
+	return gobEncode(collectPairs(m.Len(), m.All()))
+}
+
+func (m *OrderedMap[K, V]) GobDecode(data []byte) error {
+	pairs, err := decodePairs[K, V](data)
+	if err != nil {
+		return err
+	}
+
+	decoded := NewOrderedMap[K, V]()
+	for i, k := range pairs.Keys {
+		decoded = decoded.Set(k, pairs.Values[i])
+	}
+	*m = *decoded
+	return nil
+}
+
+func (s *HashSet[T]) GobEncode() ([]byte, error) {
+	items := make([]T, 0, s.Len())
+	for item := range s.All() {
+		items = append(items, item)
+	}
+	return gobEncode(items)
+}
+
+func (s *HashSet[T]) GobDecode(data []byte) error {
+	var items []T
+	if err := gobDecode(data, &items); err != nil {
+		return err
+	}
+
+	decoded := NewHashSet[T]()
+	for _, item := range items {
+		decoded = decoded.Add(item)
+	}
+	*s = *decoded
+	return nil
+}
+
+func (m *Multimap[K, V]) GobEncode() ([]byte, error) {
+	var encoded gobMultimap[K, V]
+	for k, values := range m.data.All() {
+		set := make([]V, 0, values.Len())
+		for v := range values.All() {
+			set = append(set, v)
+		}
+		encoded.Keys = append(encoded.Keys, k)
+		encoded.Values = append(encoded.Values, set)
+	}
+	return gobEncode(encoded)
+}
+
+func (m *Multimap[K, V]) GobDecode(data []byte) error {
+	var encoded gobMultimap[K, V]
+	if err := gobDecode(data, &encoded); err != nil {
+		return err
+	}
+	if len(encoded.Keys) != len(encoded.Values) {
+		return fmt.Errorf("%w: %d keys for %d value sets", ErrCorruptStream, len(encoded.Keys), len(encoded.Values))
+	}
+
+	decoded := NewMultimap[K, V]()
+	for i, k := range encoded.Keys {
+		for _, v := range encoded.Values[i] {
+			decoded = decoded.Put(k, v)
+		}
+	}
+	*m = *decoded
+	return nil
+}
+
+func (b *Bag[T]) GobEncode() ([]byte, error) {
+	return gobEncode(collectPairs(b.counts.Len(), b.counts.All()))
+}
+
+func (b *Bag[T]) GobDecode(data []byte) error {
+	pairs, err := decodePairs[T, int](data)
+	if err != nil {
+		return err
+	}
+
+	decoded := NewBag[T]()
+	for i, item := range pairs.Keys {
+		if pairs.Values[i] <= 0 {
+			return fmt.Errorf("%w: count %d", ErrCorruptStream, pairs.Values[i])
+		}
+		decoded = decoded.AddN(item, pairs.Values[i])
+	}
+	*b = *decoded
+	return nil
+}
+
+func (m *BiMap[K, V]) GobEncode() ([]byte, error) {
+	pairs := collectPairs(m.Len(), m.All())
+	return gobEncode(gobBiMap[K, V]{Keys: pairs.Keys, Values: pairs.Values, Policy: m.policy})
+}
+
+func (m *BiMap[K, V]) GobDecode(data []byte) error {
+	var encoded gobBiMap[K, V]
+	if err := gobDecode(data, &encoded); err != nil {
+		return err
+	}
+	if len(encoded.Keys) != len(encoded.Values) {
+		return fmt.Errorf("%w: %d keys for %d values", ErrCorruptStream, len(encoded.Keys), len(encoded.Values))
+	}
+
+	// при декодировании конфликт означает, что пары в потоке не взаимно однозначны
+	decoded := NewBiMapWithPolicy[K, V](RejectOnConflict)
+	for i, k := range encoded.Keys {
+		var err error
+		if decoded, err = decoded.Put(k, encoded.Values[i]); err != nil {
+			return fmt.Errorf("%w: %v", ErrCorruptStream, err)
+		}
+	}
+	decoded.policy = encoded.Policy
+	*m = *decoded
+	return nil
+}
@@ -0,0 +1,147 @@
+package hashmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gobRoundTrip прогоняет значение через gob так, как это делает RPC-слой: внутри структуры-сообщения
+func gobRoundTrip[T any](t *testing.T, value T) T {
+	t.Helper()
+	type message struct {
+		Payload T
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(message{Payload: value}))
+	var decoded message
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	return decoded.Payload
+}
+
+func TestHashMap_Gob(t *testing.T) {
+	t.Run("большое и пустое отображение", func(t *testing.T) {
+		for _, size := range []int{0, 1, 100, 20000} {
+			m := NewHashMap[string, int]()
+			for i := 0; i < size; i++ {
+				m = m.Set(fmt.Sprintf("key-%d", i), i)
+			}
+			requireHashMapEqual(t, m, gobRoundTrip(t, m))
+		}
+	})
+
+	t.Run("коллизии ключей", func(t *testing.T) {
+		hashes := map[string]func(int) uint64{
+			"все ключи в одной коллизии": func(int) uint64 { return 7 },
+			"совпадают младшие 30 бит":   func(k int) uint64 { return uint64(k%4)<<30 | 12345 },
+			"совпадают младшие 10 бит":   func(k int) uint64 { return uint64(k)<<10 | 5 },
+		}
+		for name, hashFn := range hashes {
+			t.Run(name, func(t *testing.T) {
+				m := NewHashMap(WithHashFunc[int, string](hashFn))
+				for i := 0; i < 500; i++ {
+					m = m.Set(i, fmt.Sprint(i))
+				}
+				m = m.Delete(10).Delete(11)
+				require.Equal(t, 498, m.Len())
+
+				requireHashMapEqual(t, m, gobRoundTrip(t, m))
+			})
+		}
+	})
+}
+
+func TestShardedHashMap_Gob(t *testing.T) {
+	for _, size := range []int{0, 1, 20000} {
+		m := NewShardedHashMap[int, int]()
+		for i := 0; i < size; i++ {
+			m = m.Set(i, -i)
+		}
+
+		decoded := gobRoundTrip(t, m)
+		require.Equal(t, m.Len(), decoded.Len())
+		for k, v := range m.All() {
+			actual, ok := decoded.Get(k)
+			require.True(t, ok)
+			require.Equal(t, v, actual)
+		}
+	}
+}
+
+func TestCollections_Gob(t *testing.T) {
+	t.Run("NaiveHashMap", func(t *testing.T) {
+		m := NewNaiveHashMap[string, int]().Set("a", 1).Set("b", 2)
+		decoded := gobRoundTrip(t, m)
+		assert.Equal(t, 2, decoded.Len())
+		val, _ := decoded.Get("b")
+		assert.Equal(t, 2, val)
+	})
+
+	t.Run("IntMap", func(t *testing.T) {
+		m := NewIntMap[string]()
+		for i := -500; i < 500; i += 3 {
+			m = m.Set(i, fmt.Sprint(i))
+		}
+		keys, values := collectIntMap(gobRoundTrip(t, m))
+		expectedKeys, expectedValues := collectIntMap(m)
+		assert.Equal(t, expectedKeys, keys)
+		assert.Equal(t, expectedValues, values)
+	})
+
+	t.Run("OrderedMap сохраняет порядок", func(t *testing.T) {
+		m := NewOrderedMap[string, int]().Set("z", 1).Set("a", 2).Set("m", 3).Delete("a").Set("a", 4)
+		decoded := gobRoundTrip(t, m)
+
+		var keys []string
+		for k := range decoded.Keys() {
+			keys = append(keys, k)
+		}
+		assert.Equal(t, []string{"z", "m", "a"}, keys)
+	})
+
+	t.Run("HashSet", func(t *testing.T) {
+		s := NewHashSet[int]()
+		for i := 0; i < 1000; i++ {
+			s = s.Add(i * 7)
+		}
+		decoded := gobRoundTrip(t, s)
+		assert.Equal(t, 1000, decoded.Len())
+		assert.True(t, decoded.Contains(700))
+		assert.Equal(t, 0, gobRoundTrip(t, NewHashSet[int]()).Len())
+	})
+
+	t.Run("Multimap", func(t *testing.T) {
+		m := NewMultimap[string, int]().Put("a", 1).Put("a", 2).Put("b", 3)
+		decoded := gobRoundTrip(t, m)
+		assert.Equal(t, 3, decoded.Len())
+		assert.True(t, decoded.ContainsEntry("a", 2))
+		assert.Equal(t, 1, decoded.Count("b"))
+	})
+
+	t.Run("Bag", func(t *testing.T) {
+		b := NewBag[string]().AddN("x", 5).Add("y")
+		decoded := gobRoundTrip(t, b)
+		assert.Equal(t, 6, decoded.Len())
+		assert.Equal(t, 5, decoded.Count("x"))
+	})
+
+	t.Run("BiMap", func(t *testing.T) {
+		m := NewBiMapWithPolicy[string, int](RejectOnConflict)
+		for i := 0; i < 100; i++ {
+			var err error
+			m, err = m.Put(fmt.Sprint(i), i)
+			require.NoError(t, err)
+		}
+		decoded := gobRoundTrip(t, m)
+		assert.Equal(t, 100, decoded.Len())
+		assert.Equal(t, RejectOnConflict, decoded.Policy())
+		key, ok := decoded.GetByValue(42)
+		require.True(t, ok)
+		assert.Equal(t, "42", key)
+	})
+}
@@ -0,0 +1,258 @@
+package hashmap
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// Option настраивает HashMap при создании
+type Option[K comparable, V any] func(*HashMap[K, V])
+
+// WithSeed включает детерминированный хэш с фиксированным seed вместо случайного maphash.
+// Отображения с одинаковым содержимым и seed имеют одинаковую форму дерева и порядок обхода
+// в любом процессе, поэтому их можно сравнивать и сериализовать побайтно.
+func WithSeed[K comparable, V any](seed uint64) Option[K, V] {
+	return func(m *HashMap[K, V]) {
+		m.hashFn = func(key K) uint64 {
+			return hashKey(seed, key)
+		}
+	}
+}
+
+// WithHashFunc задаёт собственную хэш-функцию. Она должна быть детерминированной
+// и давать одинаковый хэш для равных ключей.
+func WithHashFunc[K comparable, V any](fn func(K) uint64) Option[K, V] {
+	return func(m *HashMap[K, V]) {
+		m.hashFn = fn
+	}
+}
+
+// newCollision создаёт узел коллизий на уровне shift. Записи упорядочиваются по вторичному хэшу,
+// чтобы форма дерева не зависела от порядка вставки; записи с одинаковым вторичным хэшем остаются
+// в порядке вставки. Узел на уровне hmapSecondShift, переросший collisionThreshold, сразу
+// раскладывается в поддерево по вторичному хэшу.
+func (m *HashMap[K, V]) newCollision(entries []entry[K, V], shift uint) *hmapNode[K, V] {
+	hashed := make([]hashedEntry[K, V], len(entries))
+	for i := range entries {
+		hashed[i] = hashedEntry[K, V]{hash: secondaryHash(entries[i].key), entry: &entries[i]}
+	}
+	if shift == hmapSecondShift && len(entries) > collisionThreshold {
+		return m.buildNode(hashed, shift)
+	}
+
+	slices.SortStableFunc(hashed, func(a, b hashedEntry[K, V]) int {
+		return cmp.Compare(a.hash, b.hash)
+	})
+	sorted := make([]entry[K, V], len(hashed))
+	for i, e := range hashed {
+		sorted[i] = *e.entry
+	}
+	return &hmapNode[K, V]{entries: sorted}
+}
+
+// collisionSeed - seed вторичного хэша. Он фиксирован, чтобы форма дерева оставалась детерминированной.
+const collisionSeed = 0x9e3779b97f4a7c15
+
+// secondaryHash - хэш для ключей, у которых совпал основной 64-битный хэш. Он не зависит от основной
+// хэш-функции, поэтому ключи, подобранные под неё (или под WithHashFunc), снова расходятся по дереву.
+func secondaryHash[K comparable](key K) uint64 {
+	return hashKey(collisionSeed, key)
+}
+
+// maphashKey - хэш ключа по умолчанию: maphash со случайным seed процесса.
+// Ключи без быстрого пути хэшируются по значению, как maphash.Comparable: равные ключи дают
+// равный хэш, а указатели и каналы хэшируются по адресу.
+func maphashKey[K comparable](seed maphash.Seed, key K) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+
+	switch k := any(key).(type) {
+	case string:
+		h.WriteString(k)
+	case int:
+		writeUint(&h, uint64(k), 8)
+	case int64:
+		writeUint(&h, uint64(k), 8)
+	case int32:
+		writeUint(&h, uint64(k), 4)
+	case uint:
+		writeUint(&h, uint64(k), 8)
+	case uint64:
+		writeUint(&h, k, 8)
+	case uint32:
+		writeUint(&h, uint64(k), 4)
+	default:
+		writeValue(&h, reflect.ValueOf(any(key)))
+	}
+	return h.Sum64()
+}
+
+// valueWriter - хэш, в который writeValue пишет байты ключа: *maphash.Hash или *fnvHash
+type valueWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+func writeUint(h valueWriter, x uint64, size int) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], x)
+	h.Write(buf[:size])
+}
+
+// writeValue пишет в h значение сравнимого типа так, чтобы у значений, равных по ==, совпадали байты
+func writeValue(h valueWriter, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		// nil в ключе-интерфейсе
+		h.WriteByte(0)
+	case reflect.Bool:
+		if v.Bool() {
+			h.WriteByte(1)
+		} else {
+			h.WriteByte(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint(h, uint64(v.Int()), 8)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint(h, v.Uint(), 8)
+	case reflect.Float32, reflect.Float64:
+		writeFloat(h, v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		writeFloat(h, real(c))
+		writeFloat(h, imag(c))
+	case reflect.String:
+		h.WriteString(v.String())
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Chan:
+		writeUint(h, uint64(v.Pointer()), 8)
+	case reflect.Interface:
+		writeValue(h, v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			writeValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			// пустые поля _ не участвуют в сравнении структур
+			if t.Field(i).Name != "_" {
+				writeValue(h, v.Field(i))
+			}
+		}
+	default:
+		panic(fmt.Errorf("hashmap: hash of unhashable type %s", v.Type()))
+	}
+}
+
+func writeFloat(h valueWriter, f float64) {
+	if f == 0 {
+		f = 0 // -0.0 == 0.0, поэтому у них должен быть один хэш
+	}
+	writeUint(h, math.Float64bits(f), 8)
+}
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// hashKey - детерминированный хэш ключа: FNV-1a от seed и байтов ключа с финальным перемешиванием
+// (splitmix64), чтобы младшие биты, по которым ветвится дерево, зависели от всех байтов.
+// Ключи, для которых нет быстрого пути, обходятся тем же writeValue, что и в maphashKey:
+// поля структур и элементы массивов - по значению, указатели и каналы - по адресу.
+func hashKey[K comparable](seed uint64, key K) uint64 {
+	h := uint64(fnvOffset)
+	h = fnvUint64(h, seed)
+
+	switch k := any(key).(type) {
+	case string:
+		h = fnvString(h, k)
+	case int:
+		h = fnvUint64(h, uint64(k))
+	case int64:
+		h = fnvUint64(h, uint64(k))
+	case int32:
+		h = fnvUint64(h, uint64(k))
+	case uint:
+		h = fnvUint64(h, uint64(k))
+	case uint64:
+		h = fnvUint64(h, k)
+	case uint32:
+		h = fnvUint64(h, uint64(k))
+	case float64:
+		if k == 0 {
+			k = 0 // -0.0 == 0.0, поэтому у них должен быть один хэш
+		}
+		h = fnvUint64(h, math.Float64bits(k))
+	case bool:
+		if k {
+			h = fnvUint64(h, 1)
+		} else {
+			h = fnvUint64(h, 0)
+		}
+	default:
+		f := fnvHash{sum: h}
+		writeValue(&f, reflect.ValueOf(any(key)))
+		h = f.sum
+	}
+
+	return mix64(h)
+}
+
+// fnvHash - FNV-1a как valueWriter, для ключей hashKey без быстрого пути
+type fnvHash struct {
+	sum uint64
+}
+
+func (f *fnvHash) Write(p []byte) (int, error) {
+	for _, b := range p {
+		f.sum ^= uint64(b)
+		f.sum *= fnvPrime
+	}
+	return len(p), nil
+}
+
+func (f *fnvHash) WriteByte(b byte) error {
+	f.sum ^= uint64(b)
+	f.sum *= fnvPrime
+	return nil
+}
+
+func (f *fnvHash) WriteString(s string) (int, error) {
+	f.sum = fnvString(f.sum, s)
+	return len(s), nil
+}
+
+func fnvString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime
+	}
+	return h
+}
+
+func fnvUint64(h, x uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h ^= x & 0xff
+		h *= fnvPrime
+		x >>= 8
+	}
+	return h
+}
+
+// mix64 - финализатор splitmix64
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
@@ -0,0 +1,429 @@
+package hashmap
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dumpShape описывает форму дерева: битовые карты узлов и порядок записей в них
+func dumpShape[K comparable, V any](m *HashMap[K, V]) string {
+	var sb strings.Builder
+	var walk func(node *hmapNode[K, V], depth int)
+	walk = func(node *hmapNode[K, V], depth int) {
+		node = node.resolve()
+		fmt.Fprintf(&sb, "%s%032b %032b\n", strings.Repeat(" ", depth), node.dataMap, node.nodeMap)
+		for _, e := range node.entries {
+			fmt.Fprintf(&sb, "%s%v=%v\n", strings.Repeat(" ", depth+1), e.key, e.value)
+		}
+		for _, child := range node.nodes {
+			walk(child, depth+1)
+		}
+	}
+	walk(m.root, 0)
+	return sb.String()
+}
+
+func keysInOrder[K comparable, V any](m *HashMap[K, V]) []K {
+	keys := []K{}
+	for k := range m.Keys() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestHashMap_WithSeed(t *testing.T) {
+	const size = 3000
+
+	// одно и то же содержимое, полученное разными путями: вставка в прямом и обратном порядке,
+	// лишние ключи, удалённые потом, и массовое построение из JSON
+	forward := NewHashMap(WithSeed[string, int](42))
+	for i := 0; i < size; i++ {
+		forward = forward.Set(fmt.Sprintf("key-%d", i), i)
+	}
+
+	shuffled := NewHashMap(WithSeed[string, int](42))
+	for _, i := range rand.New(rand.NewSource(1)).Perm(size + 500) {
+		shuffled = shuffled.Set(fmt.Sprintf("key-%d", i), i)
+	}
+	for i := size; i < size+500; i++ {
+		shuffled = shuffled.Delete(fmt.Sprintf("key-%d", i))
+	}
+
+	data, err := forward.MarshalJSON()
+	require.NoError(t, err)
+	decoded := NewHashMap(WithSeed[string, int](42))
+	require.NoError(t, decoded.UnmarshalJSON(data))
+
+	t.Run("одинаковая форма дерева", func(t *testing.T) {
+		require.Equal(t, size, shuffled.Len())
+		assert.Equal(t, dumpShape(forward), dumpShape(shuffled))
+		assert.Equal(t, dumpShape(forward), dumpShape(decoded))
+		assert.Equal(t, keysInOrder(forward), keysInOrder(shuffled))
+	})
+
+	t.Run("побайтно одинаковая сериализация", func(t *testing.T) {
+		a, err := forward.MarshalBinary()
+		require.NoError(t, err)
+		b, err := shuffled.MarshalBinary()
+		require.NoError(t, err)
+		assert.Equal(t, a, b)
+
+		a, err = forward.GobEncode()
+		require.NoError(t, err)
+		b, err = decoded.GobEncode()
+		require.NoError(t, err)
+		assert.Equal(t, a, b)
+	})
+
+	t.Run("декодирование сохраняет хэш получателя", func(t *testing.T) {
+		data, err := shuffled.MarshalBinary()
+		require.NoError(t, err)
+		restored := NewHashMap(WithSeed[string, int](42))
+		require.NoError(t, restored.UnmarshalBinary(data))
+		assert.Equal(t, dumpShape(forward), dumpShape(restored))
+
+		// новая версия продолжает использовать тот же хэш
+		assert.Equal(t, dumpShape(forward.Set("extra", -1)), dumpShape(restored.Set("extra", -1)))
+	})
+
+	t.Run("другой seed - другая раскладка", func(t *testing.T) {
+		other := NewHashMap(WithSeed[string, int](43))
+		for i := 0; i < size; i++ {
+			other = other.Set(fmt.Sprintf("key-%d", i), i)
+		}
+		requireHashMapEqual(t, forward, other)
+		assert.NotEqual(t, keysInOrder(forward), keysInOrder(other))
+	})
+
+	t.Run("удаление всех ключей", func(t *testing.T) {
+		empty := NewHashMap(WithSeed[string, int](42))
+		m := shuffled
+		for i := 0; i < size; i++ {
+			m = m.Delete(fmt.Sprintf("key-%d", i))
+		}
+		assert.Equal(t, dumpShape(empty), dumpShape(m))
+	})
+}
+
+func TestHashMap_WithHashFunc(t *testing.T) {
+	// младшие 32 бита у всех ключей совпадают, поэтому ключи расходятся только на нижних уровнях дерева
+	sameLow := WithHashFunc[int, int](func(k int) uint64 { return uint64(k)<<32 | 7 })
+
+	ascending := NewHashMap(sameLow)
+	for i := 0; i < 50; i++ {
+		ascending = ascending.Set(i, i)
+	}
+	descending := NewHashMap(sameLow)
+	for i := 59; i >= 0; i-- {
+		descending = descending.Set(i, i)
+	}
+	for i := 50; i < 60; i++ {
+		descending = descending.Delete(i)
+	}
+
+	assert.Equal(t, dumpShape(ascending), dumpShape(descending))
+	for i := 0; i < 50; i++ {
+		value, ok := descending.Get(i)
+		require.True(t, ok)
+		assert.Equal(t, i, value)
+	}
+
+	// цепочка узлов после удаления сворачивается обратно в одну запись у корня
+	single := ascending
+	for i := 1; i < 50; i++ {
+		single = single.Delete(i)
+	}
+	assert.Equal(t, dumpShape(NewHashMap(sameLow).Set(0, 0)), dumpShape(single))
+}
+
+func TestHashKey(t *testing.T) {
+	// значения закреплены: изменение hashKey меняет раскладку уже сохранённых файлов
+	assert.Equal(t, uint64(0x9070657ffccdd62e), hashKey(42, "hello"))
+	assert.Equal(t, uint64(0xc2bfbf68b0da73e5), hashKey(42, 12345))
+
+	assert.NotEqual(t, hashKey(1, "hello"), hashKey(2, "hello"))
+	assert.Equal(t, hashKey(0, 0.0), hashKey(0, math.Copysign(0, -1)), "равные ключи дают равный хэш")
+}
+
+// maxLinearNode возвращает размер самого большого линейного узла коллизий
+func maxLinearNode[K comparable, V any](m *HashMap[K, V]) int {
+	largest := 0
+	var walk func(node *hmapNode[K, V], shift uint)
+	walk = func(node *hmapNode[K, V], shift uint) {
+		node = node.resolve()
+		if node.linearAt(shift) {
+			largest = max(largest, len(node.entries))
+			return
+		}
+		for _, child := range node.nodes {
+			walk(child, shift+hmapShift)
+		}
+	}
+	walk(m.root, 0)
+	return largest
+}
+
+func TestHashMap_Collisions(t *testing.T) {
+	constant := WithHashFunc[int, int](func(int) uint64 { return 0xdeadbeef })
+
+	t.Run("совпадает весь основной хэш", func(t *testing.T) {
+		const size = 5000
+		m := NewHashMap(constant)
+		for i := 0; i < size; i++ {
+			m = m.Set(i, i*10)
+		}
+
+		require.Equal(t, size, m.Len())
+		assert.LessOrEqual(t, maxLinearNode(m), collisionThreshold, "большой узел коллизий должен ветвиться по вторичному хэшу")
+		for i := 0; i < size; i++ {
+			value, ok := m.Get(i)
+			require.True(t, ok, "ключ %d", i)
+			require.Equal(t, i*10, value)
+		}
+		_, ok := m.Get(size)
+		assert.False(t, ok)
+
+		count := 0
+		for range m.All() {
+			count++
+		}
+		assert.Equal(t, size, count)
+	})
+
+	t.Run("коллизии последнего уровня лежат под своей позицией", func(t *testing.T) {
+		// младшие 60 бит общие, старшие 4 бита - последний сегмент основного хэша - различаются,
+		// а ключи k и k+16 совпадают по всему хэшу
+		topBits := WithHashFunc[int, int](func(k int) uint64 { return uint64(k%16)<<hmapMaxShift | 0xabcdef })
+
+		// первыми вставляются полностью совпадающие ключи 1 и 17: узел коллизий создаётся сразу
+		// на последнем уровне и должен лежать под позицией 1, а не 0
+		m := NewHashMap(topBits)
+		for i := 1; i <= 16; i++ {
+			m = m.Set(i%16, i%16).Set(i%16+16, i%16+16)
+		}
+		for i := 0; i < 32; i++ {
+			value, ok := m.Get(i)
+			require.True(t, ok, "ключ %d", i)
+			require.Equal(t, i, value)
+		}
+		_, ok := m.Get(32)
+		assert.False(t, ok)
+
+		for i := 0; i < 32; i += 2 {
+			m = m.Delete(i)
+		}
+		for i := 0; i < 32; i++ {
+			_, ok := m.Get(i)
+			assert.Equal(t, i%2 == 1, ok, "ключ %d", i)
+		}
+	})
+
+	t.Run("форма не зависит от порядка операций", func(t *testing.T) {
+		ascending := NewHashMap(constant)
+		for i := 0; i < 300; i++ {
+			ascending = ascending.Set(i, i)
+		}
+		shuffled := NewHashMap(constant)
+		for _, i := range rand.New(rand.NewSource(3)).Perm(400) {
+			shuffled = shuffled.Set(i, i)
+		}
+		for i := 300; i < 400; i++ {
+			shuffled = shuffled.Delete(i)
+		}
+		assert.Equal(t, dumpShape(ascending), dumpShape(shuffled))
+	})
+
+	t.Run("поддерево сворачивается обратно в линейный узел", func(t *testing.T) {
+		m := NewHashMap(constant)
+		for i := 0; i < 100; i++ {
+			m = m.Set(i, i)
+		}
+		for _, i := range rand.New(rand.NewSource(4)).Perm(100)[:97] {
+			m = m.Delete(i)
+		}
+
+		expected := NewHashMap(constant)
+		for k, v := range m.All() {
+			expected = expected.Set(k, v)
+		}
+		assert.Equal(t, 3, m.Len())
+		assert.Equal(t, dumpShape(expected), dumpShape(m))
+	})
+
+	t.Run("совпадает и вторичный хэш", func(t *testing.T) {
+		// равные числа разных целых типов - разные ключи-интерфейсы, но вторичный хэш у них один:
+		// он зависит только от значения
+		keys := []any{int(7), int8(7), int16(7), int32(7), int64(7), uint(7), uint8(7), uint16(7), uint32(7), uint64(7), uintptr(7)}
+		require.Greater(t, len(keys), collisionThreshold)
+		m := NewHashMap(WithHashFunc[any, int](func(any) uint64 { return 1 }))
+		for i, k := range keys {
+			m = m.Set(k, i)
+		}
+		assert.Equal(t, len(keys), maxLinearNode(m), "ниже последнего уровня остаётся линейный список")
+
+		for i := 0; i < len(keys); i += 2 {
+			m = m.Delete(keys[i])
+		}
+		require.Equal(t, len(keys)/2, m.Len())
+		for i, k := range keys {
+			val, ok := m.Get(k)
+			assert.Equal(t, i%2 == 1, ok)
+			if ok {
+				assert.Equal(t, i, val)
+			}
+		}
+	})
+
+	t.Run("случайные операции против map", func(t *testing.T) {
+		hashers := map[string]func(int) uint64{
+			"16 значений хэша":    func(k int) uint64 { return uint64(k % 16) },
+			"различаются старшие": func(k int) uint64 { return uint64(k%64) << 58 },
+		}
+		for name, fn := range hashers {
+			t.Run(name, func(t *testing.T) {
+				r := rand.New(rand.NewSource(5))
+				m := NewHashMap(WithHashFunc[int, int](fn))
+				model := map[int]int{}
+				for i := 0; i < 20000; i++ {
+					k := r.Intn(2000)
+					if r.Intn(3) == 0 {
+						m = m.Delete(k)
+						delete(model, k)
+					} else {
+						m = m.Set(k, i)
+						model[k] = i
+					}
+				}
+
+				require.Equal(t, len(model), m.Len())
+				for k, v := range model {
+					got, ok := m.Get(k)
+					require.True(t, ok, "ключ %d", k)
+					require.Equal(t, v, got)
+				}
+				assert.LessOrEqual(t, maxLinearNode(m), collisionThreshold)
+			})
+		}
+	})
+}
+
+type point struct {
+	X, Y int
+	tag  string
+	_    int
+}
+
+type compositeKey struct {
+	P     point
+	Cells [2]float64
+	Ref   *int
+	Any   any
+}
+
+func TestHashMap_ComparableKeys(t *testing.T) {
+	t.Run("структуры и массивы", func(t *testing.T) {
+		shared := 7
+		keys := []compositeKey{
+			{P: point{X: 1, Y: 2, tag: "a"}},
+			{P: point{X: 1, Y: 2, tag: "b"}},
+			{P: point{X: 2, Y: 1}, Cells: [2]float64{0.5, 1}},
+			{Ref: &shared, Any: "строка"},
+			{Ref: &shared, Any: 42},
+			{Any: point{X: 3}},
+		}
+
+		m := NewHashMap[compositeKey, int]()
+		for i, k := range keys {
+			m = m.Set(k, i)
+		}
+		require.Equal(t, len(keys), m.Len())
+		for i, k := range keys {
+			value, ok := m.Get(k)
+			require.True(t, ok, "ключ %+v", k)
+			assert.Equal(t, i, value)
+		}
+
+		// равные по == ключи находят запись, даже если это разные экземпляры
+		value, ok := m.Get(compositeKey{Any: point{X: 3}})
+		assert.True(t, ok)
+		assert.Equal(t, 5, value)
+		other := 7
+		_, ok = m.Get(compositeKey{Ref: &other, Any: 42})
+		assert.False(t, ok, "указатели сравниваются по адресу")
+	})
+
+	t.Run("отрицательный ноль", func(t *testing.T) {
+		m := NewHashMap[[2]float64, string]().Set([2]float64{0, 1}, "ноль")
+		value, ok := m.Get([2]float64{math.Copysign(0, -1), 1})
+		assert.True(t, ok)
+		assert.Equal(t, "ноль", value)
+
+		floats := NewHashMap[float64, int]().Set(0, 1).Set(math.Copysign(0, -1), 2)
+		assert.Equal(t, 1, floats.Len())
+	})
+
+	t.Run("ключи-интерфейсы", func(t *testing.T) {
+		m := NewHashMap[any, int]().Set(nil, 0).Set(1, 1).Set("1", 2).Set(point{X: 1}, 3)
+		for key, expected := range map[any]int{nil: 0, 1: 1, "1": 2, point{X: 1}: 3} {
+			value, ok := m.Get(key)
+			require.True(t, ok, "ключ %v", key)
+			assert.Equal(t, expected, value)
+		}
+	})
+
+	t.Run("детерминированный хэш", func(t *testing.T) {
+		x := 1
+		pointers := NewHashMap(WithSeed[*int, int](1)).Set(&x, 1)
+		x = 2
+		_, ok := pointers.Get(&x)
+		assert.True(t, ok, "указатель хэшируется по адресу, а не по значению под ним")
+
+		a, b := make(chan int), make(chan int)
+		channels := NewHashMap(WithSeed[chan int, int](1)).Set(a, 1).Set(b, 2)
+		value, ok := channels.Get(b)
+		assert.True(t, ok)
+		assert.Equal(t, 2, value)
+
+		// у ключей нет экспортированных полей, но хэшируются они всё равно по значению
+		type opaque struct{ n int }
+		primary, secondary := map[uint64]bool{}, map[uint64]bool{}
+		for i := 0; i < 2000; i++ {
+			primary[hashKey(1, opaque{i})] = true
+			secondary[secondaryHash(opaque{i})] = true
+		}
+		assert.Len(t, primary, 2000)
+		assert.Len(t, secondary, 2000)
+	})
+
+	t.Run("производные структуры", func(t *testing.T) {
+		a, b := point{X: 1, Y: 2}, point{X: 2, Y: 1}
+
+		sharded := NewShardedHashMap[point, int]().Set(a, 1).Set(b, 2)
+		value, ok := sharded.Get(point{X: 1, Y: 2})
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+
+		bimap, err := NewBiMap[point, point]().Put(a, b)
+		require.NoError(t, err)
+		key, ok := bimap.GetByValue(point{X: 2, Y: 1})
+		assert.True(t, ok)
+		assert.Equal(t, a, key)
+
+		bag := NewBag[point]().Add(a).Add(a).Add(b)
+		assert.Equal(t, 2, bag.Count(point{X: 1, Y: 2}))
+
+		multimap := NewMultimap[point, int]().Put(a, 1).Put(a, 2)
+		assert.Equal(t, 2, multimap.Count(point{X: 1, Y: 2}))
+
+		ordered := NewOrderedMap[point, string]().Set(b, "b").Set(a, "a")
+		got, ok := ordered.Get(point{X: 1, Y: 2})
+		assert.True(t, ok)
+		assert.Equal(t, "a", got)
+	})
+}
@@ -4,11 +4,22 @@ import (
 	"hash/maphash"
 	"iter"
 	"math/bits"
+
+	pds "github.com/ykhdr/persistent-data-structures"
 )
 
 const (
 	hmapShift = 5
 	hmapMask  = 31
+	// hmapMaxShift - сдвиг последнего уровня по основному 64-битному хэшу (на нём остаются 4 бита)
+	hmapMaxShift = 60
+	// hmapSecondShift - уровень узлов коллизий. Узел коллизий, в котором больше collisionThreshold записей,
+	// становится поддеревом по вторичному хэшу: его уровни отсчитываются от hmapSecondShift.
+	hmapSecondShift = hmapMaxShift + hmapShift
+	// hmapLastShift - последний уровень по вторичному хэшу: ниже лежат только линейные узлы коллизий
+	hmapLastShift = hmapSecondShift + hmapMaxShift
+	// collisionThreshold - максимальный размер линейного узла коллизий на уровне hmapSecondShift
+	collisionThreshold = 8
 )
 
 type entry[K comparable, V any] struct {
@@ -16,39 +27,137 @@ type entry[K comparable, V any] struct {
 	value V
 }
 
-type collision[K comparable, V any] struct {
+// hmapNode - узел CHAMP. Каждая позиция (5-битный сегмент хэша) занята либо записью, либо поддеревом:
+// dataMap отмечает позиции записей, nodeMap - позиции поддеревьев. Записи лежат прямо в узле
+// в порядке позиций, поддеревья - в отдельном срезе, поэтому поиск и обход обходятся без type switch.
+//
+// Узел ниже последнего уровня основного хэша - узел коллизий: битовые карты пусты, а entries хранит
+// записи с одинаковым 64-битным хэшем. Большой узел коллизий заменяется поддеревом по вторичному хэшу
+// (см. hmapSecondShift), поэтому поиск не вырождается в линейный даже при подобранных ключах. Срезы узла после создания не изменяются, поэтому копия узла может разделять
+// с оригиналом тот срез, который операция не меняет.
+type hmapNode[K comparable, V any] struct {
+	dataMap uint32
+	nodeMap uint32
 	entries []entry[K, V]
+	nodes   []*hmapNode[K, V]
+	lazy    *hmapLazy[K, V] // не nil у заглушки узла, который ещё не загружен с диска
 }
 
-type hmapNode[K comparable, V any] struct {
-	bitmap   uint32
-	children []any
+// hmapLazy - ссылка на узел, лежащий вне памяти
+type hmapLazy[K comparable, V any] struct {
+	loader hmapLoader[K, V]
+	ref    uint64
 }
 
-func (n *hmapNode[K, V]) index(bit uint32) int {
-	return bits.OnesCount32(n.bitmap & (bit - 1))
+// hmapLoader - источник узлов, которых нет в памяти. У отображения в памяти loader равен nil.
+type hmapLoader[K comparable, V any] interface {
+	// loadNode возвращает узел по ссылке; поддеревья загруженного узла - снова заглушки
+	loadNode(ref uint64) *hmapNode[K, V]
+	// spillNode записывает новый узел вместе с его новыми потомками и возвращает заглушку.
+	// Loader, который записывает узлы только по явному Save (HashMapStore), возвращает сам узел.
+	spillNode(node *hmapNode[K, V]) *hmapNode[K, V]
 }
 
-func (n *hmapNode[K, V]) clone() *hmapNode[K, V] {
-	newChildren := make([]any, len(n.children))
-	copy(newChildren, n.children)
-	return &hmapNode[K, V]{
-		bitmap:   n.bitmap,
-		children: newChildren,
+// resolve возвращает резидентный узел: для заглушки он подгружается через loader
+func (n *hmapNode[K, V]) resolve() *hmapNode[K, V] {
+	if n.lazy == nil {
+		return n
+	}
+	return n.lazy.loader.loadNode(n.lazy.ref)
+}
+
+func (n *hmapNode[K, V]) dataIndex(bit uint32) int {
+	return bits.OnesCount32(n.dataMap & (bit - 1))
+}
+
+func (n *hmapNode[K, V]) nodeIndex(bit uint32) int {
+	return bits.OnesCount32(n.nodeMap & (bit - 1))
+}
+
+// isCollision сообщает, что узел - линейный список записей с одинаковым хэшем
+func (n *hmapNode[K, V]) isCollision() bool {
+	return n.dataMap == 0 && n.nodeMap == 0 && len(n.entries) > 0
+}
+
+// bitAt возвращает бит позиции хэша на уровне shift. Уровни вторичного хэша отсчитываются от hmapSecondShift.
+func bitAt(hash uint64, shift uint) uint32 {
+	if shift >= hmapSecondShift {
+		shift -= hmapSecondShift
 	}
+	return uint32(1) << ((hash >> shift) & hmapMask)
+}
+
+// linearAt сообщает, что узел на уровне shift хранит записи списком
+func (n *hmapNode[K, V]) linearAt(shift uint) bool {
+	return shift > hmapLastShift || shift > hmapMaxShift && n.isCollision()
+}
+
+// singleEntry сообщает, что поддерево состоит из одной записи и должно быть встроено в родителя
+func (n *hmapNode[K, V]) singleEntry() bool {
+	return len(n.nodes) == 0 && len(n.entries) == 1
+}
+
+func insertAt[T any](s []T, i int, x T) []T {
+	out := make([]T, len(s)+1)
+	copy(out, s[:i])
+	out[i] = x
+	copy(out[i+1:], s[i:])
+	return out
+}
+
+func removeAt[T any](s []T, i int) []T {
+	if len(s) == 1 {
+		return nil
+	}
+	out := make([]T, len(s)-1)
+	copy(out, s[:i])
+	copy(out[i:], s[i+1:])
+	return out
+}
+
+func replaceAt[T any](s []T, i int, x T) []T {
+	out := make([]T, len(s))
+	copy(out, s)
+	out[i] = x
+	return out
 }
 
 type HashMap[K comparable, V any] struct {
 	root *hmapNode[K, V]
 	len  int
 	seed maphash.Seed
+	// hashFn заменяет maphash, если раскладка дерева должна быть одинаковой между запусками
+	// (WithSeed, WithHashFunc, HashMapFile)
+	hashFn func(K) uint64
+	// loader не nil у отображения, узлы которого хранятся на диске
+	loader hmapLoader[K, V]
 }
 
-func NewHashMap[K comparable, V any]() *HashMap[K, V] {
-	return &HashMap[K, V]{
+var _ pds.Map[string, int, *HashMap[string, int]] = (*HashMap[string, int])(nil)
+
+func NewHashMap[K comparable, V any](opts ...Option[K, V]) *HashMap[K, V] {
+	m := &HashMap[K, V]{
 		root: &hmapNode[K, V]{},
 		len:  0,
-		seed: maphash.MakeSeed(),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.hashFn == nil {
+		m.seed = maphash.MakeSeed()
+	}
+	return m
+}
+
+// emptyLike возвращает пустое отображение в памяти с той же хэш-функцией, что и у m
+func (m *HashMap[K, V]) emptyLike() *HashMap[K, V] {
+	if m.hashFn == nil && m.seed == (maphash.Seed{}) {
+		return NewHashMap[K, V]()
+	}
+	return &HashMap[K, V]{
+		root:   &hmapNode[K, V]{},
+		seed:   m.seed,
+		hashFn: m.hashFn,
 	}
 }
 
@@ -56,52 +165,30 @@ func (m *HashMap[K, V]) Len() int {
 	return m.len
 }
 
-func (m *HashMap[K, V]) hash(key K) uint32 {
-	var h maphash.Hash
-	h.SetSeed(m.seed)
-	switch k := any(key).(type) {
-	case string:
-		h.WriteString(k)
-	case int:
-		var buf [8]byte
-		for i := 0; i < 8; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	case int64:
-		var buf [8]byte
-		for i := 0; i < 8; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	case int32:
-		var buf [4]byte
-		for i := 0; i < 4; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	case uint:
-		var buf [8]byte
-		for i := 0; i < 8; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	case uint64:
-		var buf [8]byte
-		for i := 0; i < 8; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	case uint32:
-		var buf [4]byte
-		for i := 0; i < 4; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	default:
-		h.WriteString(any(key).(string))
+// spill сбрасывает новые узлы версии next на диск, если отображение хранится на диске
+func (m *HashMap[K, V]) spill(next *HashMap[K, V]) *HashMap[K, V] {
+	next.hashFn = m.hashFn
+	next.loader = m.loader
+	if m.loader != nil {
+		next.root = m.loader.spillNode(next.root)
+	}
+	return next
+}
+
+func (m *HashMap[K, V]) hash(key K) uint64 {
+	if m.hashFn != nil {
+		return m.hashFn(key)
+	}
+
+	return maphashKey(m.seed, key)
+}
+
+// hashAt возвращает хэш, по которому ключ ветвится на уровне shift: основной или вторичный
+func (m *HashMap[K, V]) hashAt(key K, shift uint) uint64 {
+	if shift > hmapMaxShift {
+		return secondaryHash(key)
 	}
-	return uint32(h.Sum64())
+	return m.hash(key)
 }
 
 func (m *HashMap[K, V]) Get(key K) (V, bool) {
@@ -114,34 +201,37 @@ func (m *HashMap[K, V]) Get(key K) (V, bool) {
 	return m.getNode(m.root, key, hash, 0)
 }
 
-func (m *HashMap[K, V]) getNode(node *hmapNode[K, V], key K, hash uint32, shift uint) (V, bool) {
-	var zero V
-	bit := uint32(1) << ((hash >> shift) & hmapMask)
-
-	if node.bitmap&bit == 0 {
-		return zero, false
-	}
-
-	idx := node.index(bit)
-	child := node.children[idx]
-
-	switch c := child.(type) {
-	case *hmapNode[K, V]:
-		return m.getNode(c, key, hash, shift+hmapShift)
-	case *entry[K, V]:
-		if c.key == key {
-			return c.value, true
+func (m *HashMap[K, V]) getNode(node *hmapNode[K, V], key K, hash uint64, shift uint) (V, bool) {
+	for {
+		node = node.resolve()
+		if node.linearAt(shift) {
+			for _, e := range node.entries {
+				if e.key == key {
+					return e.value, true
+				}
+			}
+			break
 		}
-		return zero, false
-	case *collision[K, V]:
-		for _, e := range c.entries {
+		if shift == hmapSecondShift {
+			hash = secondaryHash(key)
+		}
+
+		bit := bitAt(hash, shift)
+		if node.dataMap&bit != 0 {
+			e := &node.entries[node.dataIndex(bit)]
 			if e.key == key {
 				return e.value, true
 			}
+			break
 		}
-		return zero, false
+		if node.nodeMap&bit == 0 {
+			break
+		}
+		node = node.nodes[node.nodeIndex(bit)]
+		shift += hmapShift
 	}
 
+	var zero V
 	return zero, false
 }
 
@@ -154,121 +244,83 @@ func (m *HashMap[K, V]) Set(key K, value V) *HashMap[K, V] {
 		newLen++
 	}
 
-	return &HashMap[K, V]{
+	return m.spill(&HashMap[K, V]{
 		root: newRoot,
 		len:  newLen,
 		seed: m.seed,
-	}
+	})
 }
 
-func (m *HashMap[K, V]) setNode(node *hmapNode[K, V], key K, value V, hash uint32, shift uint) (*hmapNode[K, V], bool) {
-	bit := uint32(1) << ((hash >> shift) & hmapMask)
-	idx := node.index(bit)
+func (m *HashMap[K, V]) setNode(node *hmapNode[K, V], key K, value V, hash uint64, shift uint) (*hmapNode[K, V], bool) {
+	node = node.resolve()
 
-	if node.bitmap&bit == 0 {
-		newNode := node.clone()
-		newNode.bitmap |= bit
-		newChildren := make([]any, len(node.children)+1)
-		copy(newChildren[:idx], node.children[:idx])
-		newChildren[idx] = &entry[K, V]{key: key, value: value}
-		copy(newChildren[idx+1:], node.children[idx:])
-		newNode.children = newChildren
-		return newNode, true
+	if node.linearAt(shift) {
+		for i, e := range node.entries {
+			if e.key == key {
+				return &hmapNode[K, V]{entries: replaceAt(node.entries, i, entry[K, V]{key: key, value: value})}, false
+			}
+		}
+		return m.newCollision(insertAt(node.entries, len(node.entries), entry[K, V]{key: key, value: value}), shift), true
+	}
+	if shift == hmapSecondShift {
+		hash = secondaryHash(key)
 	}
 
-	child := node.children[idx]
-	newNode := node.clone()
-
-	switch c := child.(type) {
-	case *hmapNode[K, V]:
-		newChild, added := m.setNode(c, key, value, hash, shift+hmapShift)
-		newNode.children[idx] = newChild
-		return newNode, added
+	bit := bitAt(hash, shift)
+	newNode := &hmapNode[K, V]{dataMap: node.dataMap, nodeMap: node.nodeMap, entries: node.entries, nodes: node.nodes}
 
-	case *entry[K, V]:
-		if c.key == key {
-			newNode.children[idx] = &entry[K, V]{key: key, value: value}
+	switch {
+	case node.dataMap&bit != 0:
+		idx := node.dataIndex(bit)
+		existing := node.entries[idx]
+		if existing.key == key {
+			newNode.entries = replaceAt(node.entries, idx, entry[K, V]{key: key, value: value})
 			return newNode, false
 		}
 
-		existingHash := m.hash(c.key)
-
-		if shift >= 30 {
-			newNode.children[idx] = &collision[K, V]{
-				entries: []entry[K, V]{
-					{key: c.key, value: c.value},
-					{key: key, value: value},
-				},
-			}
-			return newNode, true
-		}
-
-		newChild := m.createTwoEntryNode(c.key, c.value, existingHash, key, value, hash, shift+hmapShift)
-		newNode.children[idx] = newChild
+		// позиция занята другим ключом: обе записи уходят в новое поддерево
+		child := m.mergeEntries(existing, m.hashAt(existing.key, shift), entry[K, V]{key: key, value: value}, hash, shift+hmapShift)
+		newNode.dataMap &^= bit
+		newNode.entries = removeAt(node.entries, idx)
+		newNode.nodeMap |= bit
+		newNode.nodes = insertAt(node.nodes, node.nodeIndex(bit), child)
 		return newNode, true
 
-	case *collision[K, V]:
-		for i, e := range c.entries {
-			if e.key == key {
-				newEntries := make([]entry[K, V], len(c.entries))
-				copy(newEntries, c.entries)
-				newEntries[i] = entry[K, V]{key: key, value: value}
-				newNode.children[idx] = &collision[K, V]{entries: newEntries}
-				return newNode, false
-			}
-		}
-		newEntries := make([]entry[K, V], len(c.entries)+1)
-		copy(newEntries, c.entries)
-		newEntries[len(c.entries)] = entry[K, V]{key: key, value: value}
-		newNode.children[idx] = &collision[K, V]{entries: newEntries}
+	case node.nodeMap&bit != 0:
+		idx := node.nodeIndex(bit)
+		child, added := m.setNode(node.nodes[idx], key, value, hash, shift+hmapShift)
+		newNode.nodes = replaceAt(node.nodes, idx, child)
+		return newNode, added
+
+	default:
+		newNode.dataMap |= bit
+		newNode.entries = insertAt(node.entries, node.dataIndex(bit), entry[K, V]{key: key, value: value})
 		return newNode, true
 	}
-
-	return newNode, false
 }
 
-func (m *HashMap[K, V]) createTwoEntryNode(key1 K, val1 V, hash1 uint32, key2 K, val2 V, hash2 uint32, shift uint) *hmapNode[K, V] {
-	if shift >= 30 {
-		return &hmapNode[K, V]{
-			bitmap: 1,
-			children: []any{
-				&collision[K, V]{
-					entries: []entry[K, V]{
-						{key: key1, value: val1},
-						{key: key2, value: val2},
-					},
-				},
-			},
-		}
+// mergeEntries строит поддерево из двух записей с разными ключами, начиная с уровня shift
+func (m *HashMap[K, V]) mergeEntries(e1 entry[K, V], hash1 uint64, e2 entry[K, V], hash2 uint64, shift uint) *hmapNode[K, V] {
+	if shift == hmapSecondShift || shift > hmapLastShift {
+		return m.newCollision([]entry[K, V]{e1, e2}, shift)
 	}
 
-	bit1 := uint32(1) << ((hash1 >> shift) & hmapMask)
-	bit2 := uint32(1) << ((hash2 >> shift) & hmapMask)
+	bit1 := bitAt(hash1, shift)
+	bit2 := bitAt(hash2, shift)
 
 	if bit1 == bit2 {
-		child := m.createTwoEntryNode(key1, val1, hash1, key2, val2, hash2, shift+hmapShift)
 		return &hmapNode[K, V]{
-			bitmap:   bit1,
-			children: []any{child},
+			nodeMap: bit1,
+			nodes:   []*hmapNode[K, V]{m.mergeEntries(e1, hash1, e2, hash2, shift+hmapShift)},
 		}
 	}
 
-	if bit1 < bit2 {
-		return &hmapNode[K, V]{
-			bitmap: bit1 | bit2,
-			children: []any{
-				&entry[K, V]{key: key1, value: val1},
-				&entry[K, V]{key: key2, value: val2},
-			},
-		}
+	if bit1 > bit2 {
+		e1, e2 = e2, e1
 	}
-
 	return &hmapNode[K, V]{
-		bitmap: bit1 | bit2,
-		children: []any{
-			&entry[K, V]{key: key2, value: val2},
-			&entry[K, V]{key: key1, value: val1},
-		},
+		dataMap: bit1 | bit2,
+		entries: []entry[K, V]{e1, e2},
 	}
 }
 
@@ -284,93 +336,82 @@ func (m *HashMap[K, V]) Delete(key K) *HashMap[K, V] {
 		return m
 	}
 
-	return &HashMap[K, V]{
+	return m.spill(&HashMap[K, V]{
 		root: newRoot,
 		len:  m.len - 1,
 		seed: m.seed,
-	}
+	})
 }
 
-func (m *HashMap[K, V]) deleteNode(node *hmapNode[K, V], key K, hash uint32, shift uint) (*hmapNode[K, V], bool) {
-	bit := uint32(1) << ((hash >> shift) & hmapMask)
+// deleteNode удаляет ключ и поддерживает каноническую форму: поддерево, в котором осталась одна запись,
+// встраивается в родителя. Поэтому форма дерева зависит только от набора ключей, а не от истории операций.
+func (m *HashMap[K, V]) deleteNode(node *hmapNode[K, V], key K, hash uint64, shift uint) (*hmapNode[K, V], bool) {
+	node = node.resolve()
 
-	if node.bitmap&bit == 0 {
+	if node.linearAt(shift) {
+		for i, e := range node.entries {
+			if e.key == key {
+				return &hmapNode[K, V]{entries: removeAt(node.entries, i)}, true
+			}
+		}
 		return node, false
 	}
+	if shift == hmapSecondShift {
+		hash = secondaryHash(key)
+	}
 
-	idx := node.index(bit)
-	child := node.children[idx]
+	bit := bitAt(hash, shift)
+	newNode := &hmapNode[K, V]{dataMap: node.dataMap, nodeMap: node.nodeMap, entries: node.entries, nodes: node.nodes}
 
-	switch c := child.(type) {
-	case *hmapNode[K, V]:
-		newChild, deleted := m.deleteNode(c, key, hash, shift+hmapShift)
-		if !deleted {
+	switch {
+	case node.dataMap&bit != 0:
+		idx := node.dataIndex(bit)
+		if node.entries[idx].key != key {
 			return node, false
 		}
+		newNode.dataMap &^= bit
+		newNode.entries = removeAt(node.entries, idx)
 
-		newNode := node.clone()
-
-		if newChild.bitmap == 0 {
-			newNode.bitmap &^= bit
-			newChildren := make([]any, len(node.children)-1)
-			copy(newChildren[:idx], node.children[:idx])
-			copy(newChildren[idx:], node.children[idx+1:])
-			newNode.children = newChildren
-		} else if len(newChild.children) == 1 {
-			if e, ok := newChild.children[0].(*entry[K, V]); ok {
-				newNode.children[idx] = e
-			} else {
-				newNode.children[idx] = newChild
-			}
-		} else {
-			newNode.children[idx] = newChild
-		}
-
-		return newNode, true
-
-	case *entry[K, V]:
-		if c.key != key {
+	case node.nodeMap&bit != 0:
+		idx := node.nodeIndex(bit)
+		child, deleted := m.deleteNode(node.nodes[idx], key, hash, shift+hmapShift)
+		if !deleted {
 			return node, false
 		}
 
-		newNode := node.clone()
-		newNode.bitmap &^= bit
-		newChildren := make([]any, len(node.children)-1)
-		copy(newChildren[:idx], node.children[:idx])
-		copy(newChildren[idx:], node.children[idx+1:])
-		newNode.children = newChildren
-
-		return newNode, true
-
-	case *collision[K, V]:
-		foundIdx := -1
-		for i, e := range c.entries {
-			if e.key == key {
-				foundIdx = i
-				break
-			}
+		if child.singleEntry() {
+			newNode.nodeMap &^= bit
+			newNode.nodes = removeAt(node.nodes, idx)
+			newNode.dataMap |= bit
+			newNode.entries = insertAt(node.entries, node.dataIndex(bit), child.entries[0])
+		} else {
+			newNode.nodes = replaceAt(node.nodes, idx, child)
 		}
 
-		if foundIdx == -1 {
-			return node, false
-		}
+	default:
+		return node, false
+	}
 
-		newNode := node.clone()
+	if shift == hmapSecondShift && countEntries(newNode, collisionThreshold+1) <= collisionThreshold {
+		// поддерево по вторичному хэшу снова помещается в линейный узел коллизий
+		entries := make([]entry[K, V], 0, collisionThreshold)
+		collectEntries(newNode, func(e *entry[K, V]) { entries = append(entries, *e) })
+		return m.newCollision(entries, shift), true
+	}
+	return newNode, true
+}
 
-		if len(c.entries) == 2 {
-			remaining := c.entries[1-foundIdx]
-			newNode.children[idx] = &entry[K, V]{key: remaining.key, value: remaining.value}
-		} else {
-			newEntries := make([]entry[K, V], len(c.entries)-1)
-			copy(newEntries[:foundIdx], c.entries[:foundIdx])
-			copy(newEntries[foundIdx:], c.entries[foundIdx+1:])
-			newNode.children[idx] = &collision[K, V]{entries: newEntries}
+// countEntries считает записи поддерева, но не больше limit
+func countEntries[K comparable, V any](node *hmapNode[K, V], limit int) int {
+	node = node.resolve()
+	count := len(node.entries)
+	for _, child := range node.nodes {
+		if count >= limit {
+			break
 		}
-
-		return newNode, true
+		count += countEntries(child, limit-count)
 	}
-
-	return node, false
+	return count
 }
 
 func (m *HashMap[K, V]) Contains(key K) bool {
@@ -388,22 +429,15 @@ func (m *HashMap[K, V]) All() iter.Seq2[K, V] {
 }
 
 func (m *HashMap[K, V]) iterNode(node *hmapNode[K, V], yield func(K, V) bool) bool {
-	for _, child := range node.children {
-		switch c := child.(type) {
-		case *hmapNode[K, V]:
-			if !m.iterNode(c, yield) {
-				return false
-			}
-		case *entry[K, V]:
-			if !yield(c.key, c.value) {
-				return false
-			}
-		case *collision[K, V]:
-			for _, e := range c.entries {
-				if !yield(e.key, e.value) {
-					return false
-				}
-			}
+	node = node.resolve()
+	for _, e := range node.entries {
+		if !yield(e.key, e.value) {
+			return false
+		}
+	}
+	for _, child := range node.nodes {
+		if !m.iterNode(child, yield) {
+			return false
 		}
 	}
 	return true
@@ -428,3 +462,74 @@ func (m *HashMap[K, V]) Values() iter.Seq[V] {
 		}
 	}
 }
+
+// Equal сообщает, что отображения содержат одинаковые пары ключ-значение
+func Equal[K comparable, V comparable](a, b *HashMap[K, V]) bool {
+	return EqualFunc(a, b, func(x, y V) bool { return x == y })
+}
+
+// EqualFunc сравнивает отображения, используя eq для значений.
+// Форма дерева каноническая, поэтому версии с общим хэшем сравниваются параллельным обходом,
+// а совпадающие по указателю поддеревья пропускаются без обхода.
+func EqualFunc[K comparable, V any](a, b *HashMap[K, V], eq func(V, V) bool) bool {
+	if a.len != b.len {
+		return false
+	}
+	if equalNodes(a.root, b.root, eq) {
+		return true
+	}
+	if a.hashFn == nil && b.hashFn == nil && a.seed == b.seed {
+		return false
+	}
+
+	// хэш-функции могут различаться, и тогда одинаковое содержимое разложено по-разному
+	for k, v := range a.All() {
+		other, ok := b.Get(k)
+		if !ok || !eq(v, other) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalNodes[K comparable, V any](a, b *hmapNode[K, V], eq func(V, V) bool) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	a, b = a.resolve(), b.resolve()
+	if a.dataMap != b.dataMap || a.nodeMap != b.nodeMap || len(a.entries) != len(b.entries) {
+		return false
+	}
+
+	if a.isCollision() {
+		// порядок записей коллизии зависит от порядка вставки
+		for _, x := range a.entries {
+			found := false
+			for _, y := range b.entries {
+				if x.key == y.key {
+					found = eq(x.value, y.value)
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+
+	for i := range a.entries {
+		if a.entries[i].key != b.entries[i].key || !eq(a.entries[i].value, b.entries[i].value) {
+			return false
+		}
+	}
+	for i := range a.nodes {
+		if !equalNodes(a.nodes[i], b.nodes[i], eq) {
+			return false
+		}
+	}
+	return true
+}
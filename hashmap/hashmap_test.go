@@ -5,12 +5,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/ykhdr/persistent-data-structures/array"
 )
 
 func TestHashMap_SetAndGet(t *testing.T) {
 	t.Run("добавление и получение элементов", func(t *testing.T) {
-		m := NewHashMap[string, int]()
+		m := newTestHashMap[string, int](t)
 
 		m = m.Set("one", 1)
 		m = m.Set("two", 2)
@@ -32,9 +31,32 @@ func TestHashMap_SetAndGet(t *testing.T) {
 	})
 }
 
+func TestHashMap_LastLevel(t *testing.T) {
+	m := NewHashMap[string, int]()
+	// первые 60 бит совпадают, поэтому ключи расходятся только на последнем уровне основного хэша (shift 60)
+	cases := map[string][2]uint64{
+		"разные старшие биты":             {0x1000000000000123, 0x2000000000000123},
+		"совпадение всего хэша не в нуле": {0xf000000000000123, 0xf000000000000123},
+	}
+
+	for name, hashes := range cases {
+		t.Run(name, func(t *testing.T) {
+			root := m.mergeEntries(entry[string, int]{key: "a", value: 1}, hashes[0], entry[string, int]{key: "b", value: 2}, hashes[1], 0)
+
+			val, ok := m.getNode(root, "a", hashes[0], 0)
+			require.True(t, ok, "ключ 'a' должен находиться по своему хэшу")
+			assert.Equal(t, 1, val)
+
+			val, ok = m.getNode(root, "b", hashes[1], 0)
+			require.True(t, ok, "ключ 'b' должен находиться по своему хэшу")
+			assert.Equal(t, 2, val)
+		})
+	}
+}
+
 func TestHashMap_GetMissing(t *testing.T) {
 	t.Run("получение несуществующего ключа", func(t *testing.T) {
-		m := NewHashMap[string, int]().Set("one", 1)
+		m := newTestHashMap[string, int](t).Set("one", 1)
 
 		_, ok := m.Get("two")
 
@@ -44,7 +66,7 @@ func TestHashMap_GetMissing(t *testing.T) {
 
 func TestHashMap_Overwrite(t *testing.T) {
 	t.Run("перезапись существующего ключа", func(t *testing.T) {
-		m1 := NewHashMap[string, int]().Set("key", 1)
+		m1 := newTestHashMap[string, int](t).Set("key", 1)
 		m2 := m1.Set("key", 100)
 
 		val1, _ := m1.Get("key")
@@ -59,7 +81,7 @@ func TestHashMap_Overwrite(t *testing.T) {
 
 func TestHashMap_Persistence(t *testing.T) {
 	t.Run("Set создаёт новую версию", func(t *testing.T) {
-		m1 := NewHashMap[string, int]()
+		m1 := newTestHashMap[string, int](t)
 		m2 := m1.Set("a", 1)
 		m3 := m2.Set("b", 2)
 		m4 := m3.Set("c", 3)
@@ -71,7 +93,7 @@ func TestHashMap_Persistence(t *testing.T) {
 	})
 
 	t.Run("старые версии не содержат новых ключей", func(t *testing.T) {
-		m1 := NewHashMap[string, int]()
+		m1 := newTestHashMap[string, int](t)
 		m2 := m1.Set("a", 1)
 		m3 := m2.Set("b", 2)
 
@@ -82,7 +104,7 @@ func TestHashMap_Persistence(t *testing.T) {
 
 func TestHashMap_Delete(t *testing.T) {
 	t.Run("удаление существующего ключа", func(t *testing.T) {
-		m := NewHashMap[string, int]().Set("a", 1).Set("b", 2).Set("c", 3)
+		m := newTestHashMap[string, int](t).Set("a", 1).Set("b", 2).Set("c", 3)
 
 		m2 := m.Delete("b")
 
@@ -93,7 +115,7 @@ func TestHashMap_Delete(t *testing.T) {
 	})
 
 	t.Run("остальные ключи сохраняются после удаления", func(t *testing.T) {
-		m := NewHashMap[string, int]().Set("a", 1).Set("b", 2).Set("c", 3)
+		m := newTestHashMap[string, int](t).Set("a", 1).Set("b", 2).Set("c", 3)
 		m2 := m.Delete("b")
 
 		val, ok := m2.Get("a")
@@ -106,7 +128,7 @@ func TestHashMap_Delete(t *testing.T) {
 	})
 
 	t.Run("удаление несуществующего ключа", func(t *testing.T) {
-		m := NewHashMap[string, int]().Set("a", 1)
+		m := newTestHashMap[string, int](t).Set("a", 1)
 
 		m2 := m.Delete("nonexistent")
 
@@ -116,7 +138,7 @@ func TestHashMap_Delete(t *testing.T) {
 
 func TestHashMap_IntKeys(t *testing.T) {
 	t.Run("целочисленные ключи", func(t *testing.T) {
-		m := NewHashMap[int, string]()
+		m := newTestHashMap[int, string](t)
 
 		for i := 0; i < 100; i++ {
 			m = m.Set(i, string(rune('a'+i%26)))
@@ -135,7 +157,7 @@ func TestHashMap_IntKeys(t *testing.T) {
 
 func TestHashMap_LargeDataset(t *testing.T) {
 	t.Run("большой набор данных", func(t *testing.T) {
-		m := NewHashMap[int, int]()
+		m := newTestHashMap[int, int](t)
 
 		for i := 0; i < 10000; i++ {
 			m = m.Set(i, i*2)
@@ -153,7 +175,7 @@ func TestHashMap_LargeDataset(t *testing.T) {
 
 func TestHashMap_Iterator(t *testing.T) {
 	t.Run("итерация по всем парам", func(t *testing.T) {
-		m := NewHashMap[string, int]().Set("a", 1).Set("b", 2).Set("c", 3)
+		m := newTestHashMap[string, int](t).Set("a", 1).Set("b", 2).Set("c", 3)
 
 		count := 0
 		sum := 0
@@ -169,7 +191,7 @@ func TestHashMap_Iterator(t *testing.T) {
 
 func TestHashMap_Keys(t *testing.T) {
 	t.Run("итерация по ключам", func(t *testing.T) {
-		m := NewHashMap[string, int]().Set("a", 1).Set("b", 2)
+		m := newTestHashMap[string, int](t).Set("a", 1).Set("b", 2)
 
 		keys := make(map[string]bool)
 		for k := range m.Keys() {
@@ -184,7 +206,7 @@ func TestHashMap_Keys(t *testing.T) {
 
 func TestHashMap_Values(t *testing.T) {
 	t.Run("итерация по значениям", func(t *testing.T) {
-		m := NewHashMap[string, int]().Set("a", 1).Set("b", 2)
+		m := newTestHashMap[string, int](t).Set("a", 1).Set("b", 2)
 
 		sum := 0
 		count := 0
@@ -198,25 +220,78 @@ func TestHashMap_Values(t *testing.T) {
 	})
 }
 
-func TestHashMap_NestedStructures(t *testing.T) {
-	t.Run("хранение векторов в качестве значений", func(t *testing.T) {
-		inner := array.NewVector[int]().Append(1).Append(2).Append(3)
-		m := NewHashMap[string, *array.Vector[int]]().Set("numbers", inner)
+func TestHashMap_Contains(t *testing.T) {
+	t.Run("проверка наличия ключа", func(t *testing.T) {
+		m := newTestHashMap[string, int](t).Set("exists", 1)
+
+		assert.True(t, m.Contains("exists"), "Contains должен вернуть true для существующего ключа")
+		assert.False(t, m.Contains("missing"), "Contains должен вернуть false для отсутствующего ключа")
+	})
+}
+
+func TestHashMap_CanonicalDelete(t *testing.T) {
+	base := newTestHashMap[int, int](t)
+	for i := 0; i < 2000; i++ {
+		base = base.Set(i, i)
+	}
 
-		retrieved, ok := m.Get("numbers")
-		require.True(t, ok, "ключ 'numbers' должен существовать")
+	t.Run("вставка и удаление возвращают исходную форму", func(t *testing.T) {
+		m := base
+		for i := 2000; i < 3000; i++ {
+			m = m.Set(i, i)
+		}
+		for i := 2999; i >= 2000; i-- {
+			m = m.Delete(i)
+		}
+		assert.Equal(t, dumpShape(base), dumpShape(m))
+	})
 
-		val, _ := retrieved.Get(0)
-		assert.Equal(t, 1, val, "первый элемент вложенного вектора должен быть 1")
-		assert.Equal(t, 3, retrieved.Len(), "вложенный вектор должен содержать 3 элемента")
+	t.Run("поддерево с одной записью встраивается в родителя", func(t *testing.T) {
+		m := base
+		for i := 1; i < 2000; i++ {
+			m = m.Delete(i)
+		}
+		root := m.root.resolve()
+		assert.Empty(t, root.nodes, "в корне не должно остаться поддеревьев")
+		assert.Len(t, root.entries, 1)
 	})
 }
 
-func TestHashMap_Contains(t *testing.T) {
-	t.Run("проверка наличия ключа", func(t *testing.T) {
-		m := NewHashMap[string, int]().Set("exists", 1)
+func TestHashMap_Equal(t *testing.T) {
+	base := newTestHashMap[int, int](t)
+	for i := 0; i < 1000; i++ {
+		base = base.Set(i, i)
+	}
+
+	t.Run("версии одного отображения", func(t *testing.T) {
+		assert.True(t, Equal(base, base))
+		assert.True(t, Equal(base, base.Set(5, 5)), "запись того же значения")
+		assert.True(t, Equal(base, base.Set(1000, 1).Delete(1000)))
+		assert.False(t, Equal(base, base.Set(5, -5)))
+		assert.False(t, Equal(base, base.Delete(5).Set(1000, 1000)))
+	})
 
-		assert.True(t, m.Contains("exists"), "Contains должен вернуть true для существующего ключа")
-		assert.False(t, m.Contains("missing"), "Contains должен вернуть false для отсутствующего ключа")
+	t.Run("отображения с разными хэш-функциями", func(t *testing.T) {
+		other := NewHashMap(WithSeed[int, int](7))
+		for i := 999; i >= 0; i-- {
+			other = other.Set(i, i)
+		}
+		assert.True(t, Equal(base, other))
+		assert.True(t, Equal(other, base))
+		assert.False(t, Equal(base, other.Set(0, 1)))
+	})
+
+	t.Run("коллизии в разном порядке", func(t *testing.T) {
+		same := WithHashFunc[int, string](func(int) uint64 { return 42 })
+		a := NewHashMap(same).Set(1, "a").Set(2, "b").Set(3, "c")
+		b := NewHashMap(same).Set(3, "c").Set(1, "a").Set(2, "b")
+		assert.True(t, Equal(a, b))
+		assert.False(t, Equal(a, b.Set(2, "x")))
+	})
+
+	t.Run("EqualFunc", func(t *testing.T) {
+		a := newTestHashMap[string, []int](t).Set("x", []int{1, 2})
+		b := newTestHashMap[string, []int](t).Set("x", []int{1, 2})
+		assert.True(t, EqualFunc(a, b, func(x, y []int) bool { return len(x) == len(y) && x[0] == y[0] && x[1] == y[1] }))
 	})
 }
@@ -0,0 +1,446 @@
+package hashmap
+
+import (
+	"iter"
+	"math/bits"
+
+	pds "github.com/ykhdr/persistent-data-structures"
+)
+
+// signBit переворачивается при переводе int -> uint64, чтобы беззнаковый порядок
+// в дереве совпадал со знаковым порядком ключей (отрицательные идут первыми)
+const signBit = uint64(1) << 63
+
+// intNode - узел big-endian Patricia trie (Okasaki–Gill).
+// Лист: mask == 0, в key/value лежит запись.
+// Внутренний узел: prefix - общие старшие биты поддерева, mask - бит ветвления,
+// в left ключи с нулевым битом mask, в right - с единичным.
+type intNode[V any] struct {
+	prefix uint64
+	mask   uint64
+	key    uint64
+	value  V
+	left   *intNode[V]
+	right  *intNode[V]
+}
+
+func (n *intNode[V]) isLeaf() bool {
+	return n.mask == 0
+}
+
+type IntMap[V any] struct {
+	root *intNode[V]
+	len  int
+}
+
+var _ pds.Map[int, int, *IntMap[int]] = (*IntMap[int])(nil)
+
+func NewIntMap[V any]() *IntMap[V] {
+	return &IntMap[V]{
+		root: nil,
+		len:  0,
+	}
+}
+
+func toIntKey(key int) uint64 {
+	return uint64(key) ^ signBit
+}
+
+func fromIntKey(key uint64) int {
+	return int(key ^ signBit)
+}
+
+// maskPrefix оставляет только биты старше бита ветвления m
+func maskPrefix(key, m uint64) uint64 {
+	return key &^ (m | (m - 1))
+}
+
+func matchPrefix(key, prefix, m uint64) bool {
+	return maskPrefix(key, m) == prefix
+}
+
+func zeroBit(key, m uint64) bool {
+	return key&m == 0
+}
+
+// branchingBit возвращает старший бит, в котором различаются префиксы
+func branchingBit(p1, p2 uint64) uint64 {
+	return uint64(1) << (63 - bits.LeadingZeros64(p1^p2))
+}
+
+func newIntLeaf[V any](key uint64, value V) *intNode[V] {
+	return &intNode[V]{key: key, value: value}
+}
+
+func newIntBranch[V any](prefix, m uint64, left, right *intNode[V]) *intNode[V] {
+	return &intNode[V]{prefix: prefix, mask: m, left: left, right: right}
+}
+
+// branchOrChild - конструктор ветвления, схлопывающий пустые поддеревья
+func branchOrChild[V any](prefix, m uint64, left, right *intNode[V]) *intNode[V] {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	return newIntBranch(prefix, m, left, right)
+}
+
+// join объединяет два поддерева с непересекающимися префиксами
+func join[V any](p1 uint64, t1 *intNode[V], p2 uint64, t2 *intNode[V]) *intNode[V] {
+	m := branchingBit(p1, p2)
+	if zeroBit(p1, m) {
+		return newIntBranch(maskPrefix(p1, m), m, t1, t2)
+	}
+	return newIntBranch(maskPrefix(p1, m), m, t2, t1)
+}
+
+func (m *IntMap[V]) Len() int {
+	return m.len
+}
+
+func (m *IntMap[V]) Get(key int) (V, bool) {
+	var zero V
+	k := toIntKey(key)
+	node := m.root
+	for node != nil {
+		if node.isLeaf() {
+			if node.key == k {
+				return node.value, true
+			}
+			return zero, false
+		}
+		if !matchPrefix(k, node.prefix, node.mask) {
+			return zero, false
+		}
+		if zeroBit(k, node.mask) {
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return zero, false
+}
+
+func (m *IntMap[V]) Contains(key int) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+func (m *IntMap[V]) Set(key int, value V) *IntMap[V] {
+	newRoot, added := insertInt(m.root, toIntKey(key), value)
+
+	newLen := m.len
+	if added {
+		newLen++
+	}
+
+	return &IntMap[V]{
+		root: newRoot,
+		len:  newLen,
+	}
+}
+
+func insertInt[V any](node *intNode[V], key uint64, value V) (*intNode[V], bool) {
+	if node == nil {
+		return newIntLeaf(key, value), true
+	}
+
+	if node.isLeaf() {
+		if node.key == key {
+			return newIntLeaf(key, value), false
+		}
+		return join(key, newIntLeaf(key, value), node.key, node), true
+	}
+
+	if !matchPrefix(key, node.prefix, node.mask) {
+		return join(key, newIntLeaf(key, value), node.prefix, node), true
+	}
+
+	if zeroBit(key, node.mask) {
+		newLeft, added := insertInt(node.left, key, value)
+		return newIntBranch(node.prefix, node.mask, newLeft, node.right), added
+	}
+	newRight, added := insertInt(node.right, key, value)
+	return newIntBranch(node.prefix, node.mask, node.left, newRight), added
+}
+
+func (m *IntMap[V]) Delete(key int) *IntMap[V] {
+	newRoot, deleted := deleteInt(m.root, toIntKey(key))
+	if !deleted {
+		return m
+	}
+
+	return &IntMap[V]{
+		root: newRoot,
+		len:  m.len - 1,
+	}
+}
+
+func deleteInt[V any](node *intNode[V], key uint64) (*intNode[V], bool) {
+	if node == nil {
+		return nil, false
+	}
+
+	if node.isLeaf() {
+		if node.key == key {
+			return nil, true
+		}
+		return node, false
+	}
+
+	if !matchPrefix(key, node.prefix, node.mask) {
+		return node, false
+	}
+
+	if zeroBit(key, node.mask) {
+		newLeft, deleted := deleteInt(node.left, key)
+		if !deleted {
+			return node, false
+		}
+		return branchOrChild(node.prefix, node.mask, newLeft, node.right), true
+	}
+	newRight, deleted := deleteInt(node.right, key)
+	if !deleted {
+		return node, false
+	}
+	return branchOrChild(node.prefix, node.mask, node.left, newRight), true
+}
+
+func (m *IntMap[V]) Min() (int, V, bool) {
+	var zero V
+	if m.root == nil {
+		return 0, zero, false
+	}
+	node := m.root
+	for !node.isLeaf() {
+		node = node.left
+	}
+	return fromIntKey(node.key), node.value, true
+}
+
+func (m *IntMap[V]) Max() (int, V, bool) {
+	var zero V
+	if m.root == nil {
+		return 0, zero, false
+	}
+	node := m.root
+	for !node.isLeaf() {
+		node = node.right
+	}
+	return fromIntKey(node.key), node.value, true
+}
+
+// Union объединяет два отображения. При совпадении ключей остаётся значение из m.
+func (m *IntMap[V]) Union(other *IntMap[V]) *IntMap[V] {
+	if other.root == nil {
+		return m
+	}
+	if m.root == nil {
+		return other
+	}
+
+	newRoot, dups := unionInt(m.root, other.root)
+	return &IntMap[V]{
+		root: newRoot,
+		len:  m.len + other.len - dups,
+	}
+}
+
+// unionInt возвращает объединённое дерево и количество совпавших ключей
+func unionInt[V any](s, t *intNode[V]) (*intNode[V], int) {
+	if s == nil {
+		return t, 0
+	}
+	if t == nil {
+		return s, 0
+	}
+
+	if s.isLeaf() {
+		return insertIntKeep(t, s.key, s.value, false)
+	}
+	if t.isLeaf() {
+		return insertIntKeep(s, t.key, t.value, true)
+	}
+
+	switch {
+	case s.mask == t.mask && s.prefix == t.prefix:
+		left, d1 := unionInt(s.left, t.left)
+		right, d2 := unionInt(s.right, t.right)
+		return newIntBranch(s.prefix, s.mask, left, right), d1 + d2
+
+	case s.mask > t.mask && matchPrefix(t.prefix, s.prefix, s.mask):
+		if zeroBit(t.prefix, s.mask) {
+			left, d := unionInt(s.left, t)
+			return newIntBranch(s.prefix, s.mask, left, s.right), d
+		}
+		right, d := unionInt(s.right, t)
+		return newIntBranch(s.prefix, s.mask, s.left, right), d
+
+	case s.mask < t.mask && matchPrefix(s.prefix, t.prefix, t.mask):
+		if zeroBit(s.prefix, t.mask) {
+			left, d := unionInt(s, t.left)
+			return newIntBranch(t.prefix, t.mask, left, t.right), d
+		}
+		right, d := unionInt(s, t.right)
+		return newIntBranch(t.prefix, t.mask, t.left, right), d
+	}
+
+	return join(s.prefix, s, t.prefix, t), 0
+}
+
+// insertIntKeep вставляет ключ в дерево; при keepExisting существующее значение не заменяется.
+// Возвращает 1, если ключ уже был в дереве.
+func insertIntKeep[V any](node *intNode[V], key uint64, value V, keepExisting bool) (*intNode[V], int) {
+	if keepExisting {
+		if _, ok := lookupInt(node, key); ok {
+			return node, 1
+		}
+		newNode, _ := insertInt(node, key, value)
+		return newNode, 0
+	}
+
+	newNode, added := insertInt(node, key, value)
+	if added {
+		return newNode, 0
+	}
+	return newNode, 1
+}
+
+func lookupInt[V any](node *intNode[V], key uint64) (*intNode[V], bool) {
+	for node != nil {
+		if node.isLeaf() {
+			return node, node.key == key
+		}
+		if !matchPrefix(key, node.prefix, node.mask) {
+			return nil, false
+		}
+		if zeroBit(key, node.mask) {
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return nil, false
+}
+
+// Intersection оставляет только ключи, присутствующие в обоих отображениях, со значениями из m
+func (m *IntMap[V]) Intersection(other *IntMap[V]) *IntMap[V] {
+	newRoot, count := intersectInt(m.root, other.root)
+	return &IntMap[V]{
+		root: newRoot,
+		len:  count,
+	}
+}
+
+func intersectInt[V any](s, t *intNode[V]) (*intNode[V], int) {
+	if s == nil || t == nil {
+		return nil, 0
+	}
+
+	if s.isLeaf() {
+		if _, ok := lookupInt(t, s.key); ok {
+			return s, 1
+		}
+		return nil, 0
+	}
+	if t.isLeaf() {
+		if leaf, ok := lookupInt(s, t.key); ok {
+			return leaf, 1
+		}
+		return nil, 0
+	}
+
+	switch {
+	case s.mask == t.mask && s.prefix == t.prefix:
+		left, c1 := intersectInt(s.left, t.left)
+		right, c2 := intersectInt(s.right, t.right)
+		if left == s.left && right == s.right {
+			return s, c1 + c2
+		}
+		return branchOrChild(s.prefix, s.mask, left, right), c1 + c2
+
+	case s.mask > t.mask && matchPrefix(t.prefix, s.prefix, s.mask):
+		if zeroBit(t.prefix, s.mask) {
+			return intersectInt(s.left, t)
+		}
+		return intersectInt(s.right, t)
+
+	case s.mask < t.mask && matchPrefix(s.prefix, t.prefix, t.mask):
+		if zeroBit(s.prefix, t.mask) {
+			return intersectInt(s, t.left)
+		}
+		return intersectInt(s, t.right)
+	}
+
+	return nil, 0
+}
+
+func (m *IntMap[V]) All() iter.Seq2[int, V] {
+	return func(yield func(int, V) bool) {
+		iterInt(m.root, yield)
+	}
+}
+
+func iterInt[V any](node *intNode[V], yield func(int, V) bool) bool {
+	if node == nil {
+		return true
+	}
+	if node.isLeaf() {
+		return yield(fromIntKey(node.key), node.value)
+	}
+	return iterInt(node.left, yield) && iterInt(node.right, yield)
+}
+
+func (m *IntMap[V]) Keys() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+func (m *IntMap[V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Range перебирает в порядке возрастания ключи из полуинтервала [from, to)
+func (m *IntMap[V]) Range(from, to int) iter.Seq2[int, V] {
+	return func(yield func(int, V) bool) {
+		if from >= to {
+			return
+		}
+		rangeInt(m.root, toIntKey(from), toIntKey(to), yield)
+	}
+}
+
+func rangeInt[V any](node *intNode[V], lo, hi uint64, yield func(int, V) bool) bool {
+	if node == nil {
+		return true
+	}
+
+	if node.isLeaf() {
+		if node.key >= lo && node.key < hi {
+			return yield(fromIntKey(node.key), node.value)
+		}
+		return true
+	}
+
+	// все ключи поддерева лежат в [prefix, prefix | (2*mask - 1)]
+	minKey := node.prefix
+	maxKey := node.prefix | (node.mask | (node.mask - 1))
+	if maxKey < lo || minKey >= hi {
+		return true
+	}
+
+	return rangeInt(node.left, lo, hi, yield) && rangeInt(node.right, lo, hi, yield)
+}
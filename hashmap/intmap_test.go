@@ -0,0 +1,282 @@
+package hashmap
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectIntMap[V any](m *IntMap[V]) ([]int, []V) {
+	var keys []int
+	var values []V
+	for k, v := range m.All() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	return keys, values
+}
+
+func TestIntMap_SetAndGet(t *testing.T) {
+	t.Run("добавление и получение элементов", func(t *testing.T) {
+		m := NewIntMap[string]().Set(1, "one").Set(2, "two").Set(3, "three")
+
+		assert.Equal(t, 3, m.Len(), "после добавления 3 элементов длина должна быть 3")
+
+		val, ok := m.Get(2)
+		require.True(t, ok, "ключ 2 должен существовать")
+		assert.Equal(t, "two", val)
+
+		_, ok = m.Get(4)
+		assert.False(t, ok, "Get несуществующего ключа должен вернуть false")
+	})
+
+	t.Run("отрицательные и граничные ключи", func(t *testing.T) {
+		keys := []int{-1, 0, 1, -1 << 63, 1<<63 - 1, -42, 42}
+		m := NewIntMap[int]()
+		for _, k := range keys {
+			m = m.Set(k, k*2)
+		}
+
+		assert.Equal(t, len(keys), m.Len())
+		for _, k := range keys {
+			val, ok := m.Get(k)
+			require.True(t, ok, "ключ %d должен существовать", k)
+			assert.Equal(t, k*2, val)
+		}
+	})
+
+	t.Run("перезапись не меняет длину", func(t *testing.T) {
+		m1 := NewIntMap[int]().Set(7, 1)
+		m2 := m1.Set(7, 100)
+
+		val1, _ := m1.Get(7)
+		val2, _ := m2.Get(7)
+
+		assert.Equal(t, 1, val1, "оригинал должен содержать старое значение")
+		assert.Equal(t, 100, val2, "новая версия должна содержать новое значение")
+		assert.Equal(t, 1, m2.Len())
+	})
+}
+
+func TestIntMap_Delete(t *testing.T) {
+	t.Run("удаление существующего ключа", func(t *testing.T) {
+		m := NewIntMap[int]().Set(1, 1).Set(2, 2).Set(3, 3)
+
+		m2 := m.Delete(2)
+
+		assert.Equal(t, 3, m.Len(), "оригинал не должен измениться")
+		assert.Equal(t, 2, m2.Len(), "новая версия должна содержать 2 элемента")
+		assert.True(t, m.Contains(2), "оригинал должен содержать 2")
+		assert.False(t, m2.Contains(2), "новая версия не должна содержать 2")
+		assert.True(t, m2.Contains(1))
+		assert.True(t, m2.Contains(3))
+	})
+
+	t.Run("удаление несуществующего ключа возвращает ту же версию", func(t *testing.T) {
+		m := NewIntMap[int]().Set(1, 1)
+
+		assert.Same(t, m, m.Delete(5))
+	})
+
+	t.Run("удаление всех ключей", func(t *testing.T) {
+		m := NewIntMap[int]()
+		for i := 0; i < 1000; i++ {
+			m = m.Set(i, i)
+		}
+		for i := 0; i < 1000; i++ {
+			m = m.Delete(i)
+		}
+
+		assert.Equal(t, 0, m.Len())
+		_, _, ok := m.Min()
+		assert.False(t, ok, "пустое отображение не должно иметь минимума")
+	})
+}
+
+func TestIntMap_OrderedIteration(t *testing.T) {
+	t.Run("ключи возвращаются по возрастанию", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		m := NewIntMap[int]()
+		expected := make(map[int]bool)
+		for i := 0; i < 5000; i++ {
+			k := r.Intn(100000) - 50000
+			m = m.Set(k, k)
+			expected[k] = true
+		}
+
+		keys, values := collectIntMap(m)
+
+		assert.Equal(t, len(expected), m.Len())
+		assert.Equal(t, len(expected), len(keys))
+		assert.True(t, sort.IntsAreSorted(keys), "итерация должна идти по возрастанию ключей")
+		assert.Equal(t, keys, values)
+	})
+
+	t.Run("ранний выход из итератора", func(t *testing.T) {
+		m := NewIntMap[int]().Set(1, 1).Set(2, 2).Set(3, 3)
+
+		count := 0
+		for range m.All() {
+			count++
+			break
+		}
+
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestIntMap_MinMax(t *testing.T) {
+	m := NewIntMap[string]().Set(10, "a").Set(-5, "b").Set(300, "c").Set(0, "d")
+
+	k, v, ok := m.Min()
+	require.True(t, ok)
+	assert.Equal(t, -5, k)
+	assert.Equal(t, "b", v)
+
+	k, v, ok = m.Max()
+	require.True(t, ok)
+	assert.Equal(t, 300, k)
+	assert.Equal(t, "c", v)
+
+	_, _, ok = NewIntMap[int]().Max()
+	assert.False(t, ok, "у пустого отображения нет максимума")
+}
+
+func TestIntMap_Range(t *testing.T) {
+	m := NewIntMap[int]()
+	for i := -100; i < 100; i += 3 {
+		m = m.Set(i, i)
+	}
+
+	t.Run("полуинтервал [from, to)", func(t *testing.T) {
+		var keys []int
+		for k := range m.Range(-10, 11) {
+			keys = append(keys, k)
+		}
+
+		assert.Equal(t, []int{-10, -7, -4, -1, 2, 5, 8}, keys)
+	})
+
+	t.Run("пустой диапазон", func(t *testing.T) {
+		count := 0
+		for range m.Range(10, 10) {
+			count++
+		}
+		for range m.Range(1000, 2000) {
+			count++
+		}
+
+		assert.Equal(t, 0, count)
+	})
+}
+
+func TestIntMap_Union(t *testing.T) {
+	t.Run("объединение с приоритетом левого отображения", func(t *testing.T) {
+		a := NewIntMap[string]()
+		b := NewIntMap[string]()
+		for i := 0; i < 100; i++ {
+			a = a.Set(i, "a")
+		}
+		for i := 50; i < 200; i++ {
+			b = b.Set(i, "b")
+		}
+
+		u := a.Union(b)
+
+		assert.Equal(t, 200, u.Len())
+		for i := 0; i < 200; i++ {
+			val, ok := u.Get(i)
+			require.True(t, ok, "ключ %d должен существовать", i)
+			if i < 100 {
+				assert.Equal(t, "a", val, "при совпадении ключей остаётся значение левого отображения")
+			} else {
+				assert.Equal(t, "b", val)
+			}
+		}
+
+		keys, _ := collectIntMap(u)
+		assert.True(t, sort.IntsAreSorted(keys))
+		assert.Equal(t, 100, a.Len(), "исходные отображения не должны измениться")
+		assert.Equal(t, 150, b.Len(), "исходные отображения не должны измениться")
+	})
+
+	t.Run("случайные множества", func(t *testing.T) {
+		r := rand.New(rand.NewSource(2))
+		a := NewIntMap[int]()
+		b := NewIntMap[int]()
+		expected := make(map[int]int)
+		for i := 0; i < 2000; i++ {
+			k := r.Intn(5000) - 2500
+			b = b.Set(k, -1)
+			expected[k] = -1
+		}
+		for i := 0; i < 2000; i++ {
+			k := r.Intn(5000) - 2500
+			a = a.Set(k, k)
+			expected[k] = k
+		}
+
+		u := a.Union(b)
+
+		assert.Equal(t, len(expected), u.Len())
+		for k, v := range expected {
+			val, ok := u.Get(k)
+			require.True(t, ok, "ключ %d должен существовать", k)
+			assert.Equal(t, v, val)
+		}
+	})
+
+	t.Run("объединение с пустым", func(t *testing.T) {
+		a := NewIntMap[int]().Set(1, 1)
+		empty := NewIntMap[int]()
+
+		assert.Same(t, a, a.Union(empty))
+		assert.Same(t, a, empty.Union(a))
+	})
+}
+
+func TestIntMap_Intersection(t *testing.T) {
+	t.Run("пересечение сохраняет значения левого отображения", func(t *testing.T) {
+		r := rand.New(rand.NewSource(3))
+		a := NewIntMap[int]()
+		b := NewIntMap[int]()
+		inA := make(map[int]bool)
+		inB := make(map[int]bool)
+		for i := 0; i < 2000; i++ {
+			k := r.Intn(5000) - 2500
+			a = a.Set(k, k)
+			inA[k] = true
+		}
+		for i := 0; i < 2000; i++ {
+			k := r.Intn(5000) - 2500
+			b = b.Set(k, -1)
+			inB[k] = true
+		}
+
+		in := a.Intersection(b)
+
+		expected := 0
+		for k := range inA {
+			if inB[k] {
+				expected++
+				val, ok := in.Get(k)
+				require.True(t, ok, "ключ %d должен быть в пересечении", k)
+				assert.Equal(t, k, val)
+			}
+		}
+		assert.Equal(t, expected, in.Len())
+
+		keys, _ := collectIntMap(in)
+		assert.Equal(t, expected, len(keys))
+		assert.True(t, sort.IntsAreSorted(keys))
+	})
+
+	t.Run("пересечение с пустым", func(t *testing.T) {
+		a := NewIntMap[int]().Set(1, 1)
+
+		assert.Equal(t, 0, a.Intersection(NewIntMap[int]()).Len())
+	})
+}
@@ -0,0 +1,23 @@
+package hashmap
+
+import "encoding/json"
+
+// MarshalJSON кодирует HashMap как JSON-объект. Поддерживаются ключи,
+// которые encoding/json умеет использовать в map: строки, целые числа и encoding.TextMarshaler.
+func (m *HashMap[K, V]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.ToMap())
+}
+
+func (m *HashMap[K, V]) UnmarshalJSON(data []byte) error {
+	var decoded map[K]V
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	entries := make([]entry[K, V], 0, len(decoded))
+	for k, v := range decoded {
+		entries = append(entries, entry[K, V]{key: k, value: v})
+	}
+	*m = *fromEntries(m.emptyLike(), entries)
+	return nil
+}
@@ -0,0 +1,96 @@
+package hashmap
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashMap_JSON(t *testing.T) {
+	t.Run("кодирование в JSON-объект", func(t *testing.T) {
+		m := NewHashMap[string, int]().Set("a", 1).Set("b", 2)
+
+		data, err := json.Marshal(m)
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a": 1, "b": 2}`, string(data))
+	})
+
+	t.Run("целочисленные ключи", func(t *testing.T) {
+		m := NewHashMap[int, string]().Set(1, "one").Set(2, "two")
+
+		data, err := json.Marshal(m)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"1": "one", "2": "two"}`, string(data))
+
+		var decoded HashMap[int, string]
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		val, ok := decoded.Get(2)
+		require.True(t, ok)
+		assert.Equal(t, "two", val)
+	})
+
+	t.Run("пустое отображение", func(t *testing.T) {
+		var decoded HashMap[string, int]
+		require.NoError(t, json.Unmarshal([]byte(`{}`), &decoded))
+
+		assert.Equal(t, 0, decoded.Len())
+		assert.Equal(t, 1, decoded.Set("a", 1).Len(), "декодированное отображение должно быть рабочим")
+	})
+
+	t.Run("ошибка декодирования", func(t *testing.T) {
+		var decoded HashMap[string, int]
+		assert.Error(t, json.Unmarshal([]byte(`[1, 2]`), &decoded))
+	})
+
+	for _, size := range []int{1, 100, 10000} {
+		t.Run(fmt.Sprintf("round-trip size_%d", size), func(t *testing.T) {
+			m := NewHashMap[string, int]()
+			for i := 0; i < size; i++ {
+				m = m.Set(fmt.Sprintf("key_%d", i), i)
+			}
+
+			data, err := json.Marshal(m)
+			require.NoError(t, err)
+
+			var decoded HashMap[string, int]
+			require.NoError(t, json.Unmarshal(data, &decoded))
+
+			require.Equal(t, size, decoded.Len())
+			for i := 0; i < size; i++ {
+				val, ok := decoded.Get(fmt.Sprintf("key_%d", i))
+				require.True(t, ok, "ключ key_%d должен существовать", i)
+				require.Equal(t, i, val)
+			}
+
+			// дерево, построенное снизу вверх, должно поддерживать дальнейшие изменения
+			updated := &decoded
+			for i := 0; i < size; i += 2 {
+				updated = updated.Delete(fmt.Sprintf("key_%d", i))
+			}
+			assert.Equal(t, size/2, updated.Len())
+			assert.False(t, updated.Contains("key_0"))
+		})
+	}
+}
+
+func TestHashMap_JSONNested(t *testing.T) {
+	t.Run("вложенные отображения", func(t *testing.T) {
+		m := NewHashMap[string, *HashMap[string, int]]().
+			Set("outer", NewHashMap[string, int]().Set("inner", 42))
+
+		data, err := json.Marshal(m)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"outer": {"inner": 42}}`, string(data))
+
+		var decoded HashMap[string, *HashMap[string, int]]
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		inner, _ := decoded.Get("outer")
+		val, _ := inner.Get("inner")
+		assert.Equal(t, 42, val)
+	})
+}
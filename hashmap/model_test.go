@@ -0,0 +1,87 @@
+package hashmap
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pds "github.com/ykhdr/persistent-data-structures"
+	"github.com/ykhdr/persistent-data-structures/pdstest"
+)
+
+// mapModel сравнивает реализацию pds.Map, начиная с empty, с NaiveHashMap.
+// Ключи берутся из небольшого диапазона, чтобы операции часто попадали в уже существующие ключи.
+func mapModel[S pds.Map[int, int, S]](empty S) pdstest.Model[S, *NaiveHashMap[int, int]] {
+	return pdstest.Model[S, *NaiveHashMap[int, int]]{
+		Ops:   []string{"Set", "Set", "Delete", "Get"},
+		Empty: empty,
+		Ref:   NewNaiveHashMap[int, int](),
+		Apply: func(m S, ref *NaiveHashMap[int, int], op int, arg int) (S, *NaiveHashMap[int, int], error) {
+			key := arg % 200
+			switch op {
+			case 0, 1:
+				return m.Set(key, arg), ref.Set(key, arg), nil
+			case 2:
+				return m.Delete(key), ref.Delete(key), nil
+			default:
+				got, gotOk := m.Get(key)
+				want, wantOk := ref.Get(key)
+				if got != want || gotOk != wantOk || m.Contains(key) != wantOk {
+					return m, ref, fmt.Errorf("Get(%d): %d %v, ожидалось %d %v", key, got, gotOk, want, wantOk)
+				}
+				return m, ref, nil
+			}
+		},
+		Equal: func(m S, ref *NaiveHashMap[int, int]) error {
+			if m.Len() != ref.Len() {
+				return fmt.Errorf("Len: %d, ожидалось %d", m.Len(), ref.Len())
+			}
+			count := 0
+			for k, v := range m.All() {
+				if want, ok := ref.Get(k); !ok || want != v {
+					return fmt.Errorf("ключ %d: %d, ожидалось %d %v", k, v, want, ok)
+				}
+				count++
+			}
+			if count != ref.Len() {
+				return fmt.Errorf("обход: %d записей, ожидалось %d", count, ref.Len())
+			}
+			return nil
+		},
+	}
+}
+
+func TestHashMap_Model(t *testing.T) {
+	t.Run("HashMap", func(t *testing.T) {
+		mapModel(NewHashMap[int, int]()).Run(t)
+	})
+	t.Run("HashMap с коллизиями", func(t *testing.T) {
+		mapModel(NewHashMap(WithHashFunc[int, int](func(k int) uint64 { return uint64(k % 3) }))).Run(t)
+	})
+	t.Run("HashMapFile", func(t *testing.T) {
+		f, err := CreateHashMapFile[int, int](filepath.Join(t.TempDir(), "hashmap.pds"), 16)
+		require.NoError(t, err)
+		t.Cleanup(func() { f.Close() })
+		mapModel(f.Empty()).Run(t)
+	})
+	t.Run("ShardedHashMap", func(t *testing.T) {
+		mapModel(NewShardedHashMap(WithBuckets[int, int](16), WithMaxLoad[int, int](1))).Run(t)
+	})
+}
+
+func FuzzHashMap(f *testing.F) {
+	f.Add([]byte{0, 0, 1, 0, 0, 1, 2, 0, 2, 2, 1, 0, 3, 1, 1, 0})
+	f.Add([]byte{0, 0, 7, 0, 0, 1, 207, 0, 2, 1, 7, 0, 3, 3, 207, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		steps := pdstest.StepsFromBytes(data)
+		if err := mapModel(NewHashMap[int, int]()).Execute(steps); err != nil {
+			t.Fatal(err)
+		}
+		collisions := NewHashMap(WithHashFunc[int, int](func(k int) uint64 { return uint64(k % 3) }))
+		if err := mapModel(collisions).Execute(steps); err != nil {
+			t.Fatal("с коллизиями:", err)
+		}
+	})
+}
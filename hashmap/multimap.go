@@ -0,0 +1,125 @@
+package hashmap
+
+import "iter"
+
+// Multimap хранит для каждого ключа множество значений.
+// Ключ без значений из отображения удаляется.
+type Multimap[K comparable, V comparable] struct {
+	data *HashMap[K, *HashSet[V]]
+	len  int // общее количество пар ключ-значение
+}
+
+func NewMultimap[K comparable, V comparable]() *Multimap[K, V] {
+	return &Multimap[K, V]{
+		data: NewHashMap[K, *HashSet[V]](),
+		len:  0,
+	}
+}
+
+func (m *Multimap[K, V]) Len() int {
+	return m.len
+}
+
+func (m *Multimap[K, V]) KeyCount() int {
+	return m.data.Len()
+}
+
+func (m *Multimap[K, V]) Count(key K) int {
+	values, ok := m.data.Get(key)
+	if !ok {
+		return 0
+	}
+	return values.Len()
+}
+
+func (m *Multimap[K, V]) Contains(key K) bool {
+	return m.data.Contains(key)
+}
+
+func (m *Multimap[K, V]) ContainsEntry(key K, value V) bool {
+	values, ok := m.data.Get(key)
+	return ok && values.Contains(value)
+}
+
+func (m *Multimap[K, V]) Get(key K) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		values, ok := m.data.Get(key)
+		if !ok {
+			return
+		}
+		for v := range values.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (m *Multimap[K, V]) Put(key K, value V) *Multimap[K, V] {
+	values, ok := m.data.Get(key)
+	if !ok {
+		values = NewHashSet[V]()
+	}
+
+	newValues := values.Add(value)
+	if newValues == values {
+		return m
+	}
+
+	return &Multimap[K, V]{
+		data: m.data.Set(key, newValues),
+		len:  m.len + 1,
+	}
+}
+
+func (m *Multimap[K, V]) RemoveValue(key K, value V) *Multimap[K, V] {
+	values, ok := m.data.Get(key)
+	if !ok {
+		return m
+	}
+
+	newValues := values.Remove(value)
+	if newValues == values {
+		return m
+	}
+
+	if newValues.Len() == 0 {
+		return &Multimap[K, V]{
+			data: m.data.Delete(key),
+			len:  m.len - 1,
+		}
+	}
+
+	return &Multimap[K, V]{
+		data: m.data.Set(key, newValues),
+		len:  m.len - 1,
+	}
+}
+
+func (m *Multimap[K, V]) RemoveAll(key K) *Multimap[K, V] {
+	values, ok := m.data.Get(key)
+	if !ok {
+		return m
+	}
+
+	return &Multimap[K, V]{
+		data: m.data.Delete(key),
+		len:  m.len - values.Len(),
+	}
+}
+
+func (m *Multimap[K, V]) Keys() iter.Seq[K] {
+	return m.data.Keys()
+}
+
+func (m *Multimap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, values := range m.data.All() {
+			for v := range values.All() {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package hashmap
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func multimapValues(m *Multimap[string, int], key string) []int {
+	var values []int
+	for v := range m.Get(key) {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	return values
+}
+
+func TestMultimap_Put(t *testing.T) {
+	t.Run("несколько значений на ключ", func(t *testing.T) {
+		m := NewMultimap[string, int]().Put("a", 1).Put("a", 2).Put("b", 3)
+
+		assert.Equal(t, 3, m.Len(), "должно быть 3 пары")
+		assert.Equal(t, 2, m.KeyCount(), "должно быть 2 ключа")
+		assert.Equal(t, 2, m.Count("a"))
+		assert.Equal(t, []int{1, 2}, multimapValues(m, "a"))
+		assert.Equal(t, []int{3}, multimapValues(m, "b"))
+		assert.True(t, m.ContainsEntry("a", 2))
+		assert.False(t, m.ContainsEntry("b", 2))
+	})
+
+	t.Run("повторная пара не добавляется", func(t *testing.T) {
+		m := NewMultimap[string, int]().Put("a", 1)
+
+		assert.Same(t, m, m.Put("a", 1))
+		assert.Equal(t, 1, m.Len())
+	})
+
+	t.Run("Put создаёт новую версию", func(t *testing.T) {
+		m1 := NewMultimap[string, int]().Put("a", 1)
+		m2 := m1.Put("a", 2)
+
+		assert.Equal(t, []int{1}, multimapValues(m1, "a"), "оригинал не должен измениться")
+		assert.Equal(t, []int{1, 2}, multimapValues(m2, "a"))
+	})
+
+	t.Run("отсутствующий ключ", func(t *testing.T) {
+		m := NewMultimap[string, int]()
+
+		assert.Empty(t, multimapValues(m, "missing"))
+		assert.Equal(t, 0, m.Count("missing"))
+	})
+}
+
+func TestMultimap_Remove(t *testing.T) {
+	base := NewMultimap[string, int]().Put("a", 1).Put("a", 2).Put("b", 3)
+
+	t.Run("удаление одного значения", func(t *testing.T) {
+		m := base.RemoveValue("a", 1)
+
+		assert.Equal(t, 2, m.Len())
+		assert.Equal(t, []int{2}, multimapValues(m, "a"))
+		assert.Equal(t, []int{1, 2}, multimapValues(base, "a"), "оригинал не должен измениться")
+	})
+
+	t.Run("удаление последнего значения удаляет ключ", func(t *testing.T) {
+		m := base.RemoveValue("b", 3)
+
+		assert.False(t, m.Contains("b"))
+		assert.Equal(t, 1, m.KeyCount())
+	})
+
+	t.Run("удаление всех значений ключа", func(t *testing.T) {
+		m := base.RemoveAll("a")
+
+		assert.Equal(t, 1, m.Len())
+		assert.False(t, m.Contains("a"))
+	})
+
+	t.Run("удаление отсутствующего возвращает ту же версию", func(t *testing.T) {
+		assert.Same(t, base, base.RemoveValue("a", 100))
+		assert.Same(t, base, base.RemoveValue("missing", 1))
+		assert.Same(t, base, base.RemoveAll("missing"))
+	})
+}
+
+func TestMultimap_All(t *testing.T) {
+	m := NewMultimap[string, int]().Put("a", 1).Put("a", 2).Put("b", 3)
+
+	count := 0
+	sum := 0
+	for _, v := range m.All() {
+		count++
+		sum += v
+	}
+
+	assert.Equal(t, 3, count)
+	assert.Equal(t, 6, sum)
+}
@@ -1,9 +1,17 @@
 package hashmap
 
+import (
+	"iter"
+
+	pds "github.com/ykhdr/persistent-data-structures"
+)
+
 type NaiveHashMap[K comparable, V any] struct {
 	data map[K]V
 }
 
+var _ pds.Map[string, int, *NaiveHashMap[string, int]] = (*NaiveHashMap[string, int])(nil)
+
 func NewNaiveHashMap[K comparable, V any]() *NaiveHashMap[K, V] {
 	return &NaiveHashMap[K, V]{data: make(map[K]V)}
 }
@@ -42,3 +50,33 @@ func (m *NaiveHashMap[K, V]) Contains(key K) bool {
 	_, ok := m.data[key]
 	return ok
 }
+
+func (m *NaiveHashMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range m.data {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+func (m *NaiveHashMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.data {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+func (m *NaiveHashMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.data {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
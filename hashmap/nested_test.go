@@ -0,0 +1,108 @@
+package hashmap_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ykhdr/persistent-data-structures/array"
+	"github.com/ykhdr/persistent-data-structures/hashmap"
+)
+
+// Тесты с векторами внутри отображений лежат во внешнем пакете: array импортирует hashmap
+// (array.GroupBy), поэтому внутренние тесты hashmap не могут импортировать array.
+
+func TestHashMap_NestedStructures(t *testing.T) {
+	constructors := map[string]func(t *testing.T) *hashmap.HashMap[string, *array.Vector[int]]{
+		"в памяти": func(*testing.T) *hashmap.HashMap[string, *array.Vector[int]] {
+			return hashmap.NewHashMap[string, *array.Vector[int]]()
+		},
+		"в файле": func(t *testing.T) *hashmap.HashMap[string, *array.Vector[int]] {
+			f, err := hashmap.CreateHashMapFile[string, *array.Vector[int]](filepath.Join(t.TempDir(), "hashmap.pds"), 4)
+			require.NoError(t, err)
+			t.Cleanup(func() { f.Close() })
+			return f.Empty()
+		},
+	}
+
+	for name, newMap := range constructors {
+		t.Run(name, func(t *testing.T) {
+			inner := array.NewVector[int]().Append(1).Append(2).Append(3)
+			m := newMap(t).Set("numbers", inner)
+
+			retrieved, ok := m.Get("numbers")
+			require.True(t, ok, "ключ 'numbers' должен существовать")
+
+			val, _ := retrieved.Get(0)
+			assert.Equal(t, 1, val, "первый элемент вложенного вектора должен быть 1")
+			assert.Equal(t, 3, retrieved.Len(), "вложенный вектор должен содержать 3 элемента")
+		})
+	}
+}
+
+func TestShardedHashMap_NestedStructures(t *testing.T) {
+	t.Run("хранение векторов в качестве значений", func(t *testing.T) {
+		inner := array.NewVector[int]().Append(1).Append(2).Append(3)
+		m := hashmap.NewShardedHashMap[string, *array.Vector[int]]().Set("numbers", inner)
+
+		retrieved, ok := m.Get("numbers")
+		require.True(t, ok, "ключ 'numbers' должен существовать")
+
+		val, _ := retrieved.Get(0)
+		assert.Equal(t, 1, val, "первый элемент вложенного вектора должен быть 1")
+		assert.Equal(t, 3, retrieved.Len(), "вложенный вектор должен содержать 3 элемента")
+	})
+}
+
+func TestHashMap_JSONNestedVectors(t *testing.T) {
+	m := hashmap.NewHashMap[string, *array.Vector[int]]().
+		Set("numbers", array.NewVector[int]().Append(1).Append(2))
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"numbers": [1, 2]}`, string(data))
+
+	var decoded hashmap.HashMap[string, *array.Vector[int]]
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	inner, ok := decoded.Get("numbers")
+	require.True(t, ok)
+	val, _ := inner.Get(1)
+	assert.Equal(t, 2, val)
+}
+
+func TestHashMap_GobNestedVectors(t *testing.T) {
+	m := hashmap.NewHashMap[string, *array.Vector[int]]()
+	for i := 0; i < 50; i++ {
+		v := array.NewVector[int]()
+		for j := 0; j < i*10; j++ {
+			v = v.Append(j)
+		}
+		m = m.Set(fmt.Sprint(i), v)
+	}
+
+	// как и gobRoundTrip, отображение передаётся внутри структуры-сообщения
+	type message struct {
+		Payload *hashmap.HashMap[string, *array.Vector[int]]
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(message{Payload: m}))
+	var decoded message
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+
+	require.Equal(t, m.Len(), decoded.Payload.Len())
+	for k, v := range m.All() {
+		actual, ok := decoded.Payload.Get(k)
+		require.True(t, ok)
+		require.Equal(t, v.Len(), actual.Len())
+		last, _ := actual.Get(actual.Len() - 1)
+		expected, _ := v.Get(v.Len() - 1)
+		require.Equal(t, expected, last)
+	}
+}
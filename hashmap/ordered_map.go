@@ -0,0 +1,104 @@
+package hashmap
+
+import (
+	"iter"
+
+	pds "github.com/ykhdr/persistent-data-structures"
+)
+
+type orderedEntry[V any] struct {
+	value V
+	seq   int
+}
+
+// OrderedMap - HashMap, сохраняющий порядок вставки ключей.
+// Индекс порядка - IntMap по номеру вставки, поэтому All() идёт по возрастанию seq.
+type OrderedMap[K comparable, V any] struct {
+	entries *HashMap[K, orderedEntry[V]]
+	order   *IntMap[K]
+	nextSeq int
+}
+
+var _ pds.Map[string, int, *OrderedMap[string, int]] = (*OrderedMap[string, int])(nil)
+
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		entries: NewHashMap[K, orderedEntry[V]](),
+		order:   NewIntMap[K](),
+		nextSeq: 0,
+	}
+}
+
+func (m *OrderedMap[K, V]) Len() int {
+	return m.entries.Len()
+}
+
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	e, ok := m.entries.Get(key)
+	return e.value, ok
+}
+
+func (m *OrderedMap[K, V]) Contains(key K) bool {
+	return m.entries.Contains(key)
+}
+
+// Set добавляет ключ в конец порядка. Перезапись существующего ключа позицию не меняет.
+func (m *OrderedMap[K, V]) Set(key K, value V) *OrderedMap[K, V] {
+	if e, ok := m.entries.Get(key); ok {
+		return &OrderedMap[K, V]{
+			entries: m.entries.Set(key, orderedEntry[V]{value: value, seq: e.seq}),
+			order:   m.order,
+			nextSeq: m.nextSeq,
+		}
+	}
+
+	return &OrderedMap[K, V]{
+		entries: m.entries.Set(key, orderedEntry[V]{value: value, seq: m.nextSeq}),
+		order:   m.order.Set(m.nextSeq, key),
+		nextSeq: m.nextSeq + 1,
+	}
+}
+
+func (m *OrderedMap[K, V]) Delete(key K) *OrderedMap[K, V] {
+	e, ok := m.entries.Get(key)
+	if !ok {
+		return m
+	}
+
+	return &OrderedMap[K, V]{
+		entries: m.entries.Delete(key),
+		order:   m.order.Delete(e.seq),
+		nextSeq: m.nextSeq,
+	}
+}
+
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, key := range m.order.All() {
+			e, _ := m.entries.Get(key)
+			if !yield(key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+func (m *OrderedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for _, key := range m.order.All() {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+func (m *OrderedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
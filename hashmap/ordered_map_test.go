@@ -0,0 +1,117 @@
+package hashmap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func orderedKeys[K comparable, V any](m *OrderedMap[K, V]) []K {
+	var keys []K
+	for k := range m.Keys() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestOrderedMap_SetAndGet(t *testing.T) {
+	t.Run("добавление и получение элементов", func(t *testing.T) {
+		m := NewOrderedMap[string, int]().Set("one", 1).Set("two", 2).Set("three", 3)
+
+		assert.Equal(t, 3, m.Len(), "после добавления 3 элементов длина должна быть 3")
+
+		val, ok := m.Get("two")
+		require.True(t, ok, "ключ 'two' должен существовать")
+		assert.Equal(t, 2, val)
+
+		_, ok = m.Get("four")
+		assert.False(t, ok, "Get несуществующего ключа должен вернуть false")
+		assert.True(t, m.Contains("one"))
+	})
+}
+
+func TestOrderedMap_InsertionOrder(t *testing.T) {
+	t.Run("итерация в порядке вставки", func(t *testing.T) {
+		m := NewOrderedMap[string, int]()
+		expected := make([]string, 0, 100)
+		for i := 99; i >= 0; i-- {
+			key := fmt.Sprintf("key_%d", i)
+			m = m.Set(key, i)
+			expected = append(expected, key)
+		}
+
+		assert.Equal(t, expected, orderedKeys(m))
+	})
+
+	t.Run("перезапись не меняет позицию", func(t *testing.T) {
+		m := NewOrderedMap[string, int]().Set("a", 1).Set("b", 2).Set("c", 3)
+
+		m2 := m.Set("a", 100)
+
+		assert.Equal(t, []string{"a", "b", "c"}, orderedKeys(m2))
+		val, _ := m2.Get("a")
+		assert.Equal(t, 100, val)
+		val, _ = m.Get("a")
+		assert.Equal(t, 1, val, "оригинал не должен измениться")
+	})
+
+	t.Run("повторная вставка после удаления идёт в конец", func(t *testing.T) {
+		m := NewOrderedMap[string, int]().Set("a", 1).Set("b", 2).Set("c", 3)
+
+		m2 := m.Delete("a").Set("a", 10)
+
+		assert.Equal(t, []string{"b", "c", "a"}, orderedKeys(m2))
+		assert.Equal(t, []string{"a", "b", "c"}, orderedKeys(m), "оригинал не должен измениться")
+	})
+
+	t.Run("значения идут в порядке вставки", func(t *testing.T) {
+		m := NewOrderedMap[int, string]().Set(3, "c").Set(1, "a").Set(2, "b")
+
+		var values []string
+		for v := range m.Values() {
+			values = append(values, v)
+		}
+
+		assert.Equal(t, []string{"c", "a", "b"}, values)
+	})
+}
+
+func TestOrderedMap_Delete(t *testing.T) {
+	t.Run("удаление существующего ключа", func(t *testing.T) {
+		m := NewOrderedMap[string, int]().Set("a", 1).Set("b", 2).Set("c", 3)
+
+		m2 := m.Delete("b")
+
+		assert.Equal(t, 3, m.Len(), "оригинал не должен измениться")
+		assert.Equal(t, 2, m2.Len())
+		assert.False(t, m2.Contains("b"))
+		assert.Equal(t, []string{"a", "c"}, orderedKeys(m2))
+	})
+
+	t.Run("удаление несуществующего ключа", func(t *testing.T) {
+		m := NewOrderedMap[string, int]().Set("a", 1)
+
+		assert.Same(t, m, m.Delete("missing"))
+	})
+}
+
+func TestOrderedMap_Iterator(t *testing.T) {
+	t.Run("ранний выход из итератора", func(t *testing.T) {
+		m := NewOrderedMap[int, int]()
+		for i := 0; i < 10; i++ {
+			m = m.Set(i, i)
+		}
+
+		var keys []int
+		for k := range m.All() {
+			keys = append(keys, k)
+			if k == 2 {
+				break
+			}
+		}
+
+		assert.Equal(t, []int{0, 1, 2}, keys)
+	})
+}
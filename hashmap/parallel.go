@@ -0,0 +1,94 @@
+package hashmap
+
+import "github.com/ykhdr/persistent-data-structures/internal/parallel"
+
+// Параллельные операции обрабатывают поддеревья корня в workers горутинах; неположительное workers
+// означает GOMAXPROCS. Записи, лежащие прямо в корне, обрабатываются отдельной задачей.
+// Деление определяется формой дерева, а не числом горутин, поэтому результат не зависит от workers
+// и порядка выполнения. Функции вызываются конкурентно и не должны разделять изменяемое состояние.
+// Все четыре операции - функции пакета, как MapValues, Filter и Reduce и параллельные операции array:
+// ParallelMap меняет тип значений, а метод в Go не может ввести свой параметр типа.
+
+// ParallelMap - параллельный MapValues: поддеревья корня отображаются независимо, и результат
+// повторяет форму дерева m
+func ParallelMap[K comparable, V, U any](m *HashMap[K, V], workers int, fn func(K, V) U) *HashMap[K, U] {
+	root := m.root.resolve()
+	mapped := &hmapNode[K, U]{dataMap: root.dataMap, nodeMap: root.nodeMap}
+	if len(root.entries) > 0 {
+		mapped.entries = make([]entry[K, U], len(root.entries))
+		for i, e := range root.entries {
+			mapped.entries[i] = entry[K, U]{key: e.key, value: fn(e.key, e.value)}
+		}
+	}
+	if len(root.nodes) > 0 {
+		mapped.nodes = make([]*hmapNode[K, U], len(root.nodes))
+		parallel.Run(workers, len(root.nodes), func(i int) {
+			mapped.nodes[i] = mapNode(root.nodes[i], fn)
+		})
+	}
+
+	return &HashMap[K, U]{
+		root:   mapped,
+		len:    m.len,
+		seed:   m.seed,
+		hashFn: m.hashFn,
+	}
+}
+
+// ParallelFilter - параллельный Filter: поддеревья корня фильтруются независимо, неизменённые
+// переходят в результат тем же указателем
+func ParallelFilter[K comparable, V any](m *HashMap[K, V], workers int, pred func(K, V) bool) *HashMap[K, V] {
+	kept, _, keptLen, _ := m.splitNode(m.root, 0, pred, false, workers)
+	if keptLen == m.len {
+		return m
+	}
+	return m.withRoot(kept, keptLen)
+}
+
+// ParallelReduce сворачивает записи корня и каждое его поддерево функцией fn, начиная с identity,
+// и объединяет результаты в порядке обхода All через combine. Результат совпадает с
+// Reduce(m, identity, fn), если combine ассоциативна, identity - её нейтральный элемент, а fn
+// согласована с combine: fn(a, k, v) == combine(a, fn(identity, k, v)).
+func ParallelReduce[K comparable, V, A any](m *HashMap[K, V], workers int, identity A, fn func(A, K, V) A, combine func(A, A) A) A {
+	root := m.root.resolve()
+	results := make([]A, len(root.nodes)+1)
+	parallel.Run(workers, len(results), func(i int) {
+		acc := identity
+		reduce := func(k K, v V) bool {
+			acc = fn(acc, k, v)
+			return true
+		}
+		if i == 0 {
+			for _, e := range root.entries {
+				reduce(e.key, e.value)
+			}
+		} else {
+			m.iterNode(root.nodes[i-1], reduce)
+		}
+		results[i] = acc
+	})
+
+	acc := identity
+	for _, r := range results {
+		acc = combine(acc, r)
+	}
+	return acc
+}
+
+// ParallelForEach вызывает fn для каждой записи. Порядок вызовов не определён.
+func ParallelForEach[K comparable, V any](m *HashMap[K, V], workers int, fn func(K, V)) {
+	root := m.root.resolve()
+	visit := func(k K, v V) bool {
+		fn(k, v)
+		return true
+	}
+	parallel.Run(workers, len(root.nodes)+1, func(i int) {
+		if i == 0 {
+			for _, e := range root.entries {
+				fn(e.key, e.value)
+			}
+			return
+		}
+		m.iterNode(root.nodes[i-1], visit)
+	})
+}
@@ -0,0 +1,123 @@
+package hashmap
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashMap_Parallel(t *testing.T) {
+	hashers := map[string]Option[int, int]{
+		"seed":             WithSeed[int, int](11),
+		"16 значений хэша": WithHashFunc[int, int](func(k int) uint64 { return uint64(k % 16) }),
+		"одинаковый хэш":   WithHashFunc[int, int](func(int) uint64 { return 7 }),
+	}
+	pred := func(k, _ int) bool { return k%3 != 0 }
+	sum := func(acc, _, v int) int { return acc + v }
+	add := func(a, b int) int { return a + b }
+
+	for name, opt := range hashers {
+		t.Run(name, func(t *testing.T) {
+			for _, size := range []int{0, 1, 33, 1057, 40000} {
+				if size > 1057 && name == "одинаковый хэш" {
+					continue
+				}
+				source := make(map[int]int, size)
+				for i := 0; i < size; i++ {
+					source[i] = i * 10
+				}
+				m := FromMap(source, opt)
+
+				for _, workers := range []int{1, 2, 8, -1} {
+					mapped := ParallelMap(m, workers, func(k, v int) string { return strconv.Itoa(k + v) })
+					assert.Equal(t, dumpShape(MapValues(m, func(k, v int) string { return strconv.Itoa(k + v) })), dumpShape(mapped),
+						"размер %d workers %d", size, workers)
+
+					filtered := ParallelFilter(m, workers, pred)
+					assert.Equal(t, dumpShape(Filter(m, pred)), dumpShape(filtered), "размер %d workers %d", size, workers)
+
+					assert.Equal(t, Reduce(m, 0, sum), ParallelReduce(m, workers, 0, sum, add), "размер %d workers %d", size, workers)
+
+					var mu sync.Mutex
+					seen := make(map[int]int, size)
+					ParallelForEach(m, workers, func(k, v int) {
+						mu.Lock()
+						seen[k] = v
+						mu.Unlock()
+					})
+					assert.Equal(t, source, seen, "размер %d workers %d", size, workers)
+				}
+			}
+		})
+	}
+}
+
+func TestHashMap_ParallelDeterminism(t *testing.T) {
+	m := NewHashMap[string, int]()
+	for i := 0; i < 20000; i++ {
+		m = m.Set(fmt.Sprintf("key-%d", i), i)
+	}
+
+	// сбор ключей в срез ассоциативен, но не коммутативен: результат повторяет порядок All
+	collect := func(acc []string, k string, _ int) []string { return append(slices.Clip(acc), k) }
+	concat := func(a, b []string) []string { return append(slices.Clip(a), b...) }
+	expected := slices.Collect(m.Keys())
+	for _, workers := range []int{1, 2, 3, 8, 64, -1} {
+		assert.Equal(t, expected, ParallelReduce(m, workers, nil, collect, concat), "workers %d", workers)
+	}
+}
+
+func TestHashMap_ParallelFilterSharing(t *testing.T) {
+	m := NewHashMap[int, int]()
+	for i := 0; i < 5000; i++ {
+		m = m.Set(i, i)
+	}
+	assert.Same(t, m, ParallelFilter(m, 4, func(int, int) bool { return true }))
+	assert.Equal(t, 0, ParallelFilter(m, 4, func(int, int) bool { return false }).Len())
+
+	// удаление одного ключа меняет только его путь, остальные поддеревья корня разделяются
+	filtered := ParallelFilter(m, 4, func(k, _ int) bool { return k != 42 })
+	require.Equal(t, 4999, filtered.Len())
+	shared := 0
+	for _, child := range filtered.root.nodes {
+		if slices.Contains(m.root.nodes, child) {
+			shared++
+		}
+	}
+	assert.Equal(t, len(m.root.nodes)-1, shared)
+}
+
+func TestHashMap_ParallelDiskBacked(t *testing.T) {
+	f, err := CreateHashMapFile[int, int](filepath.Join(t.TempDir(), "hashmap.pds"), 4)
+	require.NoError(t, err)
+	defer f.Close()
+
+	source := map[int]int{}
+	m := f.Empty()
+	for i := 0; i < 3000; i++ {
+		m = m.Set(i, i)
+		source[i] = i
+	}
+	require.NoError(t, f.Checkpoint(m))
+	m, err = f.Load()
+	require.NoError(t, err)
+
+	evens := ParallelFilter(m, 4, func(k, _ int) bool { return k%2 == 0 })
+	assert.Equal(t, f, evens.loader, "результат ParallelFilter хранится в том же файле")
+	maps.DeleteFunc(source, func(k, _ int) bool { return k%2 != 0 })
+	assert.Equal(t, source, evens.ToMap())
+
+	doubled := ParallelMap(m, 4, func(_, v int) int { return v * 2 })
+	assert.Nil(t, doubled.loader)
+	value, _ := doubled.Get(10)
+	assert.Equal(t, 20, value)
+
+	assert.Equal(t, 3000*2999/2, ParallelReduce(m, 4, 0, func(acc, _, v int) int { return acc + v }, func(a, b int) int { return a + b }))
+}
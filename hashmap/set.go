@@ -0,0 +1,40 @@
+package hashmap
+
+import "iter"
+
+type HashSet[T comparable] struct {
+	items *HashMap[T, struct{}]
+}
+
+func NewHashSet[T comparable]() *HashSet[T] {
+	return &HashSet[T]{
+		items: NewHashMap[T, struct{}](),
+	}
+}
+
+func (s *HashSet[T]) Len() int {
+	return s.items.Len()
+}
+
+func (s *HashSet[T]) Contains(value T) bool {
+	return s.items.Contains(value)
+}
+
+func (s *HashSet[T]) Add(value T) *HashSet[T] {
+	if s.items.Contains(value) {
+		return s
+	}
+	return &HashSet[T]{items: s.items.Set(value, struct{}{})}
+}
+
+func (s *HashSet[T]) Remove(value T) *HashSet[T] {
+	newItems := s.items.Delete(value)
+	if newItems == s.items {
+		return s
+	}
+	return &HashSet[T]{items: newItems}
+}
+
+func (s *HashSet[T]) All() iter.Seq[T] {
+	return s.items.Keys()
+}
@@ -0,0 +1,38 @@
+package hashmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashSet_AddRemove(t *testing.T) {
+	t.Run("добавление и удаление элементов", func(t *testing.T) {
+		s1 := NewHashSet[string]().Add("a").Add("b")
+		s2 := s1.Add("c").Remove("a")
+
+		assert.Equal(t, 2, s1.Len(), "оригинал не должен измениться")
+		assert.True(t, s1.Contains("a"))
+		assert.Equal(t, 2, s2.Len())
+		assert.False(t, s2.Contains("a"))
+		assert.True(t, s2.Contains("c"))
+	})
+
+	t.Run("повторное добавление возвращает то же множество", func(t *testing.T) {
+		s := NewHashSet[int]().Add(1)
+
+		assert.Same(t, s, s.Add(1))
+		assert.Same(t, s, s.Remove(2))
+	})
+
+	t.Run("итерация по элементам", func(t *testing.T) {
+		s := NewHashSet[int]().Add(1).Add(2).Add(3)
+
+		sum := 0
+		for v := range s.All() {
+			sum += v
+		}
+
+		assert.Equal(t, 6, sum)
+	})
+}
@@ -3,90 +3,132 @@ package hashmap
 import (
 	"hash/maphash"
 	"iter"
+	"math/bits"
+
+	pds "github.com/ykhdr/persistent-data-structures"
 )
 
-const defaultBuckets = 256
+const (
+	defaultBuckets = 256
+	// minBuckets - наименьшее число бакетов
+	minBuckets = 16
+)
 
-type ShardedHashMap[K comparable, V any] struct {
+type shardPage[K comparable, V any] struct {
 	buckets []map[K]V
-	len     int
-	seed    maphash.Seed
 }
 
-func NewShardedHashMap[K comparable, V any]() *ShardedHashMap[K, V] {
-	return &ShardedHashMap[K, V]{
-		buckets: make([]map[K]V, defaultBuckets),
-		len:     0,
-		seed:    maphash.MakeSeed(),
+// ShardedHashMap распределяет записи по бакетам - обычным map, которые копируются целиком при изменении.
+// Бакеты лежат в двухуровневом массиве: каталог указателей на страницы, и в каталоге, и в странице
+// около √(число бакетов) элементов (см. pageBitsFor). Set и Delete копируют каталог и одну страницу,
+// поэтому стоимость изменения растёт как √buckets, а не как число бакетов.
+type ShardedHashMap[K comparable, V any] struct {
+	pages    []*shardPage[K, V]
+	pageBits uint   // в странице 1 << pageBits бакетов
+	mask     uint64 // число бакетов - 1, число бакетов - степень двойки
+	len      int
+	maxLoad  int // 0 - без автоматического решардинга
+	seed     maphash.Seed
+}
+
+var _ pds.Map[string, int, *ShardedHashMap[string, int]] = (*ShardedHashMap[string, int])(nil)
+
+// ShardedOption настраивает ShardedHashMap при создании
+type ShardedOption[K comparable, V any] func(*ShardedHashMap[K, V])
+
+// WithBuckets задаёт начальное число бакетов. Оно округляется вверх до степени двойки,
+// но не меньше minBuckets.
+func WithBuckets[K comparable, V any](n int) ShardedOption[K, V] {
+	return func(m *ShardedHashMap[K, V]) {
+		m.mask = uint64(bucketCount(n) - 1)
+	}
+}
+
+// WithMaxLoad включает автоматический решардинг: после превышения среднего размера бакета n
+// Set удваивает число бакетов. Решардинг перестраивает всё отображение за O(n), и его стоимость
+// амортизируется, только пока каждая версия изменяется один раз: Set из старой версии, стоящей
+// на пороге, снова платит за полный решардинг. Поэтому по умолчанию (и при n == 0) решардинга нет.
+func WithMaxLoad[K comparable, V any](n int) ShardedOption[K, V] {
+	return func(m *ShardedHashMap[K, V]) {
+		m.maxLoad = max(n, 0)
+	}
+}
+
+func bucketCount(n int) int {
+	count := minBuckets
+	for count < n {
+		count <<= 1
+	}
+	return count
+}
+
+func NewShardedHashMap[K comparable, V any](opts ...ShardedOption[K, V]) *ShardedHashMap[K, V] {
+	m := &ShardedHashMap[K, V]{
+		mask: defaultBuckets - 1,
+		len:  0,
+		seed: maphash.MakeSeed(),
 	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	m.pageBits = pageBitsFor(m.Buckets())
+	m.pages = make([]*shardPage[K, V], m.Buckets()>>m.pageBits)
+	empty := &shardPage[K, V]{buckets: make([]map[K]V, 1<<m.pageBits)}
+	for i := range m.pages {
+		// пустые страницы не изменяются, поэтому их можно разделять
+		m.pages[i] = empty
+	}
+	return m
+}
+
+// pageBitsFor делит биты номера бакета между каталогом и страницей поровну, отдавая лишний бит
+// странице: при 256 бакетах это 16 страниц по 16, при 2^20 - 1024 страницы по 1024
+func pageBitsFor(buckets int) uint {
+	return uint(bits.TrailingZeros(uint(buckets))+1) / 2
 }
 
 func (m *ShardedHashMap[K, V]) Len() int {
 	return m.len
 }
 
-func (m *ShardedHashMap[K, V]) hash(key K) uint64 {
-	var h maphash.Hash
-	h.SetSeed(m.seed)
-
-	switch k := any(key).(type) {
-	case string:
-		h.WriteString(k)
-	case int:
-		var buf [8]byte
-		for i := 0; i < 8; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	case int64:
-		var buf [8]byte
-		for i := 0; i < 8; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	case int32:
-		var buf [4]byte
-		for i := 0; i < 4; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	case uint:
-		var buf [8]byte
-		for i := 0; i < 8; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	case uint64:
-		var buf [8]byte
-		for i := 0; i < 8; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	case uint32:
-		var buf [4]byte
-		for i := 0; i < 4; i++ {
-			buf[i] = byte(k >> (i * 8))
-		}
-		h.Write(buf[:])
-	default:
-		h.WriteString(any(key).(string))
-	}
+// Buckets возвращает текущее число бакетов
+func (m *ShardedHashMap[K, V]) Buckets() int {
+	return int(m.mask) + 1
+}
 
-	return h.Sum64()
+func (m *ShardedHashMap[K, V]) hash(key K) uint64 {
+	return maphashKey(m.seed, key)
 }
 
 func (m *ShardedHashMap[K, V]) bucketIndex(key K) int {
-	return int(m.hash(key) % uint64(len(m.buckets)))
+	return int(m.hash(key) & m.mask)
+}
+
+func (m *ShardedHashMap[K, V]) bucket(idx int) map[K]V {
+	return m.pages[idx>>m.pageBits].buckets[idx&(1<<m.pageBits-1)]
+}
+
+// withBucket возвращает каталог страниц, в котором бакет idx заменён на b.
+// Копируются только каталог и одна страница - около 2√buckets указателей.
+func (m *ShardedHashMap[K, V]) withBucket(idx int, b map[K]V) []*shardPage[K, V] {
+	pages := make([]*shardPage[K, V], len(m.pages))
+	copy(pages, m.pages)
+
+	old := m.pages[idx>>m.pageBits]
+	page := &shardPage[K, V]{buckets: make([]map[K]V, len(old.buckets))}
+	copy(page.buckets, old.buckets)
+	page.buckets[idx&(1<<m.pageBits-1)] = b
+	pages[idx>>m.pageBits] = page
+	return pages
 }
 
 func (m *ShardedHashMap[K, V]) Get(key K) (V, bool) {
 	var zero V
-	if len(m.buckets) == 0 {
+	if len(m.pages) == 0 {
 		return zero, false
 	}
 
-	i := m.bucketIndex(key)
-	b := m.buckets[i]
+	b := m.bucket(m.bucketIndex(key))
 	if b == nil {
 		return zero, false
 	}
@@ -102,35 +144,71 @@ func (m *ShardedHashMap[K, V]) Contains(key K) bool {
 
 func (m *ShardedHashMap[K, V]) Set(key K, value V) *ShardedHashMap[K, V] {
 	i := m.bucketIndex(key)
-	oldB := m.buckets[i]
+	oldB := m.bucket(i)
 
 	_, existed := oldB[key]
 
-	newBuckets := make([]map[K]V, len(m.buckets))
-	copy(newBuckets, m.buckets)
-
 	newB := make(map[K]V, len(oldB)+1)
 	for k, v := range oldB {
 		newB[k] = v
 	}
 	newB[key] = value
-	newBuckets[i] = newB
 
 	newLen := m.len
 	if !existed {
 		newLen++
 	}
 
-	return &ShardedHashMap[K, V]{
-		buckets: newBuckets,
-		len:     newLen,
+	next := &ShardedHashMap[K, V]{
+		pages:    m.withBucket(i, newB),
+		pageBits: m.pageBits,
+		mask:     m.mask,
+		len:      newLen,
+		maxLoad:  m.maxLoad,
+		seed:     m.seed,
+	}
+	if m.maxLoad > 0 && newLen > m.maxLoad*m.Buckets() {
+		return next.reshard(m.Buckets() * 2)
+	}
+	return next
+}
+
+// reshard раскладывает записи по buckets бакетам. Результат - новая версия, старые версии не меняются.
+func (m *ShardedHashMap[K, V]) reshard(buckets int) *ShardedHashMap[K, V] {
+	resharded := &ShardedHashMap[K, V]{
+		mask:    uint64(bucketCount(buckets) - 1),
+		maxLoad: m.maxLoad,
 		seed:    m.seed,
 	}
+	resharded.pageBits = pageBitsFor(resharded.Buckets())
+	resharded.pages = make([]*shardPage[K, V], resharded.Buckets()>>resharded.pageBits)
+	for i := range resharded.pages {
+		resharded.pages[i] = &shardPage[K, V]{buckets: make([]map[K]V, 1<<resharded.pageBits)}
+	}
+	for k, v := range m.All() {
+		resharded.put(k, v)
+	}
+	return resharded
+}
+
+// put изменяет отображение на месте. Используется только при построении новой версии.
+func (m *ShardedHashMap[K, V]) put(key K, value V) {
+	idx := m.bucketIndex(key)
+	page := m.pages[idx>>m.pageBits]
+	b := page.buckets[idx&(1<<m.pageBits-1)]
+	if b == nil {
+		b = make(map[K]V)
+		page.buckets[idx&(1<<m.pageBits-1)] = b
+	}
+	if _, ok := b[key]; !ok {
+		m.len++
+	}
+	b[key] = value
 }
 
 func (m *ShardedHashMap[K, V]) Delete(key K) *ShardedHashMap[K, V] {
 	i := m.bucketIndex(key)
-	oldB := m.buckets[i]
+	oldB := m.bucket(i)
 	if oldB == nil {
 		return m
 	}
@@ -139,39 +217,36 @@ func (m *ShardedHashMap[K, V]) Delete(key K) *ShardedHashMap[K, V] {
 		return m
 	}
 
-	newBuckets := make([]map[K]V, len(m.buckets))
-	copy(newBuckets, m.buckets)
-
-	if len(oldB) == 1 {
-		// после удаления бакет станет пустым
-		newBuckets[i] = nil
-	} else {
-		newB := make(map[K]V, len(oldB)-1)
+	var newB map[K]V
+	// после удаления последней записи бакет становится пустым (nil)
+	if len(oldB) > 1 {
+		newB = make(map[K]V, len(oldB)-1)
 		for k, v := range oldB {
 			if k == key {
 				continue
 			}
 			newB[k] = v
 		}
-		newBuckets[i] = newB
 	}
 
 	return &ShardedHashMap[K, V]{
-		buckets: newBuckets,
-		len:     m.len - 1,
-		seed:    m.seed,
+		pages:    m.withBucket(i, newB),
+		pageBits: m.pageBits,
+		mask:     m.mask,
+		len:      m.len - 1,
+		maxLoad:  m.maxLoad,
+		seed:     m.seed,
 	}
 }
 
 func (m *ShardedHashMap[K, V]) All() iter.Seq2[K, V] {
 	return func(yield func(K, V) bool) {
-		for _, b := range m.buckets {
-			if b == nil {
-				continue
-			}
-			for k, v := range b {
-				if !yield(k, v) {
-					return
+		for _, page := range m.pages {
+			for _, b := range page.buckets {
+				for k, v := range b {
+					if !yield(k, v) {
+						return
+					}
 				}
 			}
 		}
@@ -5,7 +5,6 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/ykhdr/persistent-data-structures/array"
 )
 
 func TestShardedHashMap_SetAndGet(t *testing.T) {
@@ -198,20 +197,6 @@ func TestShardedHashMap_Values(t *testing.T) {
 	})
 }
 
-func TestShardedHashMap_NestedStructures(t *testing.T) {
-	t.Run("хранение векторов в качестве значений", func(t *testing.T) {
-		inner := array.NewVector[int]().Append(1).Append(2).Append(3)
-		m := NewShardedHashMap[string, *array.Vector[int]]().Set("numbers", inner)
-
-		retrieved, ok := m.Get("numbers")
-		require.True(t, ok, "ключ 'numbers' должен существовать")
-
-		val, _ := retrieved.Get(0)
-		assert.Equal(t, 1, val, "первый элемент вложенного вектора должен быть 1")
-		assert.Equal(t, 3, retrieved.Len(), "вложенный вектор должен содержать 3 элемента")
-	})
-}
-
 func TestShardedHashMap_Contains(t *testing.T) {
 	t.Run("проверка наличия ключа", func(t *testing.T) {
 		m := NewShardedHashMap[string, int]().Set("exists", 1)
@@ -220,3 +205,87 @@ func TestShardedHashMap_Contains(t *testing.T) {
 		assert.False(t, m.Contains("missing"), "Contains должен вернуть false для отсутствующего ключа")
 	})
 }
+
+func TestShardedHashMap_Options(t *testing.T) {
+	t.Run("число бакетов округляется до степени двойки", func(t *testing.T) {
+		assert.Equal(t, defaultBuckets, NewShardedHashMap[int, int]().Buckets())
+		assert.Equal(t, 1024, NewShardedHashMap(WithBuckets[int, int](1000)).Buckets())
+		assert.Equal(t, minBuckets, NewShardedHashMap(WithBuckets[int, int](1)).Buckets())
+	})
+
+	t.Run("решардинг при превышении среднего размера бакета", func(t *testing.T) {
+		m := NewShardedHashMap(WithBuckets[int, int](16), WithMaxLoad[int, int](4))
+		versions := []*ShardedHashMap[int, int]{m}
+		for i := 0; i < 1000; i++ {
+			m = m.Set(i, i)
+			versions = append(versions, m)
+		}
+
+		assert.Equal(t, 256, m.Buckets(), "1000 записей по 4 на бакет")
+		assert.LessOrEqual(t, m.Len(), 4*m.Buckets())
+		for i := 0; i < 1000; i++ {
+			val, ok := m.Get(i)
+			require.True(t, ok, "ключ %d должен существовать", i)
+			assert.Equal(t, i, val)
+		}
+
+		// старые версии остаются со своим числом бакетов и содержимым
+		assert.Equal(t, 16, versions[64].Buckets())
+		assert.Equal(t, 64, versions[64].Len())
+		assert.False(t, versions[64].Contains(64))
+		assert.True(t, versions[64].Contains(63))
+	})
+
+	t.Run("без решардинга", func(t *testing.T) {
+		m := NewShardedHashMap(WithBuckets[int, int](16), WithMaxLoad[int, int](0))
+		for i := 0; i < 1000; i++ {
+			m = m.Set(i, i)
+		}
+		assert.Equal(t, 16, m.Buckets())
+		assert.Equal(t, 1000, m.Len())
+	})
+
+	t.Run("по умолчанию решардинг выключен", func(t *testing.T) {
+		m := NewShardedHashMap[int, int]()
+		for i := 0; i < 5000; i++ {
+			m = m.Set(i, i)
+		}
+		assert.Equal(t, defaultBuckets, m.Buckets())
+		assert.Equal(t, 5000, m.Len())
+	})
+}
+
+func TestShardedHashMap_PageSharing(t *testing.T) {
+	m := NewShardedHashMap[int, int]()
+	for i := 0; i < 500; i++ {
+		m = m.Set(i, i)
+	}
+
+	next := m.Set(1000, 1).Delete(7)
+	changed := 0
+	for i := range m.pages {
+		if m.pages[i] != next.pages[i] {
+			changed++
+		}
+	}
+	assert.LessOrEqual(t, changed, 2, "Set и Delete копируют только страницы изменённых бакетов")
+	assert.False(t, next.Contains(7))
+	assert.True(t, m.Contains(7))
+}
+
+func TestShardedHashMap_Directory(t *testing.T) {
+	for buckets, pages := range map[int]int{16: 4, 256: 16, 1 << 11: 32, 1 << 20: 1024} {
+		m := NewShardedHashMap(WithBuckets[int, int](buckets))
+		assert.Len(t, m.pages, pages, "%d бакетов", buckets)
+		assert.Len(t, m.pages[0].buckets, buckets/pages, "%d бакетов", buckets)
+	}
+
+	// после решардинга каталог перестраивается под новое число бакетов
+	m := NewShardedHashMap[int, int]().Set(1, 1).Set(2, 2)
+	resharded := m.reshard(1 << 16)
+	assert.Len(t, resharded.pages, 256)
+	next := resharded.Set(3, 3).Delete(1)
+	assert.Equal(t, 2, next.Len())
+	assert.True(t, next.Contains(2))
+	assert.True(t, resharded.Contains(1))
+}
@@ -0,0 +1,206 @@
+package hashmap
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ykhdr/persistent-data-structures/store"
+)
+
+const (
+	objectInternal byte = iota + 1
+	objectVersion
+)
+
+// storeCacheSize - сколько прочитанных из хранилища узлов HashMapStore держит в памяти
+const storeCacheSize = 4096
+
+// HashMapStore сохраняет версии HashMap в store.Store: каждый узел CHAMP - отдельный объект,
+// адресуемый хэшем содержимого, поэтому неизменённые поддеревья разных версий хранятся один раз.
+//
+// Раскладка узлов в памяти зависит от случайного seed, поэтому, как и HashMapFile, хранилище
+// раскладывает ключи детерминированным hashKey с нулевым seed: одинаковое содержимое даёт одинаковые
+// объекты между запусками. Отображение с другой хэш-функцией Save сначала перестраивает.
+// Ключи записываются в JSON, поэтому для типа ключа, который не восстанавливается из JSON без потерь,
+// Save и Load возвращают ErrKeyType.
+//
+// Load читает только объект версии, а узлы подгружаются при первом обращении и держатся в кэше
+// на storeCacheSize узлов. Ошибка чтения узла или повреждённый объект приводят к панике
+// *store.NodeError при обращении к нему, поэтому объекты загруженной версии должны оставаться
+// достижимыми из ссылок, пока версия используется.
+type HashMapStore[K comparable, V any] struct {
+	store *store.Store
+	refs  *store.HashRefs
+	cache *store.Cache[*hmapNode[K, V]]
+	// хэши резидентных узлов последней сохранённой версии:
+	// при следующем Save общие с ней поддеревья не обходятся повторно
+	hashes map[*hmapNode[K, V]]store.Hash
+}
+
+func NewHashMapStore[K comparable, V any](s *store.Store) *HashMapStore[K, V] {
+	return &HashMapStore[K, V]{
+		store:  s,
+		refs:   store.NewHashRefs(),
+		cache:  store.NewCache[*hmapNode[K, V]](storeCacheSize),
+		hashes: make(map[*hmapNode[K, V]]store.Hash),
+	}
+}
+
+// Empty возвращает пустое отображение с раскладкой хранилища: его версии Save записывает без перестройки
+func (s *HashMapStore[K, V]) Empty() *HashMap[K, V] {
+	m := NewHashMap[K, V]()
+	m.hashFn = diskHash[K]
+	m.loader = s
+	return m
+}
+
+// Save записывает версию и возвращает хэш её корневого объекта.
+// Для версий, полученных из Empty или Load этого HashMapStore, записываются только узлы,
+// изменённые с тех пор; остальные отображения перестраиваются и обходятся целиком.
+func (s *HashMapStore[K, V]) Save(m *HashMap[K, V]) (root store.Hash, err error) {
+	if err := checkDiskKey[K](); err != nil {
+		return store.Hash{}, err
+	}
+	defer s.store.Pin()()
+	defer store.Recover(&err)
+
+	if m.loader != s {
+		entries := make([]entry[K, V], 0, m.len)
+		for k, v := range m.All() {
+			entries = append(entries, entry[K, V]{key: k, value: v})
+		}
+		m = fromEntries(s.Empty(), entries)
+	}
+
+	hashes := make(map[*hmapNode[K, V]]store.Hash)
+	h, err := s.saveNode(m.root, hashes)
+	if err != nil {
+		return store.Hash{}, err
+	}
+
+	data := binary.AppendUvarint([]byte{objectVersion}, uint64(m.len))
+	if root, err = s.store.Put(store.Object{Links: []store.Hash{h}, Data: data}); err != nil {
+		return store.Hash{}, err
+	}
+
+	s.hashes = hashes
+	return root, nil
+}
+
+func (s *HashMapStore[K, V]) saveNode(node *hmapNode[K, V], hashes map[*hmapNode[K, V]]store.Hash) (store.Hash, error) {
+	if node.lazy != nil && node.lazy.loader == s {
+		return s.refs.Hash(node.lazy.ref), nil
+	}
+	if h, ok := hashes[node]; ok {
+		return h, nil
+	}
+	if h, ok := s.hashes[node]; ok {
+		hashes[node] = h
+		return h, nil
+	}
+	node = node.resolve()
+
+	var obj store.Object
+	for _, child := range node.nodes {
+		h, err := s.saveNode(child, hashes)
+		if err != nil {
+			return store.Hash{}, err
+		}
+		obj.Links = append(obj.Links, h)
+	}
+
+	stored := diskNode[K, V]{Keys: make([]K, len(node.entries)), Values: make([]V, len(node.entries))}
+	for i, e := range node.entries {
+		stored.Keys[i] = e.key
+		stored.Values[i] = e.value
+	}
+	encoded, err := json.Marshal(stored)
+	if err != nil {
+		return store.Hash{}, err
+	}
+	obj.Data = binary.AppendUvarint([]byte{objectInternal}, uint64(node.dataMap))
+	obj.Data = binary.AppendUvarint(obj.Data, uint64(node.nodeMap))
+	obj.Data = append(obj.Data, encoded...)
+
+	h, err := s.store.Put(obj)
+	if err != nil {
+		return store.Hash{}, err
+	}
+	hashes[node] = h
+	return h, nil
+}
+
+// Load читает версию по хэшу корня. Читается только объект версии: узлы подгружаются
+// при первом обращении, а узлы, уже прочитанные этим HashMapStore, разделяются между версиями.
+func (s *HashMapStore[K, V]) Load(root store.Hash) (*HashMap[K, V], error) {
+	if err := checkDiskKey[K](); err != nil {
+		return nil, err
+	}
+	obj, err := s.store.Get(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(obj.Data) == 0 || obj.Data[0] != objectVersion || len(obj.Links) != 1 {
+		return nil, fmt.Errorf("%w: %s is not a hashmap version", store.ErrCorrupt, root)
+	}
+	length, n := binary.Uvarint(obj.Data[1:])
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: %s bad length", store.ErrCorrupt, root)
+	}
+
+	m := s.Empty()
+	m.root = s.stub(obj.Links[0])
+	m.len = int(length)
+	return m, nil
+}
+
+func (s *HashMapStore[K, V]) stub(h store.Hash) *hmapNode[K, V] {
+	return &hmapNode[K, V]{lazy: &hmapLazy[K, V]{loader: s, ref: s.refs.Ref(h)}}
+}
+
+func (s *HashMapStore[K, V]) loadNode(ref uint64) *hmapNode[K, V] {
+	if node, ok := s.cache.Get(ref); ok {
+		return node
+	}
+
+	h := s.refs.Hash(ref)
+	obj, err := s.store.Get(h)
+	if err != nil {
+		panic(&store.NodeError{Err: fmt.Errorf("hashmap: load node %s: %w", h, err)})
+	}
+	if len(obj.Data) == 0 || obj.Data[0] != objectInternal {
+		panic(&store.NodeError{Err: fmt.Errorf("hashmap: load node %s: %w: unexpected object", h, store.ErrCorrupt)})
+	}
+	dataMap, n := binary.Uvarint(obj.Data[1:])
+	if n <= 0 {
+		panic(&store.NodeError{Err: fmt.Errorf("hashmap: load node %s: %w: bad node", h, store.ErrCorrupt)})
+	}
+	nodeMap, k := binary.Uvarint(obj.Data[1+n:])
+	var stored diskNode[K, V]
+	if k <= 0 || json.Unmarshal(obj.Data[1+n+k:], &stored) != nil || !stored.valid(dataMap, nodeMap, len(obj.Links)) {
+		panic(&store.NodeError{Err: fmt.Errorf("hashmap: load node %s: %w: bad node", h, store.ErrCorrupt)})
+	}
+
+	node := &hmapNode[K, V]{dataMap: uint32(dataMap), nodeMap: uint32(nodeMap)}
+	if len(stored.Keys) > 0 {
+		node.entries = make([]entry[K, V], len(stored.Keys))
+		for i := range stored.Keys {
+			node.entries[i] = entry[K, V]{key: stored.Keys[i], value: stored.Values[i]}
+		}
+	}
+	if len(obj.Links) > 0 {
+		node.nodes = make([]*hmapNode[K, V], len(obj.Links))
+		for i, link := range obj.Links {
+			node.nodes[i] = s.stub(link)
+		}
+	}
+
+	s.cache.Put(ref, node)
+	return node
+}
+
+// spillNode оставляет новые узлы в памяти: в хранилище их записывает следующий Save
+func (s *HashMapStore[K, V]) spillNode(node *hmapNode[K, V]) *hmapNode[K, V] {
+	return node
+}
@@ -0,0 +1,127 @@
+package hashmap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ykhdr/persistent-data-structures/store"
+)
+
+func TestHashMapStore_SaveLoad(t *testing.T) {
+	t.Run("сохранение и загрузка версий", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+
+		for _, size := range []int{0, 1, 100, 5000} {
+			m := buildPersistent(size)
+
+			root, err := NewHashMapStore[int, int](s).Save(m)
+			require.NoError(t, err)
+
+			loaded, err := NewHashMapStore[int, int](s).Load(root)
+			require.NoError(t, err)
+			requireHashMapEqual(t, m, loaded)
+		}
+	})
+
+	t.Run("хэш не зависит от seed и порядка вставки", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+
+		a := NewHashMap[string, int]()
+		b := NewHashMap[string, int]()
+		for i := 0; i < 1000; i++ {
+			a = a.Set(fmt.Sprintf("key_%d", i), i)
+			b = b.Set(fmt.Sprintf("key_%d", 999-i), 999-i)
+		}
+
+		rootA, err := NewHashMapStore[string, int](s).Save(a)
+		require.NoError(t, err)
+		rootB, err := NewHashMapStore[string, int](s).Save(b)
+		require.NoError(t, err)
+
+		assert.Equal(t, rootA, rootB)
+	})
+
+	t.Run("версии разделяют неизменённые объекты", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+		hs := NewHashMapStore[int, int](s)
+
+		base := buildPersistent(10000)
+		root1, err := hs.Save(base)
+		require.NoError(t, err)
+		root2, err := hs.Save(base.Set(42, -1))
+		require.NoError(t, err)
+
+		removed, err := s.GC(root1)
+		require.NoError(t, err)
+		assert.Less(t, removed, 10, "вторая версия должна добавить только путь до изменённого бакета")
+
+		loaded, err := hs.Load(root1)
+		require.NoError(t, err)
+		requireHashMapEqual(t, base, loaded)
+		_, err = hs.Load(root2)
+		assert.ErrorIs(t, err, store.ErrNotFound, "вторая версия должна быть удалена GC")
+	})
+	t.Run("узлы читаются при первом обращении", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+
+		base := buildPersistent(5000)
+		root, err := NewHashMapStore[int, int](s).Save(base)
+		require.NoError(t, err)
+
+		hs := NewHashMapStore[int, int](s)
+		loaded, err := hs.Load(root)
+		require.NoError(t, err)
+		assert.Equal(t, 0, hs.cache.Len(), "Load читает только объект версии")
+
+		val, ok := loaded.Get(1234)
+		require.True(t, ok)
+		assert.Equal(t, 1234, val)
+		assert.Less(t, hs.cache.Len(), 6, "читается только путь до записи")
+
+		requireHashMapEqual(t, base, loaded)
+	})
+
+	t.Run("Save загруженной версии пишет только изменённый путь", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+
+		root1, err := NewHashMapStore[int, int](s).Save(buildPersistent(5000))
+		require.NoError(t, err)
+
+		hs := NewHashMapStore[int, int](s)
+		loaded, err := hs.Load(root1)
+		require.NoError(t, err)
+		changed := loaded.Set(42, -1).Delete(43)
+		root2, err := hs.Save(changed)
+		require.NoError(t, err)
+
+		read := hs.cache.Len()
+		assert.Less(t, read, 12, "Save не читает неизменённые поддеревья")
+
+		removed, err := s.GC(root2)
+		require.NoError(t, err)
+		assert.Less(t, removed, 12, "вторая версия разделяет с первой всё, кроме изменённых путей")
+
+		reloaded, err := NewHashMapStore[int, int](s).Load(root2)
+		require.NoError(t, err)
+		requireHashMapEqual(t, changed, reloaded)
+	})
+
+	t.Run("тип ключа не переживает JSON", func(t *testing.T) {
+		s, err := store.Open(t.TempDir(), store.WithSync(false))
+		require.NoError(t, err)
+		type hidden struct{ id int }
+
+		hs := NewHashMapStore[hidden, int](s)
+		_, err = hs.Save(NewHashMap[hidden, int]().Set(hidden{id: 1}, 1))
+		assert.ErrorIs(t, err, ErrKeyType)
+		_, err = hs.Load(store.Hash{})
+		assert.ErrorIs(t, err, ErrKeyType)
+	})
+}
@@ -0,0 +1,35 @@
+package history
+
+import "encoding/json"
+
+// Codec кодирует версии для журнала DurableHistory
+type Codec[T any] interface {
+	Marshal(v T) ([]byte, error)
+	Unmarshal(data []byte) (T, error)
+}
+
+// DeltaCodec - Codec, который умеет кодировать версию как разницу с предыдущей.
+// Если кодек его реализует, Commit пишет в журнал разницу, а не всю версию.
+// Реализации для persistent-структур: array.NewVectorCodec, hashmap.NewHashMapCodec.
+type DeltaCodec[T any] interface {
+	Codec[T]
+	Diff(prev, next T) ([]byte, error)
+	Apply(prev T, delta []byte) (T, error)
+}
+
+type jsonCodec[T any] struct{}
+
+// JSONCodec кодирует каждую версию целиком через encoding/json
+func JSONCodec[T any]() Codec[T] {
+	return jsonCodec[T]{}
+}
+
+func (jsonCodec[T]) Marshal(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec[T]) Unmarshal(data []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
@@ -0,0 +1,454 @@
+package history
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+)
+
+var ErrCorruptLog = errors.New("history: corrupt log")
+
+// errTornRecord - недописанная последняя запись журнала, оставшаяся после сбоя
+var errTornRecord = errors.New("history: torn record")
+
+const walMagic = "PDSWAL2\n"
+
+// recordHeaderSize - длина записи и crc32 этой длины
+const recordHeaderSize = 8
+
+// типы записей журнала
+const (
+	recordSnapshot byte = iota + 1 // версия целиком
+	recordDelta                    // разница с текущей версией (DeltaCodec)
+	recordPosition                 // новая позиция после Undo / Redo
+)
+
+// SyncPolicy определяет, когда журнал сбрасывается на диск через fsync
+type SyncPolicy int
+
+const (
+	SyncAlways    SyncPolicy = iota // после каждой записи: ни одна подтверждённая операция не теряется
+	SyncSnapshots                   // только после снимков: при сбое теряются записи после последнего снимка
+	SyncNever                       // сброс остаётся на усмотрение ОС
+)
+
+type options struct {
+	sync          SyncPolicy
+	snapshotEvery int
+}
+
+type Option func(*options)
+
+func WithSyncPolicy(policy SyncPolicy) Option {
+	return func(o *options) {
+		o.sync = policy
+	}
+}
+
+// WithSnapshotEvery задаёт, как часто Commit пишет версию целиком вместо разницы.
+// Имеет смысл только для DeltaCodec: с обычным Codec каждая запись - снимок.
+func WithSnapshotEvery(n int) Option {
+	return func(o *options) {
+		o.snapshotEvery = max(n, 1)
+	}
+}
+
+// DurableHistory - History, каждое изменение которой дописывается в журнал (write-ahead log).
+// При открытии журнал проигрывается заново: восстанавливаются все версии и позиция undo.
+// Длина и содержимое каждой записи защищены отдельными crc32; недописанная последняя запись
+// после сбоя отбрасывается, а любое повреждение до конца журнала возвращается как ErrCorruptLog.
+type DurableHistory[T any] struct {
+	history *History[T]
+	codec   Codec[T]
+	opts    options
+	path    string
+	file    logFile
+	// sinceSnapshot - число записей-разниц после последнего снимка
+	sinceSnapshot int
+}
+
+// logFile - файл журнала, открытый на дозапись. Интерфейс позволяет тестам подменить запись.
+type logFile interface {
+	io.ReadWriteSeeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// OpenDurable открывает журнал path или создаёт новый с версией initial.
+// У существующего журнала initial игнорируется.
+func OpenDurable[T any](path string, initial T, codec Codec[T], opts ...Option) (*DurableHistory[T], error) {
+	o := options{sync: SyncAlways, snapshotEvery: 64}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DurableHistory[T]{codec: codec, opts: o, path: path, file: file}
+	if err := d.replay(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if d.history == nil {
+		if err := d.reset(initial); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// reset начинает журнал заново с единственной версией initial
+func (d *DurableHistory[T]) reset(initial T) error {
+	if err := d.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := d.file.Write([]byte(walMagic)); err != nil {
+		return err
+	}
+	data, err := d.codec.Marshal(initial)
+	if err != nil {
+		return err
+	}
+	if err := d.append(recordSnapshot, data); err != nil {
+		return err
+	}
+	d.history = NewHistory(initial)
+	d.sinceSnapshot = 0
+	return nil
+}
+
+func (d *DurableHistory[T]) replay() error {
+	data, err := io.ReadAll(d.file)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if len(data) < len(walMagic) || string(data[:len(walMagic)]) != walMagic {
+		return fmt.Errorf("%w: bad header", ErrCorruptLog)
+	}
+
+	offset := len(walMagic)
+	for offset < len(data) {
+		kind, payload, next, err := readRecord(data, offset)
+		if errors.Is(err, errTornRecord) {
+			// недописанная последняя запись: отрезаем её, чтобы следующие записи шли после целых
+			return d.file.Truncate(int64(offset))
+		}
+		if err != nil {
+			return err
+		}
+		if err := d.apply(kind, payload); err != nil {
+			return fmt.Errorf("%w: record at %d: %v", ErrCorruptLog, offset, err)
+		}
+		offset = next
+	}
+	return nil
+}
+
+// readRecord читает запись по смещению offset и возвращает смещение следующей.
+// errTornRecord означает, что запись - недописанный хвост журнала: она выходит за конец файла
+// по длине, которой можно доверять, или её содержимое не сходится с crc32 и за ней ничего нет.
+// Испорченная длина не доказывает, что запись последняя, поэтому это ErrCorruptLog.
+func readRecord(data []byte, offset int) (kind byte, payload []byte, next int, err error) {
+	rest := data[offset:]
+	if len(rest) < recordHeaderSize {
+		return 0, nil, 0, errTornRecord
+	}
+	if crc32.ChecksumIEEE(rest[:4]) != binary.LittleEndian.Uint32(rest[4:]) {
+		if allZero(rest) {
+			// файловая система увеличила размер файла, но не успела записать данные
+			return 0, nil, 0, errTornRecord
+		}
+		return 0, nil, 0, fmt.Errorf("%w: record at %d length checksum mismatch", ErrCorruptLog, offset)
+	}
+
+	size := int(binary.LittleEndian.Uint32(rest))
+	if size == 0 {
+		return 0, nil, 0, fmt.Errorf("%w: record at %d is empty", ErrCorruptLog, offset)
+	}
+	next = offset + recordHeaderSize + size + 4
+	if next > len(data) {
+		return 0, nil, 0, errTornRecord
+	}
+
+	body := rest[recordHeaderSize : recordHeaderSize+size]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(data[next-4:]) {
+		if next == len(data) {
+			return 0, nil, 0, errTornRecord
+		}
+		return 0, nil, 0, fmt.Errorf("%w: record at %d checksum mismatch", ErrCorruptLog, offset)
+	}
+	return body[0], body[1:], next, nil
+}
+
+func allZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *DurableHistory[T]) apply(kind byte, payload []byte) error {
+	switch kind {
+	case recordSnapshot:
+		v, err := d.codec.Unmarshal(payload)
+		if err != nil {
+			return err
+		}
+		if d.history == nil {
+			d.history = NewHistory(v)
+		} else {
+			d.history.Commit(v)
+		}
+		d.sinceSnapshot = 0
+
+	case recordDelta:
+		dc, ok := d.codec.(DeltaCodec[T])
+		if !ok || d.history == nil {
+			return errors.New("unexpected delta")
+		}
+		v, err := dc.Apply(d.history.Current(), payload)
+		if err != nil {
+			return err
+		}
+		d.history.Commit(v)
+		d.sinceSnapshot++
+
+	case recordPosition:
+		position, n := binary.Uvarint(payload)
+		if n <= 0 || d.history == nil || position >= uint64(d.history.VersionCount()) {
+			return errors.New("bad position")
+		}
+		d.history.current = int(position)
+
+	default:
+		return fmt.Errorf("unknown record kind %d", kind)
+	}
+	return nil
+}
+
+// append дописывает запись. Если запись или её сброс на диск не удались, файл обрезается до прежней длины:
+// иначе недописанная запись осталась бы перед следующими, и журнал больше не проигрывался бы.
+func (d *DurableHistory[T]) append(kind byte, payload []byte) error {
+	end, err := d.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	err = writeRecord(d.file, kind, payload)
+	if err == nil && (d.opts.sync == SyncAlways || (d.opts.sync == SyncSnapshots && kind == recordSnapshot)) {
+		err = d.file.Sync()
+	}
+	if err != nil {
+		if truncErr := d.file.Truncate(end); truncErr != nil {
+			return errors.Join(err, truncErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// writeRecord пишет запись: длину, crc32 длины, тип и данные, crc32 типа и данных
+func writeRecord(w io.Writer, kind byte, payload []byte) error {
+	record := make([]byte, recordHeaderSize+1+len(payload)+4)
+	binary.LittleEndian.PutUint32(record, uint32(1+len(payload)))
+	binary.LittleEndian.PutUint32(record[4:], crc32.ChecksumIEEE(record[:4]))
+	body := record[recordHeaderSize : recordHeaderSize+1+len(payload)]
+	body[0] = kind
+	copy(body[1:], payload)
+	binary.LittleEndian.PutUint32(record[recordHeaderSize+len(body):], crc32.ChecksumIEEE(body))
+	n, err := w.Write(record)
+	if err == nil && n < len(record) {
+		err = io.ErrShortWrite
+	}
+	return err
+}
+
+// encodeCommit кодирует версию next, следующую за prev: разницей или, раз в snapshotEvery записей, целиком
+func (d *DurableHistory[T]) encodeCommit(prev, next T, sinceSnapshot int) (byte, []byte, error) {
+	if dc, ok := d.codec.(DeltaCodec[T]); ok && sinceSnapshot+1 < d.opts.snapshotEvery {
+		delta, err := dc.Diff(prev, next)
+		return recordDelta, delta, err
+	}
+	data, err := d.codec.Marshal(next)
+	return recordSnapshot, data, err
+}
+
+func (d *DurableHistory[T]) Current() T {
+	return d.history.Current()
+}
+
+// Commit записывает newVersion в журнал и только затем делает её текущей
+func (d *DurableHistory[T]) Commit(newVersion T) error {
+	kind, payload, err := d.encodeCommit(d.history.Current(), newVersion, d.sinceSnapshot)
+	if err != nil {
+		return err
+	}
+	if err := d.append(kind, payload); err != nil {
+		return err
+	}
+
+	d.history.Commit(newVersion)
+	if kind == recordSnapshot {
+		d.sinceSnapshot = 0
+	} else {
+		d.sinceSnapshot++
+	}
+	return nil
+}
+
+func (d *DurableHistory[T]) Undo() (T, bool, error) {
+	if !d.history.CanUndo() {
+		var zero T
+		return zero, false, nil
+	}
+	if err := d.appendPosition(d.history.current - 1); err != nil {
+		var zero T
+		return zero, false, err
+	}
+	v, ok := d.history.Undo()
+	return v, ok, nil
+}
+
+func (d *DurableHistory[T]) Redo() (T, bool, error) {
+	if !d.history.CanRedo() {
+		var zero T
+		return zero, false, nil
+	}
+	if err := d.appendPosition(d.history.current + 1); err != nil {
+		var zero T
+		return zero, false, err
+	}
+	v, ok := d.history.Redo()
+	return v, ok, nil
+}
+
+func (d *DurableHistory[T]) appendPosition(position int) error {
+	return d.append(recordPosition, binary.AppendUvarint(nil, uint64(position)))
+}
+
+func (d *DurableHistory[T]) CanUndo() bool {
+	return d.history.CanUndo()
+}
+
+func (d *DurableHistory[T]) CanRedo() bool {
+	return d.history.CanRedo()
+}
+
+func (d *DurableHistory[T]) VersionCount() int {
+	return d.history.VersionCount()
+}
+
+func (d *DurableHistory[T]) CurrentIndex() int {
+	return d.history.CurrentIndex()
+}
+
+func (d *DurableHistory[T]) Versions() iter.Seq2[int, T] {
+	return d.history.Versions()
+}
+
+// Compact переписывает журнал так, чтобы в нём остались только живые версии и текущая позиция:
+// записи отброшенных веток и переходов Undo / Redo исчезают. Новый журнал пишется во временный
+// файл и атомарно заменяет старый.
+func (d *DurableHistory[T]) Compact() error {
+	tmpPath := d.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	sinceSnapshot, err := d.writeCompacted(tmp)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, d.path)
+	}
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+
+	d.file.Close()
+	d.file = tmp
+	d.sinceSnapshot = sinceSnapshot
+	return syncDir(filepath.Dir(d.path))
+}
+
+func (d *DurableHistory[T]) writeCompacted(w io.Writer) (int, error) {
+	if _, err := w.Write([]byte(walMagic)); err != nil {
+		return 0, err
+	}
+
+	var prev T
+	sinceSnapshot := 0
+	for i, v := range d.history.Versions() {
+		kind := recordSnapshot
+		var payload []byte
+		var err error
+		if i == 0 {
+			payload, err = d.codec.Marshal(v)
+		} else {
+			kind, payload, err = d.encodeCommit(prev, v, sinceSnapshot)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if err := writeRecord(w, kind, payload); err != nil {
+			return 0, err
+		}
+
+		if kind == recordSnapshot {
+			sinceSnapshot = 0
+		} else {
+			sinceSnapshot++
+		}
+		prev = v
+	}
+
+	if d.history.CanRedo() {
+		position := binary.AppendUvarint(nil, uint64(d.history.current))
+		if err := writeRecord(w, recordPosition, position); err != nil {
+			return 0, err
+		}
+	}
+	return sinceSnapshot, nil
+}
+
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+// Sync сбрасывает журнал на диск независимо от SyncPolicy
+func (d *DurableHistory[T]) Sync() error {
+	return d.file.Sync()
+}
+
+func (d *DurableHistory[T]) Close() error {
+	if err := d.file.Sync(); err != nil {
+		d.file.Close()
+		return err
+	}
+	return d.file.Close()
+}
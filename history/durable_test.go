@@ -0,0 +1,259 @@
+package history
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ykhdr/persistent-data-structures/array"
+	"github.com/ykhdr/persistent-data-structures/hashmap"
+)
+
+func vectorValues(v *array.Vector[int]) []int {
+	values := []int{}
+	for x := range v.Values() {
+		values = append(values, x)
+	}
+	return values
+}
+
+func requireSameHistory(t *testing.T, expected, actual *DurableHistory[*array.Vector[int]]) {
+	t.Helper()
+	require.Equal(t, expected.VersionCount(), actual.VersionCount())
+	require.Equal(t, expected.CurrentIndex(), actual.CurrentIndex())
+	versions := make(map[int]*array.Vector[int])
+	for i, v := range expected.Versions() {
+		versions[i] = v
+	}
+	for i, v := range actual.Versions() {
+		require.Equal(t, vectorValues(versions[i]), vectorValues(v), "версия %d", i)
+	}
+}
+
+func openVectorHistory(t *testing.T, path string, codec Codec[*array.Vector[int]], opts ...Option) *DurableHistory[*array.Vector[int]] {
+	t.Helper()
+	h, err := OpenDurable(path, array.NewVector[int](), codec, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { h.Close() })
+	return h
+}
+
+func TestDurableHistory_Replay(t *testing.T) {
+	codecs := map[string]Codec[*array.Vector[int]]{
+		"JSONCodec":   JSONCodec[*array.Vector[int]](),
+		"VectorCodec": array.NewVectorCodec[int](),
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "history.wal")
+			h := openVectorHistory(t, path, codec, WithSnapshotEvery(5))
+
+			for i := 0; i < 100; i++ {
+				require.NoError(t, h.Commit(h.Current().Append(i)))
+			}
+			require.NoError(t, h.Commit(h.Current().Set(3, -3)))
+			popped, _, _ := h.Current().Pop()
+			require.NoError(t, h.Commit(popped))
+			_, ok, err := h.Undo()
+			require.NoError(t, err)
+			require.True(t, ok)
+			_, _, err = h.Undo()
+			require.NoError(t, err)
+			_, _, err = h.Redo()
+			require.NoError(t, err)
+			require.NoError(t, h.Close())
+
+			restored := openVectorHistory(t, path, codec, WithSnapshotEvery(5))
+			requireSameHistory(t, h, restored)
+			assert.True(t, restored.CanRedo(), "позиция undo должна восстановиться")
+
+			value, _ := restored.Current().Get(3)
+			assert.Equal(t, -3, value)
+		})
+	}
+}
+
+func TestDurableHistory_BranchOverwrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.wal")
+	h := openVectorHistory(t, path, array.NewVectorCodec[int]())
+
+	require.NoError(t, h.Commit(h.Current().Append(1)))
+	require.NoError(t, h.Commit(h.Current().Append(2)))
+	_, _, err := h.Undo()
+	require.NoError(t, err)
+	require.NoError(t, h.Commit(h.Current().Append(20)))
+	require.NoError(t, h.Close())
+
+	restored := openVectorHistory(t, path, array.NewVectorCodec[int]())
+	assert.Equal(t, 3, restored.VersionCount(), "ветка redo должна быть отброшена")
+	assert.Equal(t, []int{1, 20}, vectorValues(restored.Current()))
+}
+
+func TestDurableHistory_HashMapCodec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.wal")
+	codec := hashmap.NewHashMapCodec[string, int]()
+
+	h, err := OpenDurable(path, hashmap.NewHashMap[string, int](), codec, WithSyncPolicy(SyncNever))
+	require.NoError(t, err)
+	keys := []string{"a", "b", "c", "d", "e"}
+	for i, k := range keys {
+		require.NoError(t, h.Commit(h.Current().Set(k, i)))
+	}
+	require.NoError(t, h.Commit(h.Current().Delete("c").Set("a", 100)))
+	require.NoError(t, h.Close())
+
+	restored, err := OpenDurable(path, hashmap.NewHashMap[string, int](), codec)
+	require.NoError(t, err)
+	defer restored.Close()
+
+	require.Equal(t, 7, restored.VersionCount())
+	current := restored.Current()
+	assert.Equal(t, 4, current.Len())
+	assert.False(t, current.Contains("c"))
+	value, _ := current.Get("a")
+	assert.Equal(t, 100, value)
+}
+
+func TestDurableHistory_Corruption(t *testing.T) {
+	build := func(t *testing.T) string {
+		path := filepath.Join(t.TempDir(), "history.wal")
+		h := openVectorHistory(t, path, array.NewVectorCodec[int]())
+		for i := 0; i < 10; i++ {
+			require.NoError(t, h.Commit(h.Current().Append(i)))
+		}
+		require.NoError(t, h.Close())
+		return path
+	}
+
+	t.Run("недописанная последняя запись отбрасывается", func(t *testing.T) {
+		path := build(t)
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.NoError(t, os.Truncate(path, info.Size()-3))
+
+		restored := openVectorHistory(t, path, array.NewVectorCodec[int]())
+		assert.Equal(t, 10, restored.VersionCount(), "последний коммит должен потеряться")
+
+		// новые записи идут после последней целой записи
+		require.NoError(t, restored.Commit(restored.Current().Append(42)))
+		require.NoError(t, restored.Close())
+		again := openVectorHistory(t, path, array.NewVectorCodec[int]())
+		assert.Equal(t, 11, again.VersionCount())
+	})
+
+	t.Run("повреждение в середине журнала", func(t *testing.T) {
+		path := build(t)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		data[len(walMagic)+10] ^= 0xff
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+
+		_, err = OpenDurable(path, array.NewVector[int](), array.NewVectorCodec[int]())
+		assert.ErrorIs(t, err, ErrCorruptLog)
+	})
+
+	t.Run("испорченная длина записи в середине журнала", func(t *testing.T) {
+		path := build(t)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		// длина второй записи становится больше оставшейся части файла
+		first := len(walMagic)
+		second := first + recordHeaderSize + int(binary.LittleEndian.Uint32(data[first:])) + 4
+		data[second+3] ^= 0x40
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+
+		_, err = OpenDurable(path, array.NewVector[int](), array.NewVectorCodec[int]())
+		assert.ErrorIs(t, err, ErrCorruptLog)
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(data)), info.Size(), "подтверждённые записи не должны отрезаться")
+	})
+
+	t.Run("хвост из нулей после сбоя", func(t *testing.T) {
+		path := build(t)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, append(data, make([]byte, 64)...), 0o644))
+
+		restored := openVectorHistory(t, path, array.NewVectorCodec[int]())
+		assert.Equal(t, 11, restored.VersionCount())
+	})
+
+	t.Run("недописанная запись после ошибки записи", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.wal")
+		h := openVectorHistory(t, path, array.NewVectorCodec[int]())
+		for i := 0; i < 5; i++ {
+			require.NoError(t, h.Commit(h.Current().Append(i)))
+		}
+
+		file := h.file
+		h.file = &shortWriteFile{logFile: file}
+		err := h.Commit(h.Current().Append(100))
+		assert.ErrorIs(t, err, io.ErrShortWrite)
+		assert.Equal(t, 6, h.VersionCount(), "версия с недописанной записью не становится текущей")
+
+		// после сбоя журнал продолжается с конца последней целой записи
+		h.file = file
+		require.NoError(t, h.Commit(h.Current().Append(200)))
+		require.NoError(t, h.Close())
+
+		restored := openVectorHistory(t, path, array.NewVectorCodec[int]())
+		requireSameHistory(t, h, restored)
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 200}, vectorValues(restored.Current()))
+	})
+
+	t.Run("чужой файл", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.wal")
+		require.NoError(t, os.WriteFile(path, []byte("not a log"), 0o644))
+
+		_, err := OpenDurable(path, array.NewVector[int](), JSONCodec[*array.Vector[int]]())
+		assert.ErrorIs(t, err, ErrCorruptLog)
+	})
+}
+
+// shortWriteFile пишет только половину переданных байт, как при переполнении диска посреди записи
+type shortWriteFile struct {
+	logFile
+}
+
+func (f *shortWriteFile) Write(p []byte) (int, error) {
+	return f.logFile.Write(p[:len(p)/2])
+}
+
+func TestDurableHistory_Compact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.wal")
+	h := openVectorHistory(t, path, array.NewVectorCodec[int](), WithSyncPolicy(SyncSnapshots))
+
+	for i := 0; i < 50; i++ {
+		require.NoError(t, h.Commit(h.Current().Append(i)))
+	}
+	for i := 0; i < 20; i++ {
+		_, _, err := h.Undo()
+		require.NoError(t, err)
+	}
+	for i := 0; i < 5; i++ {
+		_, _, err := h.Redo()
+		require.NoError(t, err)
+	}
+	before, err := os.Stat(path)
+	require.NoError(t, err)
+
+	require.NoError(t, h.Compact())
+	after, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Less(t, after.Size(), before.Size(), "записи Undo / Redo должны исчезнуть")
+
+	require.NoError(t, h.Commit(h.Current().Append(1000)))
+	require.NoError(t, h.Close())
+
+	restored := openVectorHistory(t, path, array.NewVectorCodec[int]())
+	requireSameHistory(t, h, restored)
+	assert.Equal(t, 37, restored.VersionCount())
+}
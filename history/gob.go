@@ -0,0 +1,38 @@
+package history
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+)
+
+var errBadGobHistory = errors.New("history: bad gob history")
+
+type gobHistory[T any] struct {
+	Versions []T
+	Current  int
+}
+
+// GobEncode кодирует все версии и текущую позицию. Версии-структуры этой библиотеки
+// кодируются через их собственный GobEncode.
+func (h *History[T]) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobHistory[T]{Versions: h.versions, Current: h.current}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *History[T]) GobDecode(data []byte) error {
+	var decoded gobHistory[T]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+		return err
+	}
+	if len(decoded.Versions) == 0 || decoded.Current < 0 || decoded.Current >= len(decoded.Versions) {
+		return errBadGobHistory
+	}
+
+	h.versions = decoded.Versions
+	h.current = decoded.Current
+	return nil
+}
@@ -1,5 +1,7 @@
 package history
 
+import "iter"
+
 type History[T any] struct {
 	versions []T
 	current  int
@@ -55,3 +57,15 @@ func (h *History[T]) VersionCount() int {
 func (h *History[T]) CurrentIndex() int {
 	return h.current
 }
+
+// Versions перебирает все сохранённые версии от самой старой к самой новой,
+// включая версии, доступные через Redo
+func (h *History[T]) Versions() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, v := range h.versions {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package history
 
 import (
+	"bytes"
+	"encoding/gob"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -254,3 +256,38 @@ func TestHistory_Persistence(t *testing.T) {
 		assert.Equal(t, 1, h.Current().Len(), "текущее состояние истории должно измениться")
 	})
 }
+
+func TestHistory_Versions(t *testing.T) {
+	t.Run("перебор всех версий", func(t *testing.T) {
+		h := NewHistory(array.NewVector[int]())
+		h.Commit(h.Current().Append(1))
+		h.Commit(h.Current().Append(2))
+		h.Undo()
+
+		var lens []int
+		for i, v := range h.Versions() {
+			assert.Equal(t, len(lens), i)
+			lens = append(lens, v.Len())
+		}
+
+		assert.Equal(t, []int{0, 1, 2}, lens, "должны перебираться все версии, включая доступные через Redo")
+	})
+}
+
+func TestHistory_Gob(t *testing.T) {
+	h := NewHistory(array.NewVector[int]())
+	for i := 0; i < 5; i++ {
+		h.Commit(h.Current().Append(i))
+	}
+	h.Undo()
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(h))
+	decoded := &History[*array.Vector[int]]{}
+	require.NoError(t, gob.NewDecoder(&buf).Decode(decoded))
+
+	assert.Equal(t, 6, decoded.VersionCount())
+	assert.Equal(t, 4, decoded.CurrentIndex())
+	assert.Equal(t, 4, decoded.Current().Len())
+	assert.True(t, decoded.CanRedo())
+}
@@ -0,0 +1,60 @@
+// Package parallel распределяет независимые задачи по ограниченному числу горутин.
+// Используется параллельными операциями array и hashmap, которые делят дерево на поддеревья.
+package parallel
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Workers возвращает число горутин: неположительное n означает runtime.GOMAXPROCS(0)
+func Workers(n int) int {
+	if n <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
+// Run вызывает fn(i) для каждого i из [0, tasks) не более чем в workers горутинах и ждёт завершения всех вызовов.
+// Если горутина одна, задачи выполняются по порядку в вызывающей горутине.
+// Паника в fn повторяется в вызывающей горутине после завершения остальных задач.
+func Run(workers, tasks int, fn func(i int)) {
+	workers = min(Workers(workers), tasks)
+	if workers <= 1 {
+		for i := 0; i < tasks; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	var (
+		next     atomic.Int64
+		wg       sync.WaitGroup
+		once     sync.Once
+		panicked any
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					once.Do(func() { panicked = r })
+				}
+			}()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= tasks {
+					return
+				}
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if panicked != nil {
+		panic(panicked)
+	}
+}
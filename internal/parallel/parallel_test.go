@@ -0,0 +1,32 @@
+package parallel
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	for _, workers := range []int{-1, 0, 1, 3, 100} {
+		var calls [50]atomic.Int32
+		Run(workers, len(calls), func(i int) { calls[i].Add(1) })
+		for i := range calls {
+			assert.Equal(t, int32(1), calls[i].Load(), "workers %d, задача %d", workers, i)
+		}
+	}
+
+	t.Run("без задач", func(t *testing.T) {
+		Run(4, 0, func(int) { t.Fatal("задач нет") })
+	})
+
+	t.Run("паника повторяется в вызывающей горутине", func(t *testing.T) {
+		assert.PanicsWithValue(t, "задача 7", func() {
+			Run(4, 20, func(i int) {
+				if i == 7 {
+					panic("задача 7")
+				}
+			})
+		})
+	})
+}
@@ -0,0 +1,65 @@
+// Package pds описывает общие интерфейсы неизменяемых коллекций из пакетов array, hashmap и queue.
+//
+// Операции изменения возвращают новую версию коллекции того же типа, а в Go метод, возвращающий
+// *Vector[T], не удовлетворяет интерфейсу с методом, возвращающим интерфейс. Поэтому интерфейсы
+// с такими операциями параметризованы типом самой коллекции S, и обобщённый код принимает его
+// как ограничение, ссылающееся на себя:
+//
+//	func Fill[S pds.Indexed[int, S]](s S, n int) S {
+//		for i := 0; i < n; i++ {
+//			s = s.Append(i)
+//		}
+//		return s
+//	}
+//
+//	v := Fill(array.NewVector[int](), 10)     // *array.Vector[int]
+//	a := Fill(array.NewNaiveArray[int](), 10) // *array.NaiveArray[int]
+package pds
+
+import "iter"
+
+// Sized - коллекция с известным числом элементов
+type Sized interface {
+	Len() int
+}
+
+// Iterable - коллекция, элементы которой можно обойти
+type Iterable[T any] interface {
+	Values() iter.Seq[T]
+}
+
+// Indexed - массив с доступом по индексу: array.Vector, array.NaiveArray.
+// Get для индекса вне [0, Len) возвращает false, Pop пустого массива возвращает его же и false.
+type Indexed[T any, S any] interface {
+	Sized
+	Iterable[T]
+	Get(index int) (T, bool)
+	Set(index int, value T) S
+	Append(value T) S
+	Pop() (S, T, bool)
+	All() iter.Seq2[int, T]
+}
+
+// Map - ассоциативный массив: hashmap.HashMap, hashmap.ShardedHashMap, hashmap.NaiveHashMap,
+// hashmap.OrderedMap и hashmap.IntMap.
+// Порядок обхода не определён. Delete отсутствующего ключа возвращает эквивалентную версию.
+type Map[K comparable, V any, S any] interface {
+	Sized
+	Iterable[V]
+	Get(key K) (V, bool)
+	Contains(key K) bool
+	Set(key K, value V) S
+	Delete(key K) S
+	All() iter.Seq2[K, V]
+	Keys() iter.Seq[K]
+}
+
+// FIFO - очередь: queue.Queue, queue.NaiveQueue. Values обходит элементы в порядке извлечения.
+type FIFO[T any, S any] interface {
+	Sized
+	Iterable[T]
+	IsEmpty() bool
+	Enqueue(value T) S
+	Dequeue() (S, T, bool)
+	Peek() (T, bool)
+}
@@ -0,0 +1,124 @@
+package pdstest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pds "github.com/ykhdr/persistent-data-structures"
+)
+
+// FIFO проверяет контракт pds.FIFO: порядок извлечения, длину и IsEmpty, неизменность старых версий,
+// в том числе повторное извлечение из одной версии, и полноту обхода.
+func FIFO[T any, S pds.FIFO[T, S]](t *testing.T, empty func() S, gen func(i int) T) {
+	t.Helper()
+
+	fill := func(n int) S {
+		q := empty()
+		for i := 0; i < n; i++ {
+			q = q.Enqueue(gen(i))
+		}
+		return q
+	}
+
+	t.Run("длина", func(t *testing.T) {
+		q := empty()
+		require.True(t, q.IsEmpty())
+		require.Equal(t, 0, q.Len())
+		for i := 0; i < 100; i++ {
+			q = q.Enqueue(gen(i))
+			require.Equal(t, i+1, q.Len())
+			require.False(t, q.IsEmpty())
+		}
+		for i := 99; i >= 0; i-- {
+			q, _, _ = q.Dequeue()
+			require.Equal(t, i, q.Len())
+		}
+		require.True(t, q.IsEmpty())
+	})
+
+	t.Run("порядок извлечения", func(t *testing.T) {
+		for _, n := range sizes {
+			q := fill(n)
+			for i := 0; i < n; i++ {
+				head, ok := q.Peek()
+				require.True(t, ok)
+				require.Equal(t, gen(i), head)
+
+				var value T
+				q, value, ok = q.Dequeue()
+				require.True(t, ok)
+				require.Equal(t, gen(i), value)
+			}
+			require.True(t, q.IsEmpty())
+		}
+	})
+
+	t.Run("старые версии не меняются", func(t *testing.T) {
+		// чередование добавлений и извлечений, при котором очередь перекладывает элементы
+		versions := []S{empty()}
+		expected := [][]T{{}}
+		next := 0
+		for step := 0; step < 300; step++ {
+			q := versions[len(versions)-1]
+			content := expected[len(expected)-1]
+			if step%3 == 2 {
+				q, _, _ = q.Dequeue()
+				if len(content) > 0 {
+					content = content[1:]
+				}
+			} else {
+				q = q.Enqueue(gen(next))
+				content = append(content[:len(content):len(content)], gen(next))
+				next++
+			}
+			versions = append(versions, q)
+			expected = append(expected, content)
+		}
+
+		for i, q := range versions {
+			// повторное извлечение из той же версии даёт тот же результат
+			for attempt := 0; attempt < 2; attempt++ {
+				_, value, ok := q.Dequeue()
+				require.Equal(t, len(expected[i]) > 0, ok, "версия %d", i)
+				if ok {
+					require.Equal(t, expected[i][0], value, "версия %d", i)
+				}
+			}
+			require.Equal(t, expected[i], collect[T](q), "версия %d", i)
+			require.Equal(t, len(expected[i]), q.Len(), "версия %d", i)
+		}
+	})
+
+	t.Run("обход", func(t *testing.T) {
+		for _, n := range sizes {
+			require.Equal(t, generate(gen, n), collect[T](fill(n)), "Values при длине %d", n)
+		}
+
+		// элементы, уже переложенные при извлечении, и новые элементы обходятся в порядке очереди
+		q := fill(10)
+		q, _, _ = q.Dequeue()
+		q = q.Enqueue(gen(10)).Enqueue(gen(11))
+		assert.Equal(t, generate(gen, 12)[1:], collect[T](q))
+
+		visited := 0
+		for range fill(100).Values() {
+			visited++
+			if visited == 40 {
+				break
+			}
+		}
+		assert.Equal(t, 40, visited, "обход останавливается по break")
+	})
+
+	t.Run("пустая очередь", func(t *testing.T) {
+		q := empty()
+		_, ok := q.Peek()
+		assert.False(t, ok)
+		dequeued, _, ok := q.Dequeue()
+		assert.False(t, ok)
+		assert.True(t, dequeued.IsEmpty())
+		assert.Empty(t, collect[T](q))
+	})
+}
@@ -0,0 +1,136 @@
+package pdstest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pds "github.com/ykhdr/persistent-data-structures"
+)
+
+// Indexed проверяет контракт pds.Indexed: длину после каждой операции, неизменность старых версий,
+// полноту и порядок обхода и поведение на индексах вне [0, Len).
+func Indexed[T any, S pds.Indexed[T, S]](t *testing.T, empty func() S, gen func(i int) T) {
+	t.Helper()
+
+	fill := func(n int) S {
+		s := empty()
+		for i := 0; i < n; i++ {
+			s = s.Append(gen(i))
+		}
+		return s
+	}
+
+	t.Run("длина", func(t *testing.T) {
+		s := empty()
+		require.Equal(t, 0, s.Len())
+		for _, n := range sizes {
+			for s.Len() < n {
+				s = s.Append(gen(s.Len()))
+			}
+			require.Equal(t, n, s.Len(), "после Append")
+			if n > 0 {
+				require.Equal(t, n, s.Set(n-1, gen(0)).Len(), "Set не меняет длину")
+				popped, _, ok := s.Pop()
+				require.True(t, ok)
+				require.Equal(t, n-1, popped.Len(), "после Pop")
+			}
+		}
+	})
+
+	t.Run("старые версии не меняются", func(t *testing.T) {
+		const size = 200
+		versions := []S{empty()}
+		for i := 0; i < size; i++ {
+			versions = append(versions, versions[i].Append(gen(i)))
+		}
+
+		// изменения поверх каждой версии не должны просачиваться в неё саму и в соседние версии
+		for n, s := range versions {
+			if n > 0 {
+				s.Set(0, gen(size))
+				s.Set(n-1, gen(size))
+				s.Pop()
+			}
+			s.Append(gen(size))
+		}
+		for n, s := range versions {
+			require.Equal(t, generate(gen, n), collect[T](s), "версия длины %d", n)
+		}
+
+		expected := generate(gen, size)
+		s := versions[size]
+		for i := 0; i < size; i += 7 {
+			s = s.Set(i, gen(size+i))
+			expected[i] = gen(size + i)
+		}
+		assert.Equal(t, expected, collect[T](s))
+		assert.Equal(t, generate(gen, size), collect[T](versions[size]))
+	})
+
+	t.Run("обход", func(t *testing.T) {
+		for _, n := range sizes {
+			s := fill(n)
+			expected := generate(gen, n)
+			require.Equal(t, expected, collect[T](s), "Values при длине %d", n)
+
+			next := 0
+			for i, v := range s.All() {
+				require.Equal(t, next, i, "All при длине %d", n)
+				got, ok := s.Get(i)
+				require.True(t, ok)
+				require.Equal(t, got, v)
+				next++
+			}
+			require.Equal(t, n, next, "All при длине %d", n)
+		}
+
+		s := fill(100)
+		visited := 0
+		for range s.All() {
+			visited++
+			if visited == 40 {
+				break
+			}
+		}
+		assert.Equal(t, 40, visited, "обход останавливается по break")
+	})
+
+	t.Run("границы индексов", func(t *testing.T) {
+		for _, n := range sizes {
+			s := fill(n)
+			for _, index := range []int{-1, n, n + 1, n + 1000} {
+				_, ok := s.Get(index)
+				assert.False(t, ok, "Get(%d) при длине %d", index, n)
+				assert.Equal(t, generate(gen, n), collect[T](s.Set(index, gen(n))), "Set(%d) при длине %d", index, n)
+			}
+			if n > 0 {
+				first, ok := s.Get(0)
+				require.True(t, ok)
+				assert.Equal(t, gen(0), first)
+				last, ok := s.Get(n - 1)
+				require.True(t, ok)
+				assert.Equal(t, gen(n-1), last)
+			}
+		}
+
+		s := empty()
+		popped, _, ok := s.Pop()
+		assert.False(t, ok, "Pop пустого массива")
+		assert.Equal(t, 0, popped.Len())
+	})
+
+	t.Run("Pop до пустого", func(t *testing.T) {
+		s := fill(1057)
+		for i := 1056; i >= 0; i-- {
+			var value T
+			var ok bool
+			s, value, ok = s.Pop()
+			require.True(t, ok)
+			require.Equal(t, gen(i), value)
+			require.Equal(t, i, s.Len())
+		}
+		assert.Empty(t, collect[T](s))
+	})
+}
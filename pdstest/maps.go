@@ -0,0 +1,129 @@
+package pdstest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pds "github.com/ykhdr/persistent-data-structures"
+)
+
+// Map проверяет контракт pds.Map: длину после вставки, замены и удаления, неизменность старых версий,
+// полноту обхода и поиск отсутствующих ключей. key должен давать разные ключи для разных номеров.
+func Map[K comparable, V any, S pds.Map[K, V, S]](t *testing.T, empty func() S, key func(i int) K, value func(i int) V) {
+	t.Helper()
+
+	fill := func(n int) S {
+		m := empty()
+		for i := 0; i < n; i++ {
+			m = m.Set(key(i), value(i))
+		}
+		return m
+	}
+
+	t.Run("длина", func(t *testing.T) {
+		m := empty()
+		require.Equal(t, 0, m.Len())
+		for i := 0; i < 1000; i++ {
+			m = m.Set(key(i), value(i))
+			require.Equal(t, i+1, m.Len(), "новый ключ")
+		}
+		require.Equal(t, 1000, m.Set(key(10), value(11)).Len(), "замена значения")
+		require.Equal(t, 1000, m.Delete(key(1000)).Len(), "удаление отсутствующего ключа")
+		for i := 0; i < 1000; i++ {
+			m = m.Delete(key(i))
+			require.Equal(t, 999-i, m.Len(), "удаление ключа")
+		}
+	})
+
+	t.Run("старые версии не меняются", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		model := map[K]V{}
+		versions := []S{empty()}
+		models := []map[K]V{{}}
+		for i := 0; i < 2000; i++ {
+			// операции применяются к случайной, не обязательно последней, версии
+			base := r.Intn(len(versions))
+			m := versions[base]
+			model = clone(models[base])
+			k := r.Intn(300)
+			if r.Intn(3) == 0 {
+				m = m.Delete(key(k))
+				delete(model, key(k))
+			} else {
+				m = m.Set(key(k), value(i))
+				model[key(k)] = value(i)
+			}
+			versions = append(versions, m)
+			models = append(models, model)
+		}
+
+		for i, m := range versions {
+			require.Equal(t, len(models[i]), m.Len(), "версия %d", i)
+			for k, v := range models[i] {
+				got, ok := m.Get(k)
+				require.True(t, ok, "версия %d", i)
+				require.Equal(t, v, got, "версия %d", i)
+			}
+		}
+	})
+
+	t.Run("обход", func(t *testing.T) {
+		for _, n := range sizes {
+			m := fill(n)
+			seen := make(map[K]bool, n)
+			for k, v := range m.All() {
+				require.False(t, seen[k], "ключ встретился дважды")
+				seen[k] = true
+				got, ok := m.Get(k)
+				require.True(t, ok)
+				require.Equal(t, got, v)
+			}
+			require.Len(t, seen, n, "All при длине %d", n)
+
+			keys := 0
+			for k := range m.Keys() {
+				require.True(t, seen[k])
+				keys++
+			}
+			require.Equal(t, n, keys, "Keys при длине %d", n)
+			assert.ElementsMatch(t, generate(value, n), collect[V](m), "Values при длине %d", n)
+		}
+
+		visited := 0
+		for range fill(100).All() {
+			visited++
+			if visited == 40 {
+				break
+			}
+		}
+		assert.Equal(t, 40, visited, "обход останавливается по break")
+	})
+
+	t.Run("отсутствующие ключи", func(t *testing.T) {
+		var zero V
+		m := fill(100)
+		for i := 100; i < 200; i++ {
+			got, ok := m.Get(key(i))
+			require.False(t, ok)
+			require.Equal(t, zero, got)
+			require.False(t, m.Contains(key(i)))
+		}
+
+		deleted := m.Delete(key(5))
+		assert.False(t, deleted.Contains(key(5)))
+		assert.True(t, m.Contains(key(5)))
+		_, ok := empty().Get(key(0))
+		assert.False(t, ok)
+	})
+}
+
+func clone[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
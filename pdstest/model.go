@@ -0,0 +1,263 @@
+package pdstest
+
+import (
+	"fmt"
+	"iter"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Переменные окружения модельных тестов. Пакет не регистрирует флаги, чтобы его можно было
+// импортировать из любых тестов без конфликтов с их флагами.
+const (
+	// SeedEnv задаёт seed: число воспроизводит одну последовательность, random включает случайные seed
+	SeedEnv = "PDSTEST_SEED"
+	// RunsEnv задаёт число последовательностей, по умолчанию 20
+	RunsEnv = "PDSTEST_RUNS"
+	// StepsEnv задаёт длину последовательности, по умолчанию 500
+	StepsEnv = "PDSTEST_STEPS"
+)
+
+// defaultSeed - seed первой последовательности по умолчанию: без переменных окружения
+// модельные тесты детерминированы
+const defaultSeed = 1
+
+// runConfig читает настройки модельного теста из окружения
+func runConfig(t *testing.T) (seed int64, runs, steps int) {
+	t.Helper()
+
+	seed, runs, steps = defaultSeed, 20, 500
+	switch value := os.Getenv(SeedEnv); value {
+	case "":
+	case "random":
+		seed = time.Now().UnixNano()
+	default:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			t.Fatalf("%s=%q: %v", SeedEnv, value, err)
+		}
+		// явный seed воспроизводит одну упавшую последовательность
+		return parsed, 1, envInt(t, StepsEnv, steps)
+	}
+	return seed, envInt(t, RunsEnv, runs), envInt(t, StepsEnv, steps)
+}
+
+func envInt(t *testing.T, name string, fallback int) int {
+	t.Helper()
+
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		t.Fatalf("%s=%q: ожидается положительное число", name, value)
+	}
+	return n
+}
+
+// Step - шаг последовательности операций модельного теста. Операция Op применяется к версии
+// с номером Version по модулю числа уже полученных версий (нулевая - пустая коллекция),
+// а её аргумент выводится из Arg. Поэтому любая подпоследовательность шагов тоже выполнима,
+// и на этом построено сокращение упавшей последовательности.
+type Step struct {
+	Op      int
+	Version int
+	Arg     int
+}
+
+// Model сравнивает проверяемую коллекцию S с эталонной реализацией R, например Vector с NaiveArray.
+// Каждый шаг применяется к одной и той же версии обеих коллекций, а в конце все полученные версии
+// сравниваются заново, так что изменение старой версии последующими операциями будет замечено.
+type Model[S, R any] struct {
+	Ops   []string // имена операций, номер операции шага берётся по модулю их числа
+	Empty S
+	Ref   R
+
+	// Apply выполняет операцию op над обеими коллекциями и сравнивает результаты, которые она
+	// возвращает помимо новой версии, например значение Pop
+	Apply func(s S, r R, op int, arg int) (S, R, error)
+	// Equal сравнивает содержимое версий
+	Equal func(s S, r R) error
+}
+
+// Run выполняет случайные последовательности операций, по умолчанию с фиксированными seed
+// (см. SeedEnv). Упавшая последовательность сокращается до минимальной, и тест завершается
+// с её описанием и seed, которым её можно воспроизвести:
+//
+//	PDSTEST_SEED=<seed> go test -run TestVector_Model
+func (m Model[S, R]) Run(t *testing.T) {
+	t.Helper()
+
+	seed, runs, n := runConfig(t)
+	for i := 0; i < runs; i++ {
+		steps := Steps(seed+int64(i), n)
+		if err := m.Execute(steps); err != nil {
+			shrunk := m.Shrink(steps)
+			t.Fatalf("seed %d: %v\nминимальная последовательность (%d из %d шагов):\n%s\nвоспроизвести: %s=%d go test -run '%s'",
+				seed+int64(i), m.Execute(shrunk), len(shrunk), len(steps), m.Format(shrunk), SeedEnv, seed+int64(i), t.Name())
+		}
+	}
+}
+
+// Steps генерирует n случайных шагов. Половина шагов применяется к последней версии,
+// остальные - к случайной из уже полученных.
+func Steps(seed int64, n int) []Step {
+	r := rand.New(rand.NewSource(seed))
+	steps := make([]Step, n)
+	for i := range steps {
+		version := i
+		if r.Intn(2) == 0 {
+			version = r.Intn(i + 1)
+		}
+		steps[i] = Step{Op: r.Intn(1 << 10), Version: version, Arg: r.Intn(1 << 10)}
+	}
+	return steps
+}
+
+// StepsFromBytes разбирает вход фаззера на шаги: по четыре байта на шаг, неполный хвост отбрасывается
+func StepsFromBytes(data []byte) []Step {
+	steps := make([]Step, 0, len(data)/4)
+	for ; len(data) >= 4; data = data[4:] {
+		steps = append(steps, Step{
+			Op:      int(data[0]),
+			Version: int(data[1]),
+			Arg:     int(data[2]) | int(data[3])<<8,
+		})
+	}
+	return steps
+}
+
+// Execute выполняет шаги и возвращает первое расхождение с эталоном. Паника реализации
+// тоже считается расхождением.
+func (m Model[S, R]) Execute(steps []Step) (err error) {
+	current := -1
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("шаг %d: паника: %v", current, r)
+		}
+	}()
+
+	versions, refs := []S{m.Empty}, []R{m.Ref}
+	for i, step := range steps {
+		current = i
+		v := step.Version % len(versions)
+		s, r, err := m.Apply(versions[v], refs[v], step.Op%len(m.Ops), step.Arg)
+		if err == nil {
+			err = m.Equal(s, r)
+		}
+		if err != nil {
+			return fmt.Errorf("шаг %d (%s): %w", i, m.describe(step, len(versions)), err)
+		}
+		versions, refs = append(versions, s), append(refs, r)
+	}
+
+	current = len(steps)
+	for v := range versions {
+		if err := m.Equal(versions[v], refs[v]); err != nil {
+			return fmt.Errorf("версия %d изменилась после последующих операций: %w", v, err)
+		}
+	}
+	return nil
+}
+
+// Shrink сокращает упавшую последовательность: сначала выбрасывает блоки шагов, от половины
+// последовательности до одного шага, затем уменьшает аргументы и номера версий оставшихся шагов.
+// Результат по-прежнему падает, хотя, возможно, с другой ошибкой.
+func (m Model[S, R]) Shrink(steps []Step) []Step {
+	fails := func(candidate []Step) bool { return m.Execute(candidate) != nil }
+
+	steps = m.normalize(steps)
+	for changed := true; changed; {
+		changed = false
+
+		for size := len(steps) / 2; size >= 1; size /= 2 {
+			for start := 0; start+size <= len(steps); {
+				candidate := m.normalize(append(steps[:start:start], steps[start+size:]...))
+				if fails(candidate) {
+					steps, changed = candidate, true
+				} else {
+					start += size
+				}
+			}
+		}
+
+		for i := range steps {
+			for _, smaller := range smallerSteps(steps[i]) {
+				candidate := append([]Step(nil), steps...)
+				candidate[i] = smaller
+				if fails(candidate) {
+					steps[i], changed = smaller, true
+					break
+				}
+			}
+		}
+	}
+	return steps
+}
+
+// normalize заменяет номера операций и версий их фактическими значениями, чтобы удаление шагов
+// не перенаправляло оставшиеся шаги на другие версии сильнее, чем нужно
+func (m Model[S, R]) normalize(steps []Step) []Step {
+	normalized := make([]Step, len(steps))
+	for i, step := range steps {
+		step.Op %= len(m.Ops)
+		step.Version %= i + 1
+		normalized[i] = step
+	}
+	return normalized
+}
+
+func smallerSteps(step Step) []Step {
+	var candidates []Step
+	for _, arg := range []int{0, step.Arg / 2, step.Arg - 1} {
+		if arg >= 0 && arg < step.Arg {
+			candidates = append(candidates, Step{Op: step.Op, Version: step.Version, Arg: arg})
+		}
+	}
+	for _, version := range []int{0, step.Version - 1} {
+		if version >= 0 && version < step.Version {
+			candidates = append(candidates, Step{Op: step.Op, Version: version, Arg: step.Arg})
+		}
+	}
+	return candidates
+}
+
+// Format описывает шаги по одному на строку: номер шага, операция, версия и аргумент
+func (m Model[S, R]) Format(steps []Step) string {
+	var sb strings.Builder
+	for i, step := range steps {
+		fmt.Fprintf(&sb, "%4d: %s\n", i, m.describe(step, i+1))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func (m Model[S, R]) describe(step Step, versions int) string {
+	return fmt.Sprintf("%s над версией %d, arg=%d", m.Ops[step.Op%len(m.Ops)], step.Version%versions, step.Arg)
+}
+
+// EqualSeq сравнивает последовательности поэлементно и описывает первое расхождение
+func EqualSeq[T comparable](got, want iter.Seq[T]) error {
+	next, stop := iter.Pull(want)
+	defer stop()
+
+	i := 0
+	for v := range got {
+		expected, ok := next()
+		if !ok {
+			return fmt.Errorf("лишний элемент %d: %v", i, v)
+		}
+		if v != expected {
+			return fmt.Errorf("элемент %d: %v, ожидалось %v", i, v, expected)
+		}
+		i++
+	}
+	if expected, ok := next(); ok {
+		return fmt.Errorf("не хватает элемента %d: %v", i, expected)
+	}
+	return nil
+}
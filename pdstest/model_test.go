@@ -0,0 +1,83 @@
+package pdstest
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenStack теряет элемент при Pop из стека ровно из трёх элементов
+type brokenStack []int
+
+func stackModel() Model[brokenStack, []int] {
+	return Model[brokenStack, []int]{
+		Ops:   []string{"Push", "Pop"},
+		Empty: brokenStack{},
+		Ref:   []int{},
+		Apply: func(s brokenStack, r []int, op int, arg int) (brokenStack, []int, error) {
+			if op == 0 {
+				return append(s[:len(s):len(s)], arg), append(r[:len(r):len(r)], arg), nil
+			}
+			if len(r) == 0 {
+				return s, r, nil
+			}
+			if len(s) == 3 {
+				return s[:1], r[:2], nil
+			}
+			return s[:len(s)-1], r[:len(r)-1], nil
+		},
+		Equal: func(s brokenStack, r []int) error {
+			return EqualSeq(slices.Values(s), slices.Values(r))
+		},
+	}
+}
+
+func TestModel_Shrink(t *testing.T) {
+	m := stackModel()
+
+	var failing []Step
+	for seed := int64(1); failing == nil; seed++ {
+		if steps := Steps(seed, 200); m.Execute(steps) != nil {
+			failing = steps
+		}
+	}
+
+	shrunk := m.Shrink(failing)
+	require.Error(t, m.Execute(shrunk))
+	assert.Equal(t, []Step{
+		{Op: 0, Version: 0, Arg: 0},
+		{Op: 0, Version: 1, Arg: 0},
+		{Op: 0, Version: 2, Arg: 0},
+		{Op: 1, Version: 3, Arg: 0},
+	}, shrunk, m.Format(shrunk))
+}
+
+func TestModel_Execute(t *testing.T) {
+	m := stackModel()
+
+	t.Run("операции над старыми версиями", func(t *testing.T) {
+		steps := []Step{{Op: 0, Arg: 1}, {Op: 0, Version: 1, Arg: 2}, {Op: 0, Version: 1, Arg: 3}, {Op: 1, Version: 2}}
+		assert.NoError(t, m.Execute(steps))
+	})
+
+	t.Run("паника считается расхождением", func(t *testing.T) {
+		m := m
+		m.Apply = func(brokenStack, []int, int, int) (brokenStack, []int, error) { panic("сломано") }
+		err := m.Execute([]Step{{}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "сломано")
+	})
+
+	t.Run("разбор входа фаззера", func(t *testing.T) {
+		assert.Equal(t, []Step{{Op: 1, Version: 2, Arg: 0x0403}}, StepsFromBytes([]byte{1, 2, 3, 4, 5}))
+	})
+}
+
+func TestEqualSeq(t *testing.T) {
+	assert.NoError(t, EqualSeq(slices.Values([]int{1, 2}), slices.Values([]int{1, 2})))
+	assert.EqualError(t, EqualSeq(slices.Values([]int{1, 3}), slices.Values([]int{1, 2})), "элемент 1: 3, ожидалось 2")
+	assert.EqualError(t, EqualSeq(slices.Values([]int{1}), slices.Values([]int{1, 2})), "не хватает элемента 1: 2")
+	assert.EqualError(t, EqualSeq(slices.Values([]int{1, 2}), slices.Values([]int{1})), "лишний элемент 1: 2")
+}
@@ -0,0 +1,37 @@
+// Package pdstest содержит контрактные тесты интерфейсов пакета pds. Любая реализация, в том числе
+// написанная вне модуля, проверяется одним вызовом:
+//
+//	func TestMyVector(t *testing.T) {
+//		pdstest.Indexed(t, NewMyVector[int], func(i int) int { return i })
+//	}
+//
+// Каждый набор получает фабрику пустой коллекции, а не саму коллекцию, чтобы подтесты не зависели
+// друг от друга, и генератор элементов: элементы с разными номерами должны различаться.
+package pdstest
+
+import (
+	"slices"
+
+	pds "github.com/ykhdr/persistent-data-structures"
+)
+
+// sizes - размеры коллекций, на которых проверяются контракты.
+// Включают границы узлов ширины 32 и переход дерева на следующий уровень.
+var sizes = []int{0, 1, 2, 31, 32, 33, 64, 1024, 1057}
+
+func collect[T any](s pds.Iterable[T]) []T {
+	values := slices.Collect(s.Values())
+	if values == nil {
+		values = []T{}
+	}
+	return values
+}
+
+// generate возвращает первые n элементов генератора
+func generate[T any](gen func(int) T, n int) []T {
+	values := make([]T, n)
+	for i := range values {
+		values[i] = gen(i)
+	}
+	return values
+}
@@ -220,3 +220,49 @@ func BenchmarkQueueMemoryAllocation(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkQueueFromSlice(b *testing.B) {
+	sizes := []int{1000, 10000, 100000}
+
+	for _, size := range sizes {
+		values := make([]int, size)
+		for i := range values {
+			values[i] = i
+		}
+
+		b.Run(fmt.Sprintf("EnqueueLoop/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				q := NewQueue[int]()
+				for _, value := range values {
+					q = q.Enqueue(value)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("FromSlice/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				FromSlice(values)
+			}
+		})
+
+		q := FromSlice(values)
+		b.Run(fmt.Sprintf("ValuesLoop/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				out := make([]int, 0, size)
+				for value := range q.Values() {
+					out = append(out, value)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("ToSlice/size_%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				q.ToSlice()
+			}
+		})
+	}
+}
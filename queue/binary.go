@@ -0,0 +1,184 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrCorruptStream = errors.New("queue: corrupt binary stream")
+
+const (
+	recordNode uint8 = iota + 1
+	recordVersion
+)
+
+// queueRecord - единица бинарного потока. Узлы стеков пишутся один раз и получают ID,
+// версии ссылаются на вершины стеков по ID. Поля экспортированы для encoding/gob.
+type queueRecord[T any] struct {
+	Kind     uint8
+	ID       uint64
+	Value    T
+	Next     uint64 // 0 - конец стека
+	Front    uint64
+	FrontLen int
+	Rear     uint64
+	RearLen  int
+}
+
+// QueueEncoder пишет несколько версий очереди в один поток.
+// Узлы стеков, общие для нескольких версий, записываются только один раз.
+type QueueEncoder[T any] struct {
+	enc    *gob.Encoder
+	ids    map[*stackNode[T]]uint64
+	nextID uint64
+}
+
+func NewQueueEncoder[T any](w io.Writer) *QueueEncoder[T] {
+	return &QueueEncoder[T]{
+		enc:    gob.NewEncoder(w),
+		ids:    make(map[*stackNode[T]]uint64),
+		nextID: 1,
+	}
+}
+
+func (e *QueueEncoder[T]) Encode(q *Queue[T]) error {
+	front, err := e.encodeStack(q.front)
+	if err != nil {
+		return err
+	}
+	rear, err := e.encodeStack(q.rear)
+	if err != nil {
+		return err
+	}
+
+	return e.enc.Encode(queueRecord[T]{
+		Kind:     recordVersion,
+		Front:    front,
+		FrontLen: q.front.len,
+		Rear:     rear,
+		RearLen:  q.rear.len,
+	})
+}
+
+// encodeStack пишет ещё не записанные узлы стека, начиная с самого глубокого
+func (e *QueueEncoder[T]) encodeStack(s *stack[T]) (uint64, error) {
+	var pending []*stackNode[T]
+	node := s.head
+	for node != nil {
+		if _, ok := e.ids[node]; ok {
+			break
+		}
+		pending = append(pending, node)
+		node = node.next
+	}
+
+	for i := len(pending) - 1; i >= 0; i-- {
+		n := pending[i]
+		record := queueRecord[T]{Kind: recordNode, ID: e.nextID, Value: n.value}
+		if n.next != nil {
+			record.Next = e.ids[n.next]
+		}
+		if err := e.enc.Encode(record); err != nil {
+			return 0, err
+		}
+		e.ids[n] = e.nextID
+		e.nextID++
+	}
+
+	if s.head == nil {
+		return 0, nil
+	}
+	return e.ids[s.head], nil
+}
+
+// QueueDecoder читает версии, записанные QueueEncoder, восстанавливая общие узлы
+type QueueDecoder[T any] struct {
+	dec   *gob.Decoder
+	nodes map[uint64]*stackNode[T]
+}
+
+func NewQueueDecoder[T any](r io.Reader) *QueueDecoder[T] {
+	return &QueueDecoder[T]{
+		dec:   gob.NewDecoder(r),
+		nodes: make(map[uint64]*stackNode[T]),
+	}
+}
+
+// Decode возвращает следующую версию из потока или io.EOF, если версий больше нет
+func (d *QueueDecoder[T]) Decode() (*Queue[T], error) {
+	for {
+		var record queueRecord[T]
+		if err := d.dec.Decode(&record); err != nil {
+			return nil, err
+		}
+
+		switch record.Kind {
+		case recordNode:
+			node := &stackNode[T]{value: record.Value}
+			if record.Next != 0 {
+				next, ok := d.nodes[record.Next]
+				if !ok {
+					return nil, fmt.Errorf("%w: unknown node %d", ErrCorruptStream, record.Next)
+				}
+				node.next = next
+			}
+			d.nodes[record.ID] = node
+
+		case recordVersion:
+			front, err := d.stack(record.Front, record.FrontLen)
+			if err != nil {
+				return nil, err
+			}
+			rear, err := d.stack(record.Rear, record.RearLen)
+			if err != nil {
+				return nil, err
+			}
+			return &Queue[T]{
+				front: front,
+				rear:  rear,
+				len:   front.len + rear.len,
+			}, nil
+
+		default:
+			return nil, fmt.Errorf("%w: unknown record kind %d", ErrCorruptStream, record.Kind)
+		}
+	}
+}
+
+func (d *QueueDecoder[T]) stack(head uint64, length int) (*stack[T], error) {
+	if head == 0 {
+		if length != 0 {
+			return nil, fmt.Errorf("%w: empty stack with length %d", ErrCorruptStream, length)
+		}
+		return newStack[T](), nil
+	}
+
+	node, ok := d.nodes[head]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown node %d", ErrCorruptStream, head)
+	}
+	if length <= 0 {
+		return nil, fmt.Errorf("%w: stack length %d", ErrCorruptStream, length)
+	}
+	return &stack[T]{head: node, len: length}, nil
+}
+
+func (q *Queue[T]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewQueueEncoder[T](&buf).Encode(q); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (q *Queue[T]) UnmarshalBinary(data []byte) error {
+	decoded, err := NewQueueDecoder[T](bytes.NewReader(data)).Decode()
+	if err != nil {
+		return err
+	}
+	*q = *decoded
+	return nil
+}
@@ -0,0 +1,89 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drain[T any](q *Queue[T]) []T {
+	var values []T
+	for v := range q.All() {
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestQueue_Binary(t *testing.T) {
+	t.Run("одна версия через BinaryMarshaler", func(t *testing.T) {
+		q := NewQueue[int]().Enqueue(1).Enqueue(2).Enqueue(3)
+		q, _, _ = q.Dequeue()
+		q = q.Enqueue(4)
+
+		data, err := q.MarshalBinary()
+		require.NoError(t, err)
+
+		var decoded Queue[int]
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		assert.Equal(t, []int{2, 3, 4}, drain(&decoded))
+	})
+
+	t.Run("пустая очередь", func(t *testing.T) {
+		data, err := NewQueue[int]().MarshalBinary()
+		require.NoError(t, err)
+
+		var decoded Queue[int]
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		assert.True(t, decoded.IsEmpty())
+	})
+
+	t.Run("несколько версий с общими узлами", func(t *testing.T) {
+		base := NewQueue[int]()
+		for i := 0; i < 100; i++ {
+			base = base.Enqueue(i)
+		}
+		afterDequeue, _, _ := base.Dequeue()
+		versions := []*Queue[int]{base, base.Enqueue(100), afterDequeue, afterDequeue.Enqueue(7)}
+
+		var buf bytes.Buffer
+		enc := NewQueueEncoder[int](&buf)
+		for _, q := range versions {
+			require.NoError(t, enc.Encode(q))
+		}
+
+		dec := NewQueueDecoder[int](&buf)
+		var decoded []*Queue[int]
+		for {
+			q, err := dec.Decode()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			decoded = append(decoded, q)
+		}
+
+		require.Len(t, decoded, len(versions))
+		for i := range versions {
+			assert.Equal(t, drain(versions[i]), drain(decoded[i]))
+		}
+		assert.Same(t, decoded[0].rear.head, decoded[1].rear.head.next, "rear-стек должен быть общим")
+		assert.Same(t, decoded[2].front.head, decoded[3].front.head, "front-стек должен быть общим")
+	})
+
+	t.Run("ссылка на неизвестный узел", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(queueRecord[int]{
+			Kind:     recordVersion,
+			Front:    3,
+			FrontLen: 1,
+		}))
+
+		_, err := NewQueueDecoder[int](&buf).Decode()
+		assert.ErrorIs(t, err, ErrCorruptStream)
+	})
+}
@@ -0,0 +1,33 @@
+package queue
+
+// FromSlice строит очередь из копии values сразу в front-стеке, чтобы первый Dequeue не делал reverse.
+// Узлы стека связываются напрямую, без промежуточных версий стека на каждый элемент.
+func FromSlice[T any](values []T) *Queue[T] {
+	var head *stackNode[T]
+	for i := len(values) - 1; i >= 0; i-- {
+		head = &stackNode[T]{value: values[i], next: head}
+	}
+
+	return &Queue[T]{
+		front: &stack[T]{head: head, len: len(values)},
+		rear:  newStack[T](),
+		len:   len(values),
+	}
+}
+
+// ToSlice копирует элементы в порядке извлечения. Front-стек читается с начала среза,
+// rear-стек - с конца, поэтому reverse не нужен.
+func (q *Queue[T]) ToSlice() []T {
+	result := make([]T, q.len)
+	i := 0
+	for node := q.front.head; node != nil; node = node.next {
+		result[i] = node.value
+		i++
+	}
+	i = q.len - 1
+	for node := q.rear.head; node != nil; node = node.next {
+		result[i] = node.value
+		i--
+	}
+	return result
+}
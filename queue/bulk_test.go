@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_FromSlice(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 100} {
+		t.Run(fmt.Sprintf("размер %d", size), func(t *testing.T) {
+			values := make([]int, size)
+			for i := range values {
+				values[i] = i
+			}
+
+			q := FromSlice(values)
+			require.Equal(t, size, q.Len())
+			assert.Equal(t, values, q.ToSlice())
+			for i := 0; i < size; i++ {
+				var value int
+				q, value, _ = q.Dequeue()
+				assert.Equal(t, i, value)
+			}
+		})
+	}
+
+	t.Run("ToSlice при элементах в обоих стеках", func(t *testing.T) {
+		q := FromSlice([]int{1, 2, 3})
+		q, _, _ = q.Dequeue()
+		q = q.Enqueue(4).Enqueue(5)
+		require.False(t, q.rear.isEmpty())
+		assert.Equal(t, []int{2, 3, 4, 5}, q.ToSlice())
+
+		// только rear-стек
+		q = NewQueue[int]().Enqueue(1).Enqueue(2)
+		assert.Equal(t, []int{1, 2}, q.ToSlice())
+	})
+
+	t.Run("срез копируется", func(t *testing.T) {
+		values := []int{1, 2}
+		q := FromSlice(values)
+		values[0] = 100
+		head, _ := q.Peek()
+		assert.Equal(t, 1, head)
+	})
+}
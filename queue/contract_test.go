@@ -0,0 +1,20 @@
+package queue
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ykhdr/persistent-data-structures/pdstest"
+)
+
+func TestFIFO_Contract(t *testing.T) {
+	t.Run("Queue", func(t *testing.T) {
+		pdstest.FIFO(t, NewQueue[int], func(i int) int { return i })
+	})
+	t.Run("NaiveQueue", func(t *testing.T) {
+		pdstest.FIFO(t, NewNaiveQueue[int], func(i int) int { return i })
+	})
+	t.Run("Queue строк", func(t *testing.T) {
+		pdstest.FIFO(t, NewQueue[string], func(i int) string { return fmt.Sprint(i) })
+	})
+}
@@ -0,0 +1,33 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// GobEncode кодирует очередь так же, как MarshalBinary: encoding/gob не видит неэкспортируемых полей,
+// а вложенные структуры кодируются рекурсивно через их GobEncode.
+func (q *Queue[T]) GobEncode() ([]byte, error) {
+	return q.MarshalBinary()
+}
+
+func (q *Queue[T]) GobDecode(data []byte) error {
+	return q.UnmarshalBinary(data)
+}
+
+func (q *NaiveQueue[T]) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(q.data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (q *NaiveQueue[T]) GobDecode(data []byte) error {
+	var values []T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&values); err != nil {
+		return err
+	}
+	q.data = append(make([]T, 0, len(values)), values...)
+	return nil
+}
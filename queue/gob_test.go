@@ -0,0 +1,60 @@
+package queue
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gobRoundTrip прогоняет значение через gob так, как это делает RPC-слой: внутри структуры-сообщения
+func gobRoundTrip[T any](t *testing.T, value T) T {
+	t.Helper()
+	type message struct {
+		Payload T
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(message{Payload: value}))
+	var decoded message
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	return decoded.Payload
+}
+
+func TestQueue_Gob(t *testing.T) {
+	t.Run("большая и пустая очередь", func(t *testing.T) {
+		for _, size := range []int{0, 1, 10000} {
+			q := NewQueue[int]()
+			for i := 0; i < size; i++ {
+				q = q.Enqueue(i)
+			}
+			// часть элементов уже в front-стеке
+			if size > 10 {
+				q, _, _ = q.Dequeue()
+			}
+
+			assert.Equal(t, drain(q), drain(gobRoundTrip(t, q)))
+		}
+	})
+
+	t.Run("вложенные очереди", func(t *testing.T) {
+		outer := NewQueue[*Queue[string]]().
+			Enqueue(NewQueue[string]().Enqueue("a").Enqueue("b")).
+			Enqueue(NewQueue[string]())
+
+		decoded := gobRoundTrip(t, outer)
+		require.Equal(t, 2, decoded.Len())
+		decoded, first, _ := decoded.Dequeue()
+		assert.Equal(t, []string{"a", "b"}, drain(first))
+		_, second, _ := decoded.Dequeue()
+		assert.Equal(t, 0, second.Len())
+	})
+
+	t.Run("NaiveQueue", func(t *testing.T) {
+		q := NewNaiveQueue[int]().Enqueue(1).Enqueue(2)
+		decoded := gobRoundTrip(t, q)
+		assert.Equal(t, 2, decoded.Len())
+	})
+}
@@ -0,0 +1,16 @@
+package queue
+
+import "encoding/json"
+
+func (q *Queue[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(q.ToSlice())
+}
+
+func (q *Queue[T]) UnmarshalJSON(data []byte) error {
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*q = *FromSlice(values)
+	return nil
+}
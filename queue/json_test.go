@@ -0,0 +1,71 @@
+package queue
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ykhdr/persistent-data-structures/array"
+)
+
+func TestQueue_JSON(t *testing.T) {
+	t.Run("кодирование в порядке FIFO", func(t *testing.T) {
+		q := NewQueue[int]().Enqueue(1).Enqueue(2)
+		q, _, _ = q.Dequeue()
+		q = q.Enqueue(3).Enqueue(4)
+
+		data, err := json.Marshal(q)
+
+		require.NoError(t, err)
+		assert.JSONEq(t, `[2, 3, 4]`, string(data))
+		assert.Equal(t, 3, q.Len(), "кодирование не должно изменять очередь")
+	})
+
+	t.Run("round-trip", func(t *testing.T) {
+		q := NewQueue[string]()
+		for _, s := range []string{"a", "b", "c"} {
+			q = q.Enqueue(s)
+		}
+
+		data, err := json.Marshal(q)
+		require.NoError(t, err)
+
+		var decoded Queue[string]
+		require.NoError(t, json.Unmarshal(data, &decoded))
+
+		require.Equal(t, 3, decoded.Len())
+		next := decoded.Enqueue("d")
+		for _, expected := range []string{"a", "b", "c", "d"} {
+			var val string
+			next, val, _ = next.Dequeue()
+			assert.Equal(t, expected, val)
+		}
+	})
+
+	t.Run("пустая очередь", func(t *testing.T) {
+		data, err := json.Marshal(NewQueue[int]())
+		require.NoError(t, err)
+		assert.JSONEq(t, `[]`, string(data))
+
+		var decoded Queue[int]
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.True(t, decoded.IsEmpty())
+		assert.Equal(t, 1, decoded.Enqueue(1).Len())
+	})
+
+	t.Run("очередь векторов", func(t *testing.T) {
+		q := NewQueue[*array.Vector[int]]().Enqueue(array.NewVector[int]().Append(5))
+
+		data, err := json.Marshal(q)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[[5]]`, string(data))
+
+		var decoded Queue[*array.Vector[int]]
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		inner, ok := decoded.Peek()
+		require.True(t, ok)
+		val, _ := inner.Get(0)
+		assert.Equal(t, 5, val)
+	})
+}
@@ -0,0 +1,58 @@
+package queue
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ykhdr/persistent-data-structures/pdstest"
+)
+
+// queueModel сравнивает Queue с NaiveQueue. Извлечения чередуются с добавлениями так,
+// чтобы очередь часто перекладывала элементы, в том числе в старых версиях.
+func queueModel() pdstest.Model[*Queue[int], *NaiveQueue[int]] {
+	return pdstest.Model[*Queue[int], *NaiveQueue[int]]{
+		Ops:   []string{"Enqueue", "Enqueue", "Dequeue", "Peek"},
+		Empty: NewQueue[int](),
+		Ref:   NewNaiveQueue[int](),
+		Apply: func(q *Queue[int], ref *NaiveQueue[int], op int, arg int) (*Queue[int], *NaiveQueue[int], error) {
+			switch op {
+			case 0, 1:
+				return q.Enqueue(arg), ref.Enqueue(arg), nil
+			case 2:
+				nextQ, got, gotOk := q.Dequeue()
+				nextRef, want, wantOk := ref.Dequeue()
+				if got != want || gotOk != wantOk {
+					return nil, nil, fmt.Errorf("Dequeue: %d %v, ожидалось %d %v", got, gotOk, want, wantOk)
+				}
+				return nextQ, nextRef, nil
+			default:
+				got, gotOk := q.Peek()
+				want, wantOk := ref.Peek()
+				if got != want || gotOk != wantOk {
+					return nil, nil, fmt.Errorf("Peek: %d %v, ожидалось %d %v", got, gotOk, want, wantOk)
+				}
+				return q, ref, nil
+			}
+		},
+		Equal: func(q *Queue[int], ref *NaiveQueue[int]) error {
+			if q.Len() != ref.Len() || q.IsEmpty() != ref.IsEmpty() {
+				return fmt.Errorf("Len: %d, ожидалось %d", q.Len(), ref.Len())
+			}
+			return pdstest.EqualSeq(q.Values(), ref.Values())
+		},
+	}
+}
+
+func TestQueue_Model(t *testing.T) {
+	queueModel().Run(t)
+}
+
+func FuzzQueue(f *testing.F) {
+	f.Add([]byte{0, 0, 1, 0, 0, 1, 2, 0, 2, 2, 0, 0, 0, 2, 3, 0, 2, 1, 0, 0})
+	f.Add([]byte{0, 0, 1, 0, 2, 1, 0, 0, 2, 1, 0, 0, 3, 1, 0, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := queueModel().Execute(pdstest.StepsFromBytes(data)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -1,9 +1,17 @@
 package queue
 
+import (
+	"iter"
+
+	pds "github.com/ykhdr/persistent-data-structures"
+)
+
 type NaiveQueue[T any] struct {
 	data []T
 }
 
+var _ pds.FIFO[int, *NaiveQueue[int]] = (*NaiveQueue[int])(nil)
+
 func NewNaiveQueue[T any]() *NaiveQueue[T] {
 	return &NaiveQueue[T]{data: make([]T, 0)}
 }
@@ -39,3 +47,17 @@ func (q *NaiveQueue[T]) Peek() (T, bool) {
 	}
 	return q.data[0], true
 }
+
+func (q *NaiveQueue[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range q.data {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func (q *NaiveQueue[T]) Values() iter.Seq[T] {
+	return q.All()
+}
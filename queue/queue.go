@@ -1,6 +1,10 @@
 package queue
 
-import "iter"
+import (
+	"iter"
+
+	pds "github.com/ykhdr/persistent-data-structures"
+)
 
 type stackNode[T any] struct {
 	value T
@@ -63,6 +67,8 @@ type Queue[T any] struct {
 	len   int
 }
 
+var _ pds.FIFO[int, *Queue[int]] = (*Queue[int])(nil)
+
 func NewQueue[T any]() *Queue[T] {
 	return &Queue[T]{
 		front: newStack[T](),
@@ -136,3 +142,8 @@ func (q *Queue[T]) All() iter.Seq[T] {
 		}
 	}
 }
+
+// Values совпадает с All: элементы обходятся в порядке извлечения
+func (q *Queue[T]) Values() iter.Seq[T] {
+	return q.All()
+}
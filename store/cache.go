@@ -0,0 +1,100 @@
+package store
+
+import (
+	"container/list"
+	"sync"
+)
+
+type cacheItem[V any] struct {
+	key   uint64
+	value V
+}
+
+// Cache - потокобезопасный LRU-кэш ограниченного размера для узлов, прочитанных из PageFile
+type Cache[V any] struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[uint64]*list.Element
+	order    *list.List
+}
+
+func NewCache[V any](capacity int) *Cache[V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Cache[V]{
+		capacity: capacity,
+		items:    make(map[uint64]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (c *Cache[V]) Get(key uint64) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToBack(el)
+	return el.Value.(*cacheItem[V]).value, true
+}
+
+func (c *Cache[V]) Put(key uint64, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*cacheItem[V]).value = value
+		c.order.MoveToBack(el)
+		return
+	}
+
+	c.items[key] = c.order.PushBack(&cacheItem[V]{key: key, value: value})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheItem[V]).key)
+	}
+}
+
+func (c *Cache[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// HashRefs выдаёт объектам хранилища короткие номера. Заглушки узлов, которые ещё не прочитаны
+// из Store, ссылаются на объект номером, как заглушки узлов PageFile - смещением.
+type HashRefs struct {
+	mu     sync.Mutex
+	hashes []Hash
+	refs   map[Hash]uint64
+}
+
+func NewHashRefs() *HashRefs {
+	return &HashRefs{refs: make(map[Hash]uint64)}
+}
+
+// Ref возвращает номер объекта, выдавая новый при первом обращении
+func (r *HashRefs) Ref(h Hash) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ref, ok := r.refs[h]; ok {
+		return ref
+	}
+	ref := uint64(len(r.hashes))
+	r.hashes = append(r.hashes, h)
+	r.refs[h] = ref
+	return ref
+}
+
+// Hash возвращает хэш объекта по номеру, выданному Ref
+func (r *HashRefs) Hash(ref uint64) Hash {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hashes[ref]
+}
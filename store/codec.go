@@ -0,0 +1,17 @@
+package store
+
+import "encoding/json"
+
+// EncodeValues и DecodeValues - детерминированное кодирование значений узлов.
+// Используется JSON, чтобы одинаковые значения давали одинаковые байты и хэши между запусками.
+func EncodeValues[T any](values []T) ([]byte, error) {
+	return json.Marshal(values)
+}
+
+func DecodeValues[T any](data []byte) ([]T, error) {
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	pageMagic      = "PDS1"
+	pageHeaderSize = 16 // magic + смещение checkpoint + crc32
+)
+
+var ErrNoCheckpoint = errors.New("store: page file has no checkpoint")
+
+// PageFile - файл записей только на добавление. Каждая запись адресуется смещением
+// и защищена crc32. Заголовок в начале файла хранит смещение последнего checkpoint.
+// Читать можно из нескольких горутин одновременно.
+type PageFile struct {
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func CreatePageFile(path string) (*PageFile, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &PageFile{file: file, size: pageHeaderSize}
+	if err := p.writeHeader(0); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+func OpenPageFile(path string) (*PageFile, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	p := &PageFile{file: file, size: info.Size()}
+	if _, err := p.Checkpoint(); err != nil && !errors.Is(err, ErrNoCheckpoint) {
+		file.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *PageFile) writeHeader(checkpoint uint64) error {
+	var header [pageHeaderSize]byte
+	copy(header[:4], pageMagic)
+	binary.LittleEndian.PutUint64(header[4:12], checkpoint)
+	binary.LittleEndian.PutUint32(header[12:], crc32.ChecksumIEEE(header[:12]))
+	_, err := p.file.WriteAt(header[:], 0)
+	return err
+}
+
+// Append дописывает запись в конец файла и возвращает её смещение
+func (p *PageFile) Append(data []byte) (uint64, error) {
+	record := make([]byte, 4+len(data)+4)
+	binary.LittleEndian.PutUint32(record, uint32(len(data)))
+	copy(record[4:], data)
+	binary.LittleEndian.PutUint32(record[4+len(data):], crc32.ChecksumIEEE(data))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	offset := p.size
+	if _, err := p.file.WriteAt(record, offset); err != nil {
+		return 0, err
+	}
+	p.size += int64(len(record))
+	return uint64(offset), nil
+}
+
+func (p *PageFile) Read(offset uint64) ([]byte, error) {
+	var lenBuf [4]byte
+	if _, err := p.file.ReadAt(lenBuf[:], int64(offset)); err != nil {
+		return nil, fmt.Errorf("%w: record at %d: %v", ErrCorrupt, offset, err)
+	}
+
+	size := binary.LittleEndian.Uint32(lenBuf[:])
+	if int64(offset)+8+int64(size) > p.fileSize() {
+		return nil, fmt.Errorf("%w: record at %d exceeds file", ErrCorrupt, offset)
+	}
+
+	record := make([]byte, size+4)
+	if _, err := p.file.ReadAt(record, int64(offset)+4); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	data := record[:size]
+	if crc32.ChecksumIEEE(data) != binary.LittleEndian.Uint32(record[size:]) {
+		return nil, fmt.Errorf("%w: record at %d checksum mismatch", ErrCorrupt, offset)
+	}
+	return data, nil
+}
+
+func (p *PageFile) fileSize() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.size
+}
+
+// SetCheckpoint сбрасывает записи на диск и атомарно переключает заголовок на запись offset
+func (p *PageFile) SetCheckpoint(offset uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.file.Sync(); err != nil {
+		return err
+	}
+	if err := p.writeHeader(offset); err != nil {
+		return err
+	}
+	return p.file.Sync()
+}
+
+// Checkpoint возвращает смещение записи, сохранённой последним SetCheckpoint
+func (p *PageFile) Checkpoint() (uint64, error) {
+	var header [pageHeaderSize]byte
+	if _, err := p.file.ReadAt(header[:], 0); err != nil {
+		return 0, fmt.Errorf("%w: header: %v", ErrCorrupt, err)
+	}
+	if string(header[:4]) != pageMagic || crc32.ChecksumIEEE(header[:12]) != binary.LittleEndian.Uint32(header[12:]) {
+		return 0, fmt.Errorf("%w: bad header", ErrCorrupt)
+	}
+
+	offset := binary.LittleEndian.Uint64(header[4:12])
+	if offset == 0 {
+		return 0, ErrNoCheckpoint
+	}
+	return offset, nil
+}
+
+func (p *PageFile) Close() error {
+	return p.file.Close()
+}
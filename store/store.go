@@ -0,0 +1,395 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// staleTempAge - возраст, после которого временный файл считается брошенным упавшей записью
+// и удаляется GC. Более свежие файлы может в этот момент писать другой процесс.
+const staleTempAge = time.Hour
+
+var (
+	ErrNotFound = errors.New("store: object not found")
+	ErrCorrupt  = errors.New("store: corrupt object")
+)
+
+// NodeError - значение паники, которой дисковые структуры (array.VectorFile, hashmap.HashMapFile,
+// VectorStore, HashMapStore) сообщают об ошибке чтения или записи узла из операций без возвращаемой
+// ошибки: Get, Set, Append и других. Err оборачивает ошибку ввода-вывода или ErrCorrupt.
+type NodeError struct {
+	Err error
+}
+
+func (e *NodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// Recover переводит панику NodeError в ошибку и должен вызываться через defer:
+//
+//	func read(v *array.Vector[int]) (sum int, err error) {
+//		defer store.Recover(&err)
+//		...
+//	}
+//
+// Другие паники пробрасываются дальше.
+func Recover(err *error) {
+	if r := recover(); r != nil {
+		if e, ok := r.(*NodeError); ok {
+			*err = e
+			return
+		}
+		panic(r)
+	}
+}
+
+type Hash [sha256.Size]byte
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+func (h Hash) IsZero() bool {
+	return h == Hash{}
+}
+
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("store: invalid hash %q", s)
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// Object - единица хранения: ссылки на другие объекты и произвольные данные.
+// Хэш объекта считается от ссылок и данных, поэтому одинаковое содержимое хранится один раз.
+type Object struct {
+	Links []Hash
+	Data  []byte
+}
+
+func (o Object) encode() []byte {
+	buf := make([]byte, 0, binary.MaxVarintLen64+len(o.Links)*len(Hash{})+len(o.Data))
+	buf = binary.AppendUvarint(buf, uint64(len(o.Links)))
+	for _, link := range o.Links {
+		buf = append(buf, link[:]...)
+	}
+	return append(buf, o.Data...)
+}
+
+func decodeObject(raw []byte) (Object, error) {
+	n, size := binary.Uvarint(raw)
+	if size <= 0 || n > uint64(len(raw)-size)/uint64(len(Hash{})) {
+		return Object{}, ErrCorrupt
+	}
+	raw = raw[size:]
+
+	o := Object{Links: make([]Hash, n)}
+	for i := range o.Links {
+		copy(o.Links[i][:], raw)
+		raw = raw[len(Hash{}):]
+	}
+	o.Data = raw
+	return o, nil
+}
+
+// Store - файловое хранилище объектов с адресацией по содержимому, похожее на objects/ в git:
+// объект с хэшем ab12... лежит в objects/ab/12....
+// Именованные ссылки (refs/) - корни для сборки мусора.
+type Store struct {
+	dir  string
+	sync bool
+
+	// GC ждёт, пока writers не станет нулём, а новые записи ждут конца сборки (collecting)
+	mu         sync.Mutex
+	cond       *sync.Cond
+	writers    int
+	collecting bool
+}
+
+type Option func(*Store)
+
+// WithSync управляет вызовом fsync для каждого записанного объекта (по умолчанию включён).
+// Ссылки синхронизируются всегда.
+func WithSync(sync bool) Option {
+	return func(s *Store) {
+		s.sync = sync
+	}
+}
+
+func Open(dir string, opts ...Option) (*Store, error) {
+	for _, sub := range []string{"objects", "refs"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Store{dir: dir, sync: true}
+	s.cond = sync.NewCond(&s.mu)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Pin откладывает GC до вызова возвращённой функции. Put и SetRef берут его сами, а Save
+// структур держит его на всё время записи версии, чтобы GC не удалил объекты, которые
+// Save уже записал или нашёл в хранилище, но на которые ещё не ссылается ни одна ссылка.
+// Версию, сохранённую без ссылки, GC удалит: держите Pin до SetRef. Pin можно вкладывать.
+func (s *Store) Pin() (unpin func()) {
+	s.mu.Lock()
+	for s.collecting {
+		s.cond.Wait()
+	}
+	s.writers++
+	s.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			s.writers--
+			if s.writers == 0 {
+				s.cond.Broadcast()
+			}
+			s.mu.Unlock()
+		})
+	}
+}
+
+func (s *Store) objectPath(h Hash) string {
+	name := h.String()
+	return filepath.Join(s.dir, "objects", name[:2], name[2:])
+}
+
+func (s *Store) Put(o Object) (Hash, error) {
+	defer s.Pin()()
+
+	raw := o.encode()
+	h := Hash(sha256.Sum256(raw))
+
+	path := s.objectPath(h)
+	if _, err := os.Stat(path); err == nil {
+		return h, nil
+	}
+
+	if err := writeFileAtomic(path, raw, s.sync); err != nil {
+		return Hash{}, err
+	}
+	return h, nil
+}
+
+func (s *Store) Get(h Hash) (Object, error) {
+	raw, err := os.ReadFile(s.objectPath(h))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Object{}, fmt.Errorf("%w: %s", ErrNotFound, h)
+	}
+	if err != nil {
+		return Object{}, err
+	}
+
+	if Hash(sha256.Sum256(raw)) != h {
+		return Object{}, fmt.Errorf("%w: %s checksum mismatch", ErrCorrupt, h)
+	}
+	return decodeObject(raw)
+}
+
+func (s *Store) Has(h Hash) bool {
+	_, err := os.Stat(s.objectPath(h))
+	return err == nil
+}
+
+func (s *Store) refPath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("store: invalid ref name %q", name)
+	}
+	return filepath.Join(s.dir, "refs", name), nil
+}
+
+// SetRef сохраняет именованную ссылку на корень версии. Объекты, достижимые из ссылок, переживают GC.
+func (s *Store) SetRef(name string, h Hash) error {
+	path, err := s.refPath(name)
+	if err != nil {
+		return err
+	}
+	defer s.Pin()()
+	return writeFileAtomic(path, []byte(h.String()+"\n"), true)
+}
+
+func (s *Store) Ref(name string) (Hash, error) {
+	path, err := s.refPath(name)
+	if err != nil {
+		return Hash{}, err
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Hash{}, fmt.Errorf("%w: ref %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return Hash{}, err
+	}
+	return ParseHash(strings.TrimSpace(string(raw)))
+}
+
+func (s *Store) DeleteRef(name string) error {
+	path, err := s.refPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) Refs() (map[string]Hash, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, "refs"))
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string]Hash, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".tmp-") {
+			continue
+		}
+		h, err := s.Ref(e.Name())
+		if err != nil {
+			return nil, err
+		}
+		refs[e.Name()] = h
+	}
+	return refs, nil
+}
+
+// GC удаляет объекты, недостижимые ни из одной ссылки и ни из переданных корней.
+// Возвращает количество удалённых объектов. Сборка ждёт завершения начатых записей (см. Pin),
+// а новые записи ждут конца сборки.
+func (s *Store) GC(roots ...Hash) (int, error) {
+	s.mu.Lock()
+	for s.writers > 0 || s.collecting {
+		s.cond.Wait()
+	}
+	s.collecting = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.collecting = false
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	}()
+
+	refs, err := s.Refs()
+	if err != nil {
+		return 0, err
+	}
+	for _, h := range refs {
+		roots = append(roots, h)
+	}
+
+	live := make(map[Hash]struct{})
+	stack := roots
+	for len(stack) > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := live[h]; ok {
+			continue
+		}
+		o, err := s.Get(h)
+		if err != nil {
+			return 0, err
+		}
+		live[h] = struct{}{}
+		stack = append(stack, o.Links...)
+	}
+
+	removed := 0
+	objects := filepath.Join(s.dir, "objects")
+	err = filepath.WalkDir(objects, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(objects, path)
+		if err != nil {
+			return err
+		}
+		h, err := ParseHash(strings.ReplaceAll(filepath.ToSlash(rel), "/", ""))
+		if err != nil {
+			return removeStaleTemp(path, d)
+		}
+		if _, ok := live[h]; ok {
+			return nil
+		}
+		removed++
+		return os.Remove(path)
+	})
+	return removed, err
+}
+
+// removeStaleTemp удаляет временный файл, брошенный упавшей записью. Свежие временные файлы
+// могут принадлежать записи другого процесса и не трогаются.
+func removeStaleTemp(path string, d fs.DirEntry) error {
+	if !strings.HasPrefix(d.Name(), ".tmp-") {
+		return nil
+	}
+	info, err := d.Info()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if time.Since(info.ModTime()) < staleTempAge {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// writeFileAtomic пишет во временный файл и переименовывает его, чтобы читатель
+// никогда не видел частично записанный объект
+func writeFileAtomic(path string, data []byte, sync bool) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if sync {
+		if err := tmp.Sync(); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countObjects(t *testing.T, dir string) int {
+	t.Helper()
+	count := 0
+	err := filepath.WalkDir(filepath.Join(dir, "objects"), func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			count++
+		}
+		return err
+	})
+	require.NoError(t, err)
+	return count
+}
+
+func TestStore_PutGet(t *testing.T) {
+	t.Run("запись и чтение объекта", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		require.NoError(t, err)
+
+		leaf, err := s.Put(Object{Data: []byte("leaf")})
+		require.NoError(t, err)
+		root, err := s.Put(Object{Links: []Hash{leaf}, Data: []byte("root")})
+		require.NoError(t, err)
+
+		obj, err := s.Get(root)
+		require.NoError(t, err)
+		assert.Equal(t, []Hash{leaf}, obj.Links)
+		assert.Equal(t, []byte("root"), obj.Data)
+		assert.True(t, s.Has(leaf))
+	})
+
+	t.Run("одинаковое содержимое хранится один раз", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := Open(dir)
+		require.NoError(t, err)
+
+		h1, err := s.Put(Object{Data: []byte("same")})
+		require.NoError(t, err)
+		h2, err := s.Put(Object{Data: []byte("same")})
+		require.NoError(t, err)
+
+		assert.Equal(t, h1, h2)
+		assert.Equal(t, 1, countObjects(t, dir))
+	})
+
+	t.Run("отсутствующий объект", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		require.NoError(t, err)
+
+		_, err = s.Get(Hash{1})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("повреждённый объект", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		require.NoError(t, err)
+
+		h, err := s.Put(Object{Data: []byte("data")})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(s.objectPath(h), []byte("garbage"), 0o644))
+
+		_, err = s.Get(h)
+		assert.ErrorIs(t, err, ErrCorrupt)
+	})
+}
+
+func TestStore_Refs(t *testing.T) {
+	s, err := Open(t.TempDir())
+	require.NoError(t, err)
+
+	h, err := s.Put(Object{Data: []byte("v1")})
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetRef("main", h))
+	got, err := s.Ref("main")
+	require.NoError(t, err)
+	assert.Equal(t, h, got)
+
+	refs, err := s.Refs()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Hash{"main": h}, refs)
+
+	require.NoError(t, s.DeleteRef("main"))
+	_, err = s.Ref("main")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Error(t, s.SetRef("../escape", h), "имя ссылки не должно выходить за пределы refs")
+}
+
+func TestStore_GC(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	require.NoError(t, err)
+
+	shared, err := s.Put(Object{Data: []byte("shared")})
+	require.NoError(t, err)
+	onlyOld, err := s.Put(Object{Data: []byte("old")})
+	require.NoError(t, err)
+	oldRoot, err := s.Put(Object{Links: []Hash{shared, onlyOld}, Data: []byte("root")})
+	require.NoError(t, err)
+	newRoot, err := s.Put(Object{Links: []Hash{shared}, Data: []byte("root")})
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetRef("old", oldRoot))
+	require.NoError(t, s.SetRef("new", newRoot))
+
+	removed, err := s.GC()
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed, "все объекты достижимы")
+
+	require.NoError(t, s.DeleteRef("old"))
+	removed, err = s.GC()
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, removed, "должны удалиться старый корень и его уникальный узел")
+	assert.True(t, s.Has(shared))
+	assert.True(t, s.Has(newRoot))
+	assert.False(t, s.Has(onlyOld))
+	assert.False(t, s.Has(oldRoot))
+
+	removed, err = s.GC(newRoot)
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+	assert.Equal(t, 2, countObjects(t, dir))
+}
+
+func TestStore_GCConcurrency(t *testing.T) {
+	t.Run("GC ждёт снятия Pin", func(t *testing.T) {
+		s, err := Open(t.TempDir(), WithSync(false))
+		require.NoError(t, err)
+
+		unpin := s.Pin()
+		leaf, err := s.Put(Object{Data: []byte("leaf")})
+		require.NoError(t, err)
+
+		done := make(chan int)
+		go func() {
+			removed, err := s.GC()
+			assert.NoError(t, err)
+			done <- removed
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("GC не должен выполняться, пока запись не завершена")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		root, err := s.Put(Object{Links: []Hash{leaf}, Data: []byte("root")})
+		require.NoError(t, err)
+		require.NoError(t, s.SetRef("main", root))
+		unpin()
+
+		assert.Equal(t, 0, <-done, "версия, получившая ссылку до снятия Pin, не мусор")
+		assert.True(t, s.Has(leaf))
+	})
+
+	t.Run("удаляются только старые временные файлы", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := Open(dir, WithSync(false))
+		require.NoError(t, err)
+
+		fresh := filepath.Join(dir, "objects", "ab", ".tmp-fresh")
+		stale := filepath.Join(dir, "objects", "ab", ".tmp-stale")
+		require.NoError(t, os.MkdirAll(filepath.Dir(fresh), 0o755))
+		require.NoError(t, os.WriteFile(fresh, []byte("x"), 0o644))
+		require.NoError(t, os.WriteFile(stale, []byte("x"), 0o644))
+		old := time.Now().Add(-2 * staleTempAge)
+		require.NoError(t, os.Chtimes(stale, old, old))
+
+		_, err = s.GC()
+		require.NoError(t, err)
+
+		assert.FileExists(t, fresh, "свежий временный файл может дописывать другой процесс")
+		assert.NoFileExists(t, stale)
+	})
+}